@@ -3,6 +3,7 @@ package infra_sdk
 import (
 	"context"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"golang.org/x/oauth2"
 )
@@ -16,3 +17,8 @@ type GcpAccessor interface {
 	GetTokenSource(ctx context.Context) (oauth2.TokenSource, error)
 	GcpProjectId() string
 }
+
+type AzureAccessor interface {
+	GetCredential(ctx context.Context) (azcore.TokenCredential, error)
+	AzureSubscriptionId() string
+}
@@ -0,0 +1,161 @@
+package aws_account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ce "github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+)
+
+var (
+	_ infra_sdk.CostForecaster = CostForecaster{}
+
+	// forecastMetricMappings maps a universal metric to a Cost Explorer forecast metric
+	// Cost metrics are forecast with GetCostForecast and cetypes.MetricUsageQuantity is forecast with GetUsageForecast
+	forecastMetricMappings = map[string]cetypes.Metric{
+		infra_sdk.UniversalMetricUnblendedCost:    cetypes.MetricUnblendedCost,
+		infra_sdk.UniversalMetricBlendedCost:      cetypes.MetricBlendedCost,
+		infra_sdk.UniversalMetricAmortizedCost:    cetypes.MetricAmortizedCost,
+		infra_sdk.UniversalMetricNetAmortizedCost: cetypes.MetricNetAmortizedCost,
+		infra_sdk.UniversalMetricNetUnblendedCost: cetypes.MetricNetUnblendedCost,
+		infra_sdk.UniversalMetricUsageQuantity:    cetypes.MetricUsageQuantity,
+	}
+)
+
+// CostForecaster forecasts costs with Cost Explorer
+// Cost Explorer forecasts one metric at a time without grouping, so a forecast is requested for each metric
+// See https://docs.aws.amazon.com/aws-cost-management/latest/APIReference/API_GetCostForecast.html
+// and https://docs.aws.amazon.com/aws-cost-management/latest/APIReference/API_GetUsageForecast.html
+type CostForecaster struct {
+	Accessor infra_sdk.AwsAccessor
+}
+
+func (f CostForecaster) GetForecast(ctx context.Context, query infra_sdk.CostForecastQuery) (*infra_sdk.CostForecast, error) {
+	granularity := granularityMappings[query.Granularity]
+	if granularity == "" {
+		granularity = cetypes.GranularityDaily
+	}
+	if granularity == cetypes.GranularityHourly {
+		return nil, fmt.Errorf("aws cost explorer does not support hourly forecasts")
+	}
+	if len(query.GroupBy) > 0 {
+		return nil, fmt.Errorf("aws cost explorer does not support grouping forecasts")
+	}
+	period, err := costQueryToTimePeriod(query.CostQuery, granularity, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	filter, err := costQueryToFilter(query.CostQuery)
+	if err != nil {
+		return nil, err
+	}
+	metrics := query.UniqueMetrics()
+	for _, metric := range metrics {
+		ceMetric, ok := forecastMetricMappings[metric]
+		if !ok {
+			return nil, fmt.Errorf("unsupported cost metric %q", metric)
+		}
+		if ceMetric == cetypes.MetricUsageQuantity && !hasUsageTypeFilter(filter) {
+			return nil, fmt.Errorf("aws cost explorer requires a filter on the %s or USAGE_TYPE_GROUP dimension to forecast %s", infra_sdk.UniversalDimensionUsageType, metric)
+		}
+	}
+
+	// Cost Explorer is global, use us-east-1 as the region to satisfy the aws sdk
+	awsConfig, err := f.Accessor.NewConfig("us-east-1")
+	if err != nil {
+		return nil, fmt.Errorf("error resolving aws config: %w", err)
+	}
+	if awsConfig == nil {
+		return nil, nil
+	}
+	client := ce.NewFromConfig(*awsConfig)
+
+	result := infra_sdk.NewCostForecast()
+	intervalLevel := ptr(int32(query.IntervalLevel()))
+	for _, metric := range metrics {
+		var total *cetypes.MetricValue
+		var forecasts []cetypes.ForecastResult
+		if ceMetric := forecastMetricMappings[metric]; ceMetric == cetypes.MetricUsageQuantity {
+			out, err := client.GetUsageForecast(ctx, &ce.GetUsageForecastInput{
+				TimePeriod:              period,
+				Granularity:             granularity,
+				Metric:                  ceMetric,
+				Filter:                  filter,
+				PredictionIntervalLevel: intervalLevel,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error querying aws cost explorer usage forecast: %w", err)
+			}
+			total, forecasts = out.Total, out.ForecastResultsByTime
+		} else {
+			out, err := client.GetCostForecast(ctx, &ce.GetCostForecastInput{
+				TimePeriod:              period,
+				Granularity:             granularity,
+				Metric:                  ceMetric,
+				Filter:                  filter,
+				PredictionIntervalLevel: intervalLevel,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error querying aws cost explorer forecast: %w", err)
+			}
+			total, forecasts = out.Total, out.ForecastResultsByTime
+		}
+		if err := addForecastResults(result, metric, total, forecasts); err != nil {
+			return nil, fmt.Errorf("error parsing forecast: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// hasUsageTypeFilter returns true if every cost matched by expr is limited to a usage type or usage type group
+// GetUsageForecast rejects filters that do not limit the usage type, since usage quantities in different units cannot be summed
+func hasUsageTypeFilter(expr *cetypes.Expression) bool {
+	switch {
+	case expr == nil:
+		return false
+	case expr.Dimensions != nil:
+		return expr.Dimensions.Key == cetypes.DimensionUsageType || expr.Dimensions.Key == cetypes.DimensionUsageTypeGroup
+	case len(expr.And) > 0:
+		for i := range expr.And {
+			if hasUsageTypeFilter(&expr.And[i]) {
+				return true
+			}
+		}
+		return false
+	case len(expr.Or) > 0:
+		for i := range expr.Or {
+			if !hasUsageTypeFilter(&expr.Or[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// addForecastResults adds each forecasted period to the series for metricName
+// The unit is only reported on the total, so it is applied to every datapoint
+func addForecastResults(result *infra_sdk.CostForecast, metricName string, total *cetypes.MetricValue, forecasts []cetypes.ForecastResult) error {
+	unit := ""
+	if total != nil {
+		unit = unptr(total.Unit)
+	}
+	for _, forecast := range forecasts {
+		start, end, err := parseTimePeriod(forecast.TimePeriod)
+		if err != nil {
+			return err
+		}
+		result.AddDatapoint(metricName, infra_sdk.CostSeriesGroupKeys{}, infra_sdk.CostForecastDatapoint{
+			Start:      start,
+			End:        end,
+			Unit:       unit,
+			Value:      unptr(forecast.MeanValue),
+			LowerBound: unptr(forecast.PredictionIntervalLowerBound),
+			UpperBound: unptr(forecast.PredictionIntervalUpperBound),
+		})
+	}
+	return nil
+}
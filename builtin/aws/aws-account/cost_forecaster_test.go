@@ -0,0 +1,179 @@
+package aws_account
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddForecastResults(t *testing.T) {
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	result := infra_sdk.NewCostForecast()
+	err := addForecastResults(result, infra_sdk.UniversalMetricAmortizedCost,
+		&cetypes.MetricValue{Amount: ptr("25.5"), Unit: ptr("USD")},
+		[]cetypes.ForecastResult{
+			{
+				TimePeriod:                   &cetypes.DateInterval{Start: ptr("2025-03-01"), End: ptr("2025-03-02")},
+				MeanValue:                    ptr("12.5"),
+				PredictionIntervalLowerBound: ptr("10.1"),
+				PredictionIntervalUpperBound: ptr("14.9"),
+			},
+			{
+				TimePeriod:                   &cetypes.DateInterval{Start: ptr("2025-03-02"), End: ptr("2025-03-03")},
+				MeanValue:                    ptr("13"),
+				PredictionIntervalLowerBound: ptr("10.4"),
+				PredictionIntervalUpperBound: ptr("15.6"),
+			},
+		})
+	require.NoError(t, err)
+
+	series, ok := result.Series[":AmortizedCost"]
+	require.True(t, ok)
+	assert.Equal(t, infra_sdk.UniversalMetricAmortizedCost, series.MetricName)
+	assert.Equal(t, []infra_sdk.CostForecastDatapoint{
+		{Start: day1, End: day1.AddDate(0, 0, 1), Unit: "USD", Value: "12.5", LowerBound: "10.1", UpperBound: "14.9"},
+		{Start: day1.AddDate(0, 0, 1), End: day1.AddDate(0, 0, 2), Unit: "USD", Value: "13", LowerBound: "10.4", UpperBound: "15.6"},
+	}, series.Points)
+}
+
+func TestCostForecaster_GetForecast_InvalidInput(t *testing.T) {
+	start := time.Now().UTC().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+	tests := []struct {
+		name   string
+		query  infra_sdk.CostQuery
+		errMsg string
+	}{
+		{
+			name:   "hourly",
+			query:  infra_sdk.CostQuery{Start: start, End: start.Add(time.Hour), Granularity: infra_sdk.CostGranularityHourly},
+			errMsg: "does not support hourly forecasts",
+		},
+		{
+			name:   "group by",
+			query:  infra_sdk.CostQuery{Start: start, End: start.AddDate(0, 0, 1), GroupBy: infra_sdk.CostGroupIdentifiers{{TagKey: infra_sdk.UniversalTagEnv}}},
+			errMsg: "does not support grouping forecasts",
+		},
+		{
+			name:   "unsupported metric",
+			query:  infra_sdk.CostQuery{Start: start, End: start.AddDate(0, 0, 1), Metrics: []string{"ListCost"}},
+			errMsg: `unsupported cost metric "ListCost"`,
+		},
+		{
+			name:   "invalid filter",
+			query:  infra_sdk.CostQuery{Start: start, End: start.AddDate(0, 0, 1), Filter: &infra_sdk.CostFilter{}},
+			errMsg: "invalid cost filter",
+		},
+		{
+			name:   "usage without filter",
+			query:  infra_sdk.CostQuery{Start: start, End: start.AddDate(0, 0, 1), Metrics: []string{infra_sdk.UniversalMetricUsageQuantity}},
+			errMsg: "requires a filter on the nullstone.io/usage-type or USAGE_TYPE_GROUP dimension",
+		},
+		{
+			name: "usage without usage type filter",
+			query: infra_sdk.CostQuery{
+				Start: start, End: start.AddDate(0, 0, 1), Metrics: []string{infra_sdk.UniversalMetricUsageQuantity},
+				FilterTags: []infra_sdk.CostFilterTag{{Key: infra_sdk.UniversalTagEnv, Values: []string{"prod"}}},
+			},
+			errMsg: "requires a filter on the nullstone.io/usage-type or USAGE_TYPE_GROUP dimension",
+		},
+		{
+			name: "usage with partial usage type filter",
+			query: infra_sdk.CostQuery{
+				Start: start, End: start.AddDate(0, 0, 1), Metrics: []string{infra_sdk.UniversalMetricUsageQuantity},
+				Filter: &infra_sdk.CostFilter{Or: []infra_sdk.CostFilter{
+					{Dimension: &infra_sdk.CostFilterCondition{Key: infra_sdk.UniversalDimensionUsageType, Values: []string{"BoxUsage:t3.micro"}}},
+					{Tag: &infra_sdk.CostFilterCondition{Key: infra_sdk.UniversalTagEnv, Values: []string{"prod"}}},
+				}},
+			},
+			errMsg: "requires a filter on the nullstone.io/usage-type or USAGE_TYPE_GROUP dimension",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CostForecaster{}.GetForecast(context.Background(), infra_sdk.CostForecastQuery{CostQuery: tt.query})
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+type testAccessor struct {
+	endpoint string
+}
+
+func (a testAccessor) NewConfig(region string) (*aws.Config, error) {
+	return &aws.Config{
+		Region:       region,
+		BaseEndpoint: aws.String(a.endpoint),
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+	}, nil
+}
+
+func (a testAccessor) AwsAccountId() string { return "123456789012" }
+
+func TestCostForecaster_GetForecast(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operation := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "AWSInsightsIndexService.")
+		var input struct{ Metric string }
+		json.NewDecoder(r.Body).Decode(&input)
+		mu.Lock()
+		requests[input.Metric] = operation
+		mu.Unlock()
+
+		unit := "USD"
+		if operation == "GetUsageForecast" {
+			unit = "Hrs"
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		json.NewEncoder(w).Encode(map[string]any{
+			"Total": map[string]any{"Amount": "5", "Unit": unit},
+			"ForecastResultsByTime": []any{
+				map[string]any{
+					"TimePeriod":                   map[string]any{"Start": "2025-03-01", "End": "2025-03-02"},
+					"MeanValue":                    "5",
+					"PredictionIntervalLowerBound": "4",
+					"PredictionIntervalUpperBound": "6",
+				},
+			},
+		})
+	}))
+	defer ts.Close()
+
+	forecaster := CostForecaster{Accessor: testAccessor{endpoint: ts.URL}}
+	result, err := forecaster.GetForecast(context.Background(), infra_sdk.CostForecastQuery{
+		CostQuery: infra_sdk.CostQuery{
+			Start:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC),
+			Metrics: []string{infra_sdk.UniversalMetricUnblendedCost, infra_sdk.UniversalMetricUsageQuantity},
+			// Usage forecasts require a usage type filter
+			Filter: &infra_sdk.CostFilter{And: []infra_sdk.CostFilter{
+				{Dimension: &infra_sdk.CostFilterCondition{Key: "USAGE_TYPE_GROUP", Values: []string{"EC2: Running Hours"}}},
+				{Tag: &infra_sdk.CostFilterCondition{Key: infra_sdk.UniversalTagEnv, Values: []string{"prod"}}},
+			}},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"UNBLENDED_COST": "GetCostForecast",
+		"USAGE_QUANTITY": "GetUsageForecast",
+	}, requests)
+	require.Len(t, result.Series, 2)
+	assert.Equal(t, "USD", result.Series[":UnblendedCost"].Points[0].Unit)
+	assert.Equal(t, "Hrs", result.Series[":UsageQuantity"].Points[0].Unit)
+}
@@ -2,7 +2,6 @@ package aws_account
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 	"time"
 
@@ -43,24 +42,37 @@ func (a *CostResultAggregator) AddResults(resultsByTime []cetypes.ResultByTime,
 }
 
 func (a *CostResultAggregator) parseWindow(resultByTime cetypes.ResultByTime) (time.Time, time.Time, error) {
-	if resultByTime.TimePeriod == nil {
+	return parseTimePeriod(resultByTime.TimePeriod)
+}
+
+func parseTimePeriod(period *cetypes.DateInterval) (time.Time, time.Time, error) {
+	if period == nil {
 		return time.Time{}, time.Time{}, fmt.Errorf("missing time period in results")
 	}
-	rawStart, rawEnd := unptr(resultByTime.TimePeriod.Start), unptr(resultByTime.TimePeriod.End)
-	start, err := time.Parse("2006-01-02", rawStart)
+	rawStart, rawEnd := unptr(period.Start), unptr(period.End)
+	start, err := parseResultTime(rawStart)
 	if err != nil {
 		return time.Time{}, time.Time{}, fmt.Errorf("invalid start time in results %q: %w", rawStart, err)
 	}
-	end, err := time.Parse("2006-01-02", rawEnd)
+	end, err := parseResultTime(rawEnd)
 	if err != nil {
 		return time.Time{}, time.Time{}, fmt.Errorf("invalid end time in results %q: %w", rawEnd, err)
 	}
 	return start, end, nil
 }
 
-func (a *CostResultAggregator) parseResultGroupKeys(inputGroups infra_sdk.CostGroupIdentifiers, keys []string) infra_sdk.CostSeriesGroupKeys {
-	sort.Strings(keys)
+// parseResultTime parses a time from a result window
+// Hourly results contain RFC3339 timestamps while daily and monthly results contain dates
+func parseResultTime(raw string) (time.Time, error) {
+	if strings.Contains(raw, "T") {
+		return time.Parse(time.RFC3339, raw)
+	}
+	return time.Parse(costDateFormat, raw)
+}
 
+// parseResultGroupKeys labels the keys of a result group
+// Cost Explorer returns group keys in the same order as the GroupBy definitions, so dimensions are labelled by position
+func (a *CostResultAggregator) parseResultGroupKeys(inputGroups infra_sdk.CostGroupIdentifiers, keys []string) infra_sdk.CostSeriesGroupKeys {
 	result := make(infra_sdk.CostSeriesGroupKeys, 0)
 	for i, key := range keys {
 		tokens := strings.SplitN(key, "$", 2)
@@ -2,19 +2,50 @@ package aws_account
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	ce "github.com/aws/aws-sdk-go-v2/service/costexplorer"
 	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
 	infra_sdk "github.com/nullstone-io/infra-sdk"
 )
 
+const (
+	costDateFormat = "2006-01-02"
+
+	// hourlyCostLookback is how far back Cost Explorer retains hourly cost data
+	hourlyCostLookback = 14 * 24 * time.Hour
+)
+
 var (
+	// ErrHourlyCostLookback is returned when an hourly query starts earlier than Cost Explorer retains hourly data
+	ErrHourlyCostLookback = errors.New("aws cost explorer only provides hourly costs for the last 14 days")
+
 	granularityMappings = map[infra_sdk.CostGranularity]cetypes.Granularity{
 		infra_sdk.CostGranularityHourly:  cetypes.GranularityHourly,
 		infra_sdk.CostGranularityDaily:   cetypes.GranularityDaily,
 		infra_sdk.CostGranularityMonthly: cetypes.GranularityMonthly,
 	}
+
+	matchOptionMappings = map[infra_sdk.CostFilterMatchOption]cetypes.MatchOption{
+		infra_sdk.CostFilterMatchEquals:          cetypes.MatchOptionEquals,
+		infra_sdk.CostFilterMatchAbsent:          cetypes.MatchOptionAbsent,
+		infra_sdk.CostFilterMatchStartsWith:      cetypes.MatchOptionStartsWith,
+		infra_sdk.CostFilterMatchCaseInsensitive: cetypes.MatchOptionCaseInsensitive,
+	}
+
+	// metricMappings maps a universal metric to a Cost Explorer metric
+	// Cost Explorer reports results using the same metric names, so no reverse mapping is needed
+	metricMappings = map[string]string{
+		infra_sdk.UniversalMetricUnblendedCost:    "UnblendedCost",
+		infra_sdk.UniversalMetricBlendedCost:      "BlendedCost",
+		infra_sdk.UniversalMetricAmortizedCost:    "AmortizedCost",
+		infra_sdk.UniversalMetricNetAmortizedCost: "NetAmortizedCost",
+		infra_sdk.UniversalMetricNetUnblendedCost: "NetUnblendedCost",
+		infra_sdk.UniversalMetricUsageQuantity:    "UsageQuantity",
+	}
 )
 
 type Coster struct {
@@ -22,6 +53,23 @@ type Coster struct {
 }
 
 func (c Coster) GetCosts(ctx context.Context, query infra_sdk.CostQuery) (*infra_sdk.CostResult, error) {
+	granularity := granularityMappings[query.Granularity]
+	if granularity == "" {
+		granularity = cetypes.GranularityDaily
+	}
+	period, err := costQueryToTimePeriod(query, granularity, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := costQueryToMetrics(query)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := costQueryToFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
 	// Cost Explorer is global, use us-east-1 as the region to satisfy the aws sdk
 	awsConfig, err := c.Accessor.NewConfig("us-east-1")
 	if err != nil {
@@ -32,22 +80,16 @@ func (c Coster) GetCosts(ctx context.Context, query infra_sdk.CostQuery) (*infra
 	}
 	client := ce.NewFromConfig(*awsConfig)
 
-	period := &cetypes.DateInterval{
-		Start: ptr(query.Start.Format("2006-01-02")),
-		End:   ptr(query.End.Format("2006-01-02")), // end is EXCLUSIVE
-	}
-
-	granularity := granularityMappings[query.Granularity]
-	if granularity == "" {
-		granularity = cetypes.GranularityDaily
+	groupBy := query.GroupBy.Unique()
+	if slices.Contains(groupBy, infra_sdk.CostGroupIdentifier{Dimension: infra_sdk.UniversalDimensionResourceId}) {
+		return c.getCostsWithResources(ctx, client, period, granularity, metrics, filter, groupBy)
 	}
 
-	groupBy := query.GroupBy.Unique()
 	input := &ce.GetCostAndUsageInput{
 		TimePeriod:  period,
 		Granularity: granularity,
-		Metrics:     []string{"UnblendedCost"},
-		Filter:      costQueryToFilter(query),
+		Metrics:     metrics,
+		Filter:      filter,
 		GroupBy:     costQueryToGroupBy(groupBy),
 	}
 
@@ -71,31 +113,154 @@ func (c Coster) GetCosts(ctx context.Context, query infra_sdk.CostQuery) (*infra
 	return aggregator.CostResult, nil
 }
 
-func costQueryToFilter(query infra_sdk.CostQuery) *cetypes.Expression {
-	if len(query.FilterTags) < 1 {
-		return nil
-	}
-	if len(query.FilterTags) == 1 {
-		return &cetypes.Expression{
-			Tags: &cetypes.TagValues{
-				Key:          ptr(UniversalTag(query.FilterTags[0].Key).ToAws()),
-				MatchOptions: []cetypes.MatchOption{cetypes.MatchOptionEquals},
-				Values:       query.FilterTags[0].Values,
+// getCostsWithResources queries resource-level costs, which is required to group by resource id
+// Resource-level data must be enabled in Cost Explorer and is only available for the last 14 days
+func (c Coster) getCostsWithResources(ctx context.Context, client *ce.Client, period *cetypes.DateInterval,
+	granularity cetypes.Granularity, metrics []string, filter *cetypes.Expression, groupBy infra_sdk.CostGroupIdentifiers) (*infra_sdk.CostResult, error) {
+	// A filter is required for resource-level queries
+	// Credits and refunds are not attributed to a resource, so they are excluded by default
+	if filter == nil {
+		filter = &cetypes.Expression{
+			Not: &cetypes.Expression{
+				Dimensions: &cetypes.DimensionValues{
+					Key:    cetypes.DimensionRecordType,
+					Values: []string{"Credit", "Refund"},
+				},
 			},
 		}
 	}
+	input := &ce.GetCostAndUsageWithResourcesInput{
+		TimePeriod:  period,
+		Granularity: granularity,
+		Metrics:     metrics,
+		Filter:      filter,
+		GroupBy:     costQueryToGroupBy(groupBy),
+	}
 
-	root := &cetypes.Expression{}
-	for _, filterTag := range query.FilterTags {
-		root.And = append(root.And, cetypes.Expression{
+	aggregator := NewCostResultAggregator()
+	var nextToken *string
+	for {
+		input.NextPageToken = nextToken
+		out, err := client.GetCostAndUsageWithResources(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error querying aws cost explorer with resources: %w", err)
+		}
+		if err := aggregator.AddResults(out.ResultsByTime, groupBy); err != nil {
+			return nil, fmt.Errorf("error aggregating results: %w", err)
+		}
+		if out.NextPageToken == nil || *out.NextPageToken == "" {
+			break
+		}
+		nextToken = out.NextPageToken
+	}
+
+	return aggregator.CostResult, nil
+}
+
+// costQueryToTimePeriod converts the query window into a Cost Explorer time period (end is EXCLUSIVE)
+// Hourly queries use RFC3339 timestamps truncated to the hour and must start within the hourly lookback
+// Daily and monthly queries use dates
+func costQueryToTimePeriod(query infra_sdk.CostQuery, granularity cetypes.Granularity, now time.Time) (*cetypes.DateInterval, error) {
+	if granularity != cetypes.GranularityHourly {
+		return &cetypes.DateInterval{
+			Start: ptr(query.Start.Format(costDateFormat)),
+			End:   ptr(query.End.Format(costDateFormat)),
+		}, nil
+	}
+
+	start, end := query.Start.UTC().Truncate(time.Hour), query.End.UTC().Truncate(time.Hour)
+	if earliest := now.UTC().Add(-hourlyCostLookback).Truncate(time.Hour); start.Before(earliest) {
+		return nil, fmt.Errorf("%w: start %s is before %s", ErrHourlyCostLookback, start.Format(time.RFC3339), earliest.Format(time.RFC3339))
+	}
+	return &cetypes.DateInterval{
+		Start: ptr(start.Format(time.RFC3339)),
+		End:   ptr(end.Format(time.RFC3339)),
+	}, nil
+}
+
+// costQueryToMetrics maps the universal metrics in the query to Cost Explorer metrics
+func costQueryToMetrics(query infra_sdk.CostQuery) ([]string, error) {
+	metrics := query.UniqueMetrics()
+	result := make([]string, 0, len(metrics))
+	for _, metric := range metrics {
+		awsMetric, ok := metricMappings[metric]
+		if !ok {
+			return nil, fmt.Errorf("unsupported cost metric %q", metric)
+		}
+		result = append(result, awsMetric)
+	}
+	return result, nil
+}
+
+// costQueryToFilter translates the filters in the query into a Cost Explorer expression
+// This returns nil if the query has no filters
+func costQueryToFilter(query infra_sdk.CostQuery) (*cetypes.Expression, error) {
+	filter := query.FilterExpression()
+	if filter == nil {
+		return nil, nil
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cost filter: %w", err)
+	}
+	expr := costFilterToExpression(*filter)
+	return &expr, nil
+}
+
+// costFilterToExpression translates a validated CostFilter into a Cost Explorer expression
+// Cost Explorer requires at least 2 expressions in And/Or, so a single expression is used as-is
+// Cost Explorer rejects match options that are not supported for a given API or key type (e.g. ABSENT on a dimension)
+func costFilterToExpression(filter infra_sdk.CostFilter) cetypes.Expression {
+	switch {
+	case len(filter.And) > 0:
+		if len(filter.And) == 1 {
+			return costFilterToExpression(filter.And[0])
+		}
+		expr := cetypes.Expression{}
+		for _, cur := range filter.And {
+			expr.And = append(expr.And, costFilterToExpression(cur))
+		}
+		return expr
+	case len(filter.Or) > 0:
+		if len(filter.Or) == 1 {
+			return costFilterToExpression(filter.Or[0])
+		}
+		expr := cetypes.Expression{}
+		for _, cur := range filter.Or {
+			expr.Or = append(expr.Or, costFilterToExpression(cur))
+		}
+		return expr
+	case filter.Not != nil:
+		not := costFilterToExpression(*filter.Not)
+		return cetypes.Expression{Not: &not}
+	case filter.Tag != nil:
+		return cetypes.Expression{
 			Tags: &cetypes.TagValues{
-				Key:          ptr(UniversalTag(filterTag.Key).ToAws()),
-				MatchOptions: []cetypes.MatchOption{cetypes.MatchOptionEquals},
-				Values:       filterTag.Values,
+				Key:          ptr(UniversalTag(filter.Tag.Key).ToAws()),
+				MatchOptions: costFilterToMatchOptions(filter.Tag.MatchOptions),
+				Values:       filter.Tag.Values,
 			},
-		})
+		}
+	case filter.Dimension != nil:
+		return cetypes.Expression{
+			Dimensions: &cetypes.DimensionValues{
+				Key:          cetypes.Dimension(UniversalDimension(filter.Dimension.Key).ToAws()),
+				MatchOptions: costFilterToMatchOptions(filter.Dimension.MatchOptions),
+				Values:       filter.Dimension.Values,
+			},
+		}
+	}
+	return cetypes.Expression{}
+}
+
+func costFilterToMatchOptions(options []infra_sdk.CostFilterMatchOption) []cetypes.MatchOption {
+	if len(options) == 0 {
+		return []cetypes.MatchOption{cetypes.MatchOptionEquals}
+	}
+	result := make([]cetypes.MatchOption, 0, len(options))
+	for _, option := range options {
+		result = append(result, matchOptionMappings[option])
 	}
-	return root
+	return result
 }
 
 func costQueryToGroupBy(groupBy infra_sdk.CostGroupIdentifiers) []cetypes.GroupDefinition {
@@ -0,0 +1,254 @@
+package aws_account
+
+import (
+	"testing"
+	"time"
+
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostQueryToTimePeriod(t *testing.T) {
+	now := time.Date(2025, 3, 20, 15, 42, 0, 0, time.UTC)
+
+	t.Run("daily uses dates", func(t *testing.T) {
+		period, err := costQueryToTimePeriod(infra_sdk.CostQuery{
+			Start: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC),
+		}, cetypes.GranularityDaily, now)
+		require.NoError(t, err)
+		assert.Equal(t, "2025-03-01", unptr(period.Start))
+		assert.Equal(t, "2025-03-08", unptr(period.End))
+	})
+
+	t.Run("monthly ignores hourly lookback", func(t *testing.T) {
+		period, err := costQueryToTimePeriod(infra_sdk.CostQuery{
+			Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		}, cetypes.GranularityMonthly, now)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-01-01", unptr(period.Start))
+		assert.Equal(t, "2025-01-01", unptr(period.End))
+	})
+
+	t.Run("hourly uses utc timestamps truncated to the hour", func(t *testing.T) {
+		est := time.FixedZone("EST", -5*60*60)
+		period, err := costQueryToTimePeriod(infra_sdk.CostQuery{
+			Start: time.Date(2025, 3, 19, 8, 30, 0, 0, est),
+			End:   time.Date(2025, 3, 20, 8, 15, 0, 0, est),
+		}, cetypes.GranularityHourly, now)
+		require.NoError(t, err)
+		assert.Equal(t, "2025-03-19T13:00:00Z", unptr(period.Start))
+		assert.Equal(t, "2025-03-20T13:00:00Z", unptr(period.End))
+	})
+
+	t.Run("hourly beyond lookback", func(t *testing.T) {
+		_, err := costQueryToTimePeriod(infra_sdk.CostQuery{
+			Start: now.AddDate(0, 0, -15),
+			End:   now,
+		}, cetypes.GranularityHourly, now)
+		assert.ErrorIs(t, err, ErrHourlyCostLookback)
+	})
+}
+
+func TestCostResultAggregator_AddResults(t *testing.T) {
+	inputGroups := infra_sdk.CostGroupIdentifiers{{Dimension: infra_sdk.UniversalDimensionAccount}}
+	seriesKey := "nullstone.io/cloud-account$123456789012:UnblendedCost"
+
+	tests := []struct {
+		name      string
+		periods   [][2]string
+		wantStart []time.Time
+		wantEnd   []time.Time
+	}{
+		{
+			name:      "hourly",
+			periods:   [][2]string{{"2025-03-19T13:00:00Z", "2025-03-19T14:00:00Z"}, {"2025-03-19T14:00:00Z", "2025-03-19T15:00:00Z"}},
+			wantStart: []time.Time{time.Date(2025, 3, 19, 13, 0, 0, 0, time.UTC), time.Date(2025, 3, 19, 14, 0, 0, 0, time.UTC)},
+			wantEnd:   []time.Time{time.Date(2025, 3, 19, 14, 0, 0, 0, time.UTC), time.Date(2025, 3, 19, 15, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:      "daily",
+			periods:   [][2]string{{"2025-03-01", "2025-03-02"}, {"2025-03-02", "2025-03-03"}},
+			wantStart: []time.Time{time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)},
+			wantEnd:   []time.Time{time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:      "monthly",
+			periods:   [][2]string{{"2025-01-01", "2025-02-01"}, {"2025-02-01", "2025-03-01"}},
+			wantStart: []time.Time{time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+			wantEnd:   []time.Time{time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results := make([]cetypes.ResultByTime, 0, len(test.periods))
+			for _, period := range test.periods {
+				results = append(results, cetypes.ResultByTime{
+					TimePeriod: &cetypes.DateInterval{Start: ptr(period[0]), End: ptr(period[1])},
+					Groups: []cetypes.Group{
+						{
+							Keys: []string{"123456789012"},
+							Metrics: map[string]cetypes.MetricValue{
+								"UnblendedCost": {Amount: ptr("1.25"), Unit: ptr("USD")},
+							},
+						},
+					},
+				})
+			}
+
+			aggregator := NewCostResultAggregator()
+			require.NoError(t, aggregator.AddResults(results, inputGroups))
+
+			series, ok := aggregator.CostResult.Series[seriesKey]
+			require.True(t, ok, "missing series %q", seriesKey)
+			require.Len(t, series.Points, len(test.periods))
+			for i, point := range series.Points {
+				assert.True(t, test.wantStart[i].Equal(point.Start), "point %d start: %s", i, point.Start)
+				assert.True(t, test.wantEnd[i].Equal(point.End), "point %d end: %s", i, point.End)
+				assert.Equal(t, "1.25", point.Value)
+				assert.Equal(t, "USD", point.Unit)
+			}
+		})
+	}
+
+	t.Run("invalid window", func(t *testing.T) {
+		aggregator := NewCostResultAggregator()
+		err := aggregator.AddResults([]cetypes.ResultByTime{
+			{TimePeriod: &cetypes.DateInterval{Start: ptr("2025-03-19 13:00"), End: ptr("2025-03-19T14:00:00Z")}},
+		}, inputGroups)
+		assert.Error(t, err)
+	})
+}
+
+func TestCostQueryToMetrics(t *testing.T) {
+	metrics, err := costQueryToMetrics(infra_sdk.CostQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"UnblendedCost"}, metrics)
+
+	metrics, err = costQueryToMetrics(infra_sdk.CostQuery{
+		Metrics: []string{infra_sdk.UniversalMetricUnblendedCost, infra_sdk.UniversalMetricAmortizedCost, infra_sdk.UniversalMetricNetAmortizedCost},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"UnblendedCost", "AmortizedCost", "NetAmortizedCost"}, metrics)
+
+	_, err = costQueryToMetrics(infra_sdk.CostQuery{Metrics: []string{"ListCost"}})
+	assert.ErrorContains(t, err, `unsupported cost metric "ListCost"`)
+}
+
+func TestCostResultAggregator_AddResults_MultipleMetrics(t *testing.T) {
+	aggregator := NewCostResultAggregator()
+	err := aggregator.AddResults([]cetypes.ResultByTime{
+		{
+			TimePeriod: &cetypes.DateInterval{Start: ptr("2025-03-01"), End: ptr("2025-03-02")},
+			Groups: []cetypes.Group{
+				{
+					Keys: []string{"123456789012"},
+					Metrics: map[string]cetypes.MetricValue{
+						"UnblendedCost": {Amount: ptr("10"), Unit: ptr("USD")},
+						"AmortizedCost": {Amount: ptr("7.5"), Unit: ptr("USD")},
+					},
+				},
+			},
+		},
+	}, infra_sdk.CostGroupIdentifiers{{Dimension: infra_sdk.UniversalDimensionAccount}})
+	require.NoError(t, err)
+
+	require.Len(t, aggregator.CostResult.Series, 2)
+	unblended := aggregator.CostResult.Series["nullstone.io/cloud-account$123456789012:UnblendedCost"]
+	assert.Equal(t, infra_sdk.UniversalMetricUnblendedCost, unblended.MetricName)
+	require.Len(t, unblended.Points, 1)
+	assert.Equal(t, "10", unblended.Points[0].Value)
+	amortized := aggregator.CostResult.Series["nullstone.io/cloud-account$123456789012:AmortizedCost"]
+	assert.Equal(t, infra_sdk.UniversalMetricAmortizedCost, amortized.MetricName)
+	require.Len(t, amortized.Points, 1)
+	assert.Equal(t, "7.5", amortized.Points[0].Value)
+}
+
+func TestCostResultAggregator_AddResults_MultipleDimensions(t *testing.T) {
+	aggregator := NewCostResultAggregator()
+	err := aggregator.AddResults([]cetypes.ResultByTime{
+		{
+			TimePeriod: &cetypes.DateInterval{Start: ptr("2025-03-01"), End: ptr("2025-03-02")},
+			Groups: []cetypes.Group{
+				{
+					// The region sorts after the service, the keys must still be labelled in GroupBy order
+					Keys: []string{"us-east-1", "Amazon Elastic Compute Cloud - Compute"},
+					Metrics: map[string]cetypes.MetricValue{
+						"UnblendedCost": {Amount: ptr("3.5"), Unit: ptr("USD")},
+					},
+				},
+			},
+		},
+	}, infra_sdk.CostGroupIdentifiers{{Dimension: infra_sdk.UniversalDimensionRegion}, {Dimension: infra_sdk.UniversalDimensionService}})
+	require.NoError(t, err)
+
+	require.Len(t, aggregator.CostResult.Series, 1)
+	for _, series := range aggregator.CostResult.Series {
+		assert.Equal(t, infra_sdk.CostSeriesGroupKeys{
+			{Name: infra_sdk.UniversalDimensionRegion, Value: "us-east-1"},
+			{Name: infra_sdk.UniversalDimensionService, Value: "Amazon Elastic Compute Cloud - Compute"},
+		}, series.GroupKeys)
+	}
+}
+
+func TestCostQueryToFilter(t *testing.T) {
+	t.Run("no filters", func(t *testing.T) {
+		expr, err := costQueryToFilter(infra_sdk.CostQuery{})
+		require.NoError(t, err)
+		assert.Nil(t, expr)
+	})
+
+	t.Run("single filter tag", func(t *testing.T) {
+		expr, err := costQueryToFilter(infra_sdk.CostQuery{
+			FilterTags: []infra_sdk.CostFilterTag{{Key: infra_sdk.UniversalTagEnv, Values: []string{"dev"}}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &cetypes.Expression{
+			Tags: &cetypes.TagValues{Key: ptr("Env"), MatchOptions: []cetypes.MatchOption{cetypes.MatchOptionEquals}, Values: []string{"dev"}},
+		}, expr)
+	})
+
+	t.Run("expression combined with filter tags", func(t *testing.T) {
+		expr, err := costQueryToFilter(infra_sdk.CostQuery{
+			FilterTags: []infra_sdk.CostFilterTag{{Key: infra_sdk.UniversalTagEnv, Values: []string{"dev"}}},
+			Filter: &infra_sdk.CostFilter{Or: []infra_sdk.CostFilter{
+				{Not: &infra_sdk.CostFilter{Dimension: &infra_sdk.CostFilterCondition{Key: infra_sdk.UniversalDimensionChargeType, Values: []string{"Credit", "Refund"}}}},
+				{Tag: &infra_sdk.CostFilterCondition{
+					Key:          infra_sdk.UniversalTagBlock,
+					MatchOptions: []infra_sdk.CostFilterMatchOption{infra_sdk.CostFilterMatchAbsent},
+				}},
+				{Dimension: &infra_sdk.CostFilterCondition{
+					Key:          infra_sdk.UniversalDimensionService,
+					Values:       []string{"amazon"},
+					MatchOptions: []infra_sdk.CostFilterMatchOption{infra_sdk.CostFilterMatchStartsWith, infra_sdk.CostFilterMatchCaseInsensitive},
+				}},
+			}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &cetypes.Expression{
+			And: []cetypes.Expression{
+				{Tags: &cetypes.TagValues{Key: ptr("Env"), MatchOptions: []cetypes.MatchOption{cetypes.MatchOptionEquals}, Values: []string{"dev"}}},
+				{Or: []cetypes.Expression{
+					{Not: &cetypes.Expression{
+						Dimensions: &cetypes.DimensionValues{Key: cetypes.DimensionRecordType, MatchOptions: []cetypes.MatchOption{cetypes.MatchOptionEquals}, Values: []string{"Credit", "Refund"}},
+					}},
+					{Tags: &cetypes.TagValues{Key: ptr("Block"), MatchOptions: []cetypes.MatchOption{cetypes.MatchOptionAbsent}}},
+					{Dimensions: &cetypes.DimensionValues{
+						Key:          cetypes.DimensionService,
+						MatchOptions: []cetypes.MatchOption{cetypes.MatchOptionStartsWith, cetypes.MatchOptionCaseInsensitive},
+						Values:       []string{"amazon"},
+					}},
+				}},
+			},
+		}, expr)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		_, err := costQueryToFilter(infra_sdk.CostQuery{Filter: &infra_sdk.CostFilter{}})
+		assert.ErrorContains(t, err, "invalid cost filter")
+	})
+}
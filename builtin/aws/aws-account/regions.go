@@ -0,0 +1,30 @@
+package aws_account
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// ListEnabledRegions retrieves the regions that are enabled for the account
+// This includes opt-in regions only if the account has opted in
+func ListEnabledRegions(ctx context.Context, config aws.Config) ([]string, error) {
+	client := ec2.NewFromConfig(config)
+	output, err := client.DescribeRegions(ctx, &ec2.DescribeRegionsInput{
+		AllRegions: aws.Bool(false),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	regions := make([]string, 0, len(output.Regions))
+	for _, region := range output.Regions {
+		if name := aws.ToString(region.RegionName); name != "" {
+			regions = append(regions, name)
+		}
+	}
+	sort.Strings(regions)
+	return regions, nil
+}
@@ -2,6 +2,7 @@ package aws_account
 
 import (
 	"context"
+	"fmt"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	infra_sdk "github.com/nullstone-io/infra-sdk"
 	"sync"
@@ -16,25 +17,58 @@ func NewResourceScanTracker() *ResourceScanTracker {
 	}
 }
 
+// NewLimitedResourceScanTracker creates a ResourceScanTracker that runs at most limit scanners at a time
+// This keeps a scan of many regions from exceeding aws api rate limits
+func NewLimitedResourceScanTracker(limit int) *ResourceScanTracker {
+	tracker := NewResourceScanTracker()
+	if limit > 0 {
+		tracker.sem = make(chan struct{}, limit)
+	}
+	return tracker
+}
+
 type ResourceScanTracker struct {
 	Resources []infra_sdk.ScanResource
 	Errors    []error
 
 	mu sync.Mutex
 	wg sync.WaitGroup
+	// sem limits the number of scanners running at once; nil is unlimited
+	sem chan struct{}
 }
 
+// Scan runs a regional rs in the background using config
+// Any resource that is not already stamped with a region is stamped with the region in config
 func (r *ResourceScanTracker) Scan(ctx context.Context, config aws.Config, rs ResourceScanner) {
+	r.scan(ctx, config, rs, config.Region, fmt.Sprintf("region %q", config.Region))
+}
+
+// ScanGlobal runs a global rs in the background using config
+// Any resource that is not already stamped with a region is stamped with GlobalRegion
+func (r *ResourceScanTracker) ScanGlobal(ctx context.Context, config aws.Config, rs ResourceScanner) {
+	r.scan(ctx, config, rs, GlobalRegion, "global services")
+}
+
+func (r *ResourceScanTracker) scan(ctx context.Context, config aws.Config, rs ResourceScanner, region string, label string) {
 	r.wg.Add(1)
 	go func() {
 		defer r.wg.Done()
+		if r.sem != nil {
+			r.sem <- struct{}{}
+			defer func() { <-r.sem }()
+		}
 
 		resources, err := rs(ctx, config)
+		for i := range resources {
+			if resources[i].Region == "" {
+				resources[i].Region = region
+			}
+		}
 
 		r.mu.Lock()
 		defer r.mu.Unlock()
 		if err != nil {
-			r.Errors = append(r.Errors, err)
+			r.Errors = append(r.Errors, fmt.Errorf("error scanning %s: %w", label, err))
 		}
 		r.Resources = append(r.Resources, resources...)
 	}()
@@ -76,7 +76,7 @@ func getRestApis(ctx context.Context, client *apigateway.Client) ([]infra_sdk.Sc
 			}
 		}
 
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: *api.Id,
 			Name:     name,
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -87,14 +87,16 @@ func getRestApis(ctx context.Context, client *apigateway.Client) ([]infra_sdk.Sc
 			},
 			ServiceName:         "API Gateway",
 			ServiceResourceName: "REST API",
-			Attributes: map[string]any{
-				"endpoint_configuration": api.EndpointConfiguration,
-				"created_date":           api.CreatedDate,
-				"api_key_source":         api.ApiKeySource,
-				"minimum_compression":    api.MinimumCompressionSize,
-				"tags":                   tags,
-			},
+		}.WithAttributes(&RestApiAttributes{
+			EndpointConfiguration: getRestApiEndpointConfiguration(api.EndpointConfiguration),
+			CreatedDate:           api.CreatedDate,
+			ApiKeySource:          string(api.ApiKeySource),
+			MinimumCompression:    api.MinimumCompressionSize,
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tags, awsTagToUniversal))
 	}
 
 	return resources, nil
@@ -154,7 +156,7 @@ func getHttpApis(ctx context.Context, config aws.Config) ([]infra_sdk.ScanResour
 			protocolType = string(api.ProtocolType)
 		}
 
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: *api.ApiId,
 			Name:     name,
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -165,19 +167,35 @@ func getHttpApis(ctx context.Context, config aws.Config) ([]infra_sdk.ScanResour
 			},
 			ServiceName:         "API Gateway",
 			ServiceResourceName: "HTTP API",
-			Attributes: map[string]any{
-				"api_endpoint":                 apiEndpoint,
-				"protocol_type":                protocolType,
-				"api_id":                       api.ApiId,
-				"api_key_selection_expression": api.ApiKeySelectionExpression,
-				"created_date":                 api.CreatedDate,
-				"description":                  api.Description,
-				"disable_execute_api_endpoint": api.DisableExecuteApiEndpoint,
-				"version":                      api.Version,
-				"tags":                         tags,
-			},
+		}.WithAttributes(&HttpApiAttributes{
+			ApiEndpoint:               apiEndpoint,
+			ProtocolType:              protocolType,
+			ApiId:                     aws.ToString(api.ApiId),
+			ApiKeySelectionExpression: aws.ToString(api.ApiKeySelectionExpression),
+			CreatedDate:               api.CreatedDate,
+			Description:               aws.ToString(api.Description),
+			DisableExecuteApiEndpoint: aws.ToBool(api.DisableExecuteApiEndpoint),
+			Version:                   aws.ToString(api.Version),
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tags, awsTagToUniversal))
 	}
 
 	return resources, nil
 }
+
+func getRestApiEndpointConfiguration(config *apigwtypes.EndpointConfiguration) *RestApiEndpointConfiguration {
+	if config == nil {
+		return nil
+	}
+	result := &RestApiEndpointConfiguration{
+		Types:          make([]string, 0, len(config.Types)),
+		VpcEndpointIds: config.VpcEndpointIds,
+	}
+	for _, endpointType := range config.Types {
+		result.Types = append(result.Types, string(endpointType))
+	}
+	return result
+}
@@ -0,0 +1,515 @@
+package aws_account
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+)
+
+const (
+	// AttributesSchemaId is the `$id` of the JSON schema for the attributes of scanned aws resources
+	AttributesSchemaId = "https://nullstone.io/schemas/infra-sdk/aws-scan-attributes.json"
+)
+
+var (
+	// AllAttributes contains the typed attributes for every kind of resource produced by AllScanners
+	AllAttributes = []infra_sdk.ScanAttributes{
+		&VpcAttributes{},
+		&EcsClusterAttributes{},
+		&RestApiAttributes{},
+		&HttpApiAttributes{},
+		&CloudFrontDistributionAttributes{},
+		&ClassicLoadBalancerAttributes{},
+		&LoadBalancerAttributes{},
+		&HostedZoneAttributes{},
+		&S3BucketAttributes{},
+		&EfsFileSystemAttributes{},
+		&RdsInstanceAttributes{},
+		&RdsClusterAttributes{},
+		&ElastiCacheClusterAttributes{},
+		&ElastiCacheReplicationGroupAttributes{},
+		&MskClusterAttributes{},
+		&MqBrokerAttributes{},
+		&SqsQueueAttributes{},
+		&SnsTopicAttributes{},
+		&OpenSearchDomainAttributes{},
+	}
+)
+
+// AttributesSchema returns the JSON schema for the attributes of every kind of scanned aws resource
+// Use ScanResource.AttributesKind to find the definition for a resource in `$defs`
+func AttributesSchema() ([]byte, error) {
+	return json.MarshalIndent(infra_sdk.ScanAttributesSchema(AttributesSchemaId, AllAttributes...), "", "  ")
+}
+
+// network
+
+type VpcAttributes struct {
+	CidrBlock       string `json:"cidr_block"`
+	IsDefault       bool   `json:"is_default"`
+	State           string `json:"state"`
+	InstanceTenancy string `json:"instance_tenancy"`
+}
+
+func (a *VpcAttributes) ScanAttributesKind() string { return "aws/vpc" }
+
+// cluster
+
+type EcsClusterAttributes struct {
+	Status            string            `json:"status"`
+	RunningTasks      int32             `json:"running_tasks"`
+	PendingTasks      int32             `json:"pending_tasks"`
+	ActiveServices    int32             `json:"active_services"`
+	Statistics        map[string]string `json:"statistics"`
+	Settings          map[string]string `json:"settings"`
+	CapacityProviders []string          `json:"capacity_providers"`
+}
+
+func (a *EcsClusterAttributes) ScanAttributesKind() string { return "aws/ecs-cluster" }
+
+// ingress
+
+type RestApiAttributes struct {
+	EndpointConfiguration *RestApiEndpointConfiguration `json:"endpoint_configuration"`
+	CreatedDate           *time.Time                    `json:"created_date"`
+	ApiKeySource          string                        `json:"api_key_source"`
+	// MinimumCompression is the minimum payload size (in bytes) that is compressed; null if compression is disabled
+	MinimumCompression *int32 `json:"minimum_compression"`
+}
+
+func (a *RestApiAttributes) ScanAttributesKind() string { return "aws/api-gateway-rest-api" }
+
+type RestApiEndpointConfiguration struct {
+	Types          []string `json:"types"`
+	VpcEndpointIds []string `json:"vpc_endpoint_ids"`
+}
+
+type HttpApiAttributes struct {
+	ApiEndpoint               string     `json:"api_endpoint"`
+	ProtocolType              string     `json:"protocol_type"`
+	ApiId                     string     `json:"api_id"`
+	ApiKeySelectionExpression string     `json:"api_key_selection_expression"`
+	CreatedDate               *time.Time `json:"created_date"`
+	Description               string     `json:"description"`
+	DisableExecuteApiEndpoint bool       `json:"disable_execute_api_endpoint"`
+	Version                   string     `json:"version"`
+}
+
+func (a *HttpApiAttributes) ScanAttributesKind() string { return "aws/api-gateway-http-api" }
+
+type CloudFrontDistributionAttributes struct {
+	Status        string   `json:"status"`
+	Enabled       bool     `json:"enabled"`
+	DomainName    string   `json:"domain_name"`
+	Domains       []string `json:"domains"`
+	HttpVersion   string   `json:"http_version"`
+	PriceClass    string   `json:"price_class"`
+	IsIpv6Enabled bool     `json:"is_ipv6_enabled"`
+	WebAclId      string   `json:"web_acl_id"`
+}
+
+func (a *CloudFrontDistributionAttributes) ScanAttributesKind() string {
+	return "aws/cloudfront-distribution"
+}
+
+type ClassicLoadBalancerAttributes struct {
+	DnsName        string                        `json:"dns_name"`
+	Scheme         string                        `json:"scheme"`
+	VpcId          string                        `json:"vpc_id"`
+	CreatedTime    *time.Time                    `json:"created_time"`
+	SecurityGroups []string                      `json:"security_groups"`
+	Listeners      []ClassicLoadBalancerListener `json:"listeners"`
+	// Instances are the ids of the EC2 instances registered with the load balancer
+	Instances []string `json:"instances"`
+}
+
+func (a *ClassicLoadBalancerAttributes) ScanAttributesKind() string { return "aws/elb-load-balancer" }
+
+type ClassicLoadBalancerListener struct {
+	Protocol         string `json:"protocol"`
+	LoadBalancerPort int32  `json:"load_balancer_port"`
+	InstanceProtocol string `json:"instance_protocol"`
+	InstancePort     int32  `json:"instance_port"`
+	SslCertificateId string `json:"ssl_certificate_id"`
+}
+
+type LoadBalancerAttributes struct {
+	DnsName           string                         `json:"dns_name"`
+	Scheme            string                         `json:"scheme"`
+	VpcId             string                         `json:"vpc_id"`
+	CreatedTime       *time.Time                     `json:"created_time"`
+	SecurityGroups    []string                       `json:"security_groups"`
+	IpAddressType     string                         `json:"ip_address_type"`
+	Listeners         []LoadBalancerListener         `json:"listeners"`
+	AvailabilityZones []LoadBalancerAvailabilityZone `json:"availability_zones"`
+	Type              string                         `json:"type"`
+}
+
+func (a *LoadBalancerAttributes) ScanAttributesKind() string { return "aws/elbv2-load-balancer" }
+
+type LoadBalancerListener struct {
+	ListenerArn string `json:"listener_arn"`
+	Protocol    string `json:"protocol"`
+	Port        int32  `json:"port"`
+	SslPolicy   string `json:"ssl_policy"`
+	// Certificates are the ARNs of the certificates attached to the listener
+	Certificates []string `json:"certificates"`
+}
+
+type LoadBalancerAvailabilityZone struct {
+	ZoneName string `json:"zone_name"`
+	SubnetId string `json:"subnet_id"`
+}
+
+// domain/subdomain
+
+type HostedZoneAttributes struct {
+	Name                   string `json:"name"`
+	ZoneId                 string `json:"zone_id"`
+	Comment                string `json:"comment"`
+	PrivateZone            bool   `json:"private_zone"`
+	ResourceRecordSetCount int64  `json:"resource_record_set_count"`
+}
+
+func (a *HostedZoneAttributes) ScanAttributesKind() string { return "aws/route53-hosted-zone" }
+
+// datastore
+
+type S3BucketAttributes struct {
+	Arn          string           `json:"arn"`
+	CreationDate *time.Time       `json:"creation_date"`
+	Region       string           `json:"region"`
+	Cors         *S3BucketCors    `json:"cors"`
+	Website      *S3BucketWebsite `json:"website"`
+}
+
+func (a *S3BucketAttributes) ScanAttributesKind() string { return "aws/s3-bucket" }
+
+type S3BucketCors struct {
+	CorsRules []S3BucketCorsRule `json:"cors_rules"`
+}
+
+type S3BucketCorsRule struct {
+	AllowedHeaders []string `json:"allowed_headers"`
+	AllowedMethods []string `json:"allowed_methods"`
+	AllowedOrigins []string `json:"allowed_origins"`
+	ExposeHeaders  []string `json:"expose_headers"`
+	MaxAgeSeconds  *int32   `json:"max_age_seconds"`
+}
+
+type S3BucketWebsite struct {
+	IndexDocument string `json:"index_document"`
+	ErrorDocument string `json:"error_document"`
+	// RedirectAllRequestsTo is the host name that all requests are redirected to
+	RedirectAllRequestsTo string                       `json:"redirect_all_requests_to"`
+	RoutingRules          []S3BucketWebsiteRoutingRule `json:"routing_rules"`
+}
+
+type S3BucketWebsiteRoutingRule struct {
+	KeyPrefixEquals             string `json:"key_prefix_equals"`
+	HttpErrorCodeReturnedEquals string `json:"http_error_code_returned_equals"`
+	HostName                    string `json:"host_name"`
+	HttpRedirectCode            string `json:"http_redirect_code"`
+	Protocol                    string `json:"protocol"`
+	ReplaceKeyPrefixWith        string `json:"replace_key_prefix_with"`
+	ReplaceKeyWith              string `json:"replace_key_with"`
+}
+
+type EfsFileSystemAttributes struct {
+	Arn                          string                   `json:"arn"`
+	FileSystemId                 string                   `json:"file_system_id"`
+	CreationToken                string                   `json:"creation_token"`
+	CreationTime                 *time.Time               `json:"creation_time"`
+	LifeCycleState               string                   `json:"life_cycle_state"`
+	NumberOfMountTargets         int32                    `json:"number_of_mount_targets"`
+	OwnerId                      string                   `json:"owner_id"`
+	SizeInBytes                  *EfsFileSystemSize       `json:"size_in_bytes"`
+	PerformanceMode              string                   `json:"performance_mode"`
+	Encrypted                    bool                     `json:"encrypted"`
+	KmsKeyId                     string                   `json:"kms_key_id"`
+	ThroughputMode               string                   `json:"throughput_mode"`
+	ProvisionedThroughputInMibps float64                  `json:"provisioned_throughput_in_mibps"`
+	AvailabilityZoneName         string                   `json:"availability_zone_name"`
+	AvailabilityZoneId           string                   `json:"availability_zone_id"`
+	MountTargets                 []EfsMountTarget         `json:"mount_targets"`
+	FileSystemPolicy             string                   `json:"file_system_policy"`
+	FileSystemProtection         *EfsFileSystemProtection `json:"file_system_protection"`
+}
+
+func (a *EfsFileSystemAttributes) ScanAttributesKind() string { return "aws/efs-file-system" }
+
+type EfsFileSystemSize struct {
+	Value           int64      `json:"value"`
+	Timestamp       *time.Time `json:"timestamp"`
+	ValueInIa       *int64     `json:"value_in_ia,omitempty"`
+	ValueInStandard *int64     `json:"value_in_standard,omitempty"`
+	ValueInArchive  *int64     `json:"value_in_archive,omitempty"`
+}
+
+type EfsMountTarget struct {
+	MountTargetId      string `json:"mount_target_id"`
+	SubnetId           string `json:"subnet_id"`
+	LifecycleState     string `json:"lifecycle_state"`
+	IpAddress          string `json:"ip_address"`
+	AvailabilityZone   string `json:"availability_zone"`
+	AvailabilityZoneId string `json:"availability_zone_id"`
+	OwnerId            string `json:"owner_id"`
+	NetworkInterfaceId string `json:"network_interface_id,omitempty"`
+}
+
+type EfsFileSystemProtection struct {
+	ReplicationOverwriteProtection string `json:"replication_overwrite_protection"`
+}
+
+type RdsInstanceAttributes struct {
+	Identifier       string       `json:"identifier"`
+	Engine           string       `json:"engine"`
+	EngineVersion    string       `json:"engine_version"`
+	InstanceClass    string       `json:"instance_class"`
+	StorageType      string       `json:"storage_type"`
+	AllocatedStorage int32        `json:"allocated_storage"`
+	MultiAz          bool         `json:"multi_az"`
+	Status           string       `json:"status"`
+	Endpoint         *RdsEndpoint `json:"endpoint"`
+}
+
+func (a *RdsInstanceAttributes) ScanAttributesKind() string { return "aws/rds-instance" }
+
+type RdsEndpoint struct {
+	Address      string `json:"address"`
+	Port         int32  `json:"port"`
+	HostedZoneId string `json:"hosted_zone_id"`
+}
+
+type RdsClusterAttributes struct {
+	Identifier        string                `json:"identifier"`
+	Engine            string                `json:"engine"`
+	EngineVersion     string                `json:"engine_version"`
+	EngineMode        string                `json:"engine_mode"`
+	Status            string                `json:"status"`
+	WriterEndpoint    string                `json:"writer_endpoint"`
+	ReaderEndpoints   []string              `json:"reader_endpoints"`
+	MultiAz           bool                  `json:"multi_az"`
+	StorageEncrypted  bool                  `json:"storage_encrypted"`
+	DatabaseName      string                `json:"database_name"`
+	BackupRetention   int32                 `json:"backup_retention"`
+	ClusterMembers    []RdsClusterMember    `json:"cluster_members"`
+	VpcSecurityGroups []RdsVpcSecurityGroup `json:"vpc_security_groups"`
+}
+
+func (a *RdsClusterAttributes) ScanAttributesKind() string { return "aws/rds-cluster" }
+
+type RdsClusterMember struct {
+	DbInstanceIdentifier string `json:"db_instance_identifier"`
+	IsClusterWriter      bool   `json:"is_cluster_writer"`
+	PromotionTier        int32  `json:"promotion_tier"`
+}
+
+type RdsVpcSecurityGroup struct {
+	VpcSecurityGroupId string `json:"vpc_security_group_id"`
+	Status             string `json:"status"`
+}
+
+type ElastiCacheClusterAttributes struct {
+	Arn                        string                     `json:"arn"`
+	Engine                     string                     `json:"engine"`
+	EngineVersion              string                     `json:"engine_version"`
+	NodeType                   string                     `json:"node_type"`
+	NumCacheNodes              int32                      `json:"num_cache_nodes"`
+	Endpoints                  []ElastiCacheEndpoint      `json:"endpoints"`
+	SecurityGroups             []ElastiCacheSecurityGroup `json:"security_groups"`
+	ParameterGroup             string                     `json:"parameter_group"`
+	SubnetGroup                string                     `json:"subnet_group"`
+	MaintenanceWindow          string                     `json:"maintenance_window"`
+	NotificationConfiguration  string                     `json:"notification_configuration"`
+	AutoMinorVersionUpgrade    bool                       `json:"auto_minor_version_upgrade"`
+	CacheClusterStatus         string                     `json:"cache_cluster_status"`
+	CacheNodeType              string                     `json:"cache_node_type"`
+	CacheParameterGroup        string                     `json:"cache_parameter_group"`
+	CacheSecurityGroups        []ElastiCacheSecurityGroup `json:"cache_security_groups"`
+	CacheSubnetGroupName       string                     `json:"cache_subnet_group_name"`
+	ClientDownloadLandingPage  string                     `json:"client_download_landing_page"`
+	ConfigurationEndpoint      *ElastiCacheEndpoint       `json:"configuration_endpoint"`
+	PreferredAvailabilityZone  string                     `json:"preferred_availability_zone"`
+	PreferredMaintenanceWindow string                     `json:"preferred_maintenance_window"`
+	ReplicationGroupId         string                     `json:"replication_group_id"`
+	SnapshotRetentionLimit     int32                      `json:"snapshot_retention_limit"`
+	SnapshotWindow             string                     `json:"snapshot_window"`
+}
+
+func (a *ElastiCacheClusterAttributes) ScanAttributesKind() string { return "aws/elasticache-cluster" }
+
+type ElastiCacheEndpoint struct {
+	Address string `json:"address"`
+	Port    int32  `json:"port"`
+}
+
+type ElastiCacheSecurityGroup struct {
+	SecurityGroupId string `json:"security_group_id"`
+	Status          string `json:"status"`
+}
+
+type ElastiCacheReplicationGroupAttributes struct {
+	Arn                    string                 `json:"arn"`
+	Status                 string                 `json:"status"`
+	Description            string                 `json:"description"`
+	NodeGroups             []ElastiCacheNodeGroup `json:"node_groups"`
+	AutomaticFailover      string                 `json:"automatic_failover"`
+	MultiAz                string                 `json:"multi_az"`
+	ConfigurationEndpoint  *ElastiCacheEndpoint   `json:"configuration_endpoint"`
+	SnapshotRetentionLimit int32                  `json:"snapshot_retention_limit"`
+	SnapshotWindow         string                 `json:"snapshot_window"`
+	ClusterEnabled         bool                   `json:"cluster_enabled"`
+}
+
+func (a *ElastiCacheReplicationGroupAttributes) ScanAttributesKind() string {
+	return "aws/elasticache-replication-group"
+}
+
+type ElastiCacheNodeGroup struct {
+	NodeGroupId     string                       `json:"node_group_id"`
+	Status          string                       `json:"status"`
+	Slots           string                       `json:"slots"`
+	PrimaryEndpoint *ElastiCacheEndpoint         `json:"primary_endpoint"`
+	ReaderEndpoint  *ElastiCacheEndpoint         `json:"reader_endpoint"`
+	Members         []ElastiCacheNodeGroupMember `json:"members"`
+}
+
+type ElastiCacheNodeGroupMember struct {
+	CacheClusterId            string               `json:"cache_cluster_id"`
+	CacheNodeId               string               `json:"cache_node_id"`
+	CurrentRole               string               `json:"current_role"`
+	PreferredAvailabilityZone string               `json:"preferred_availability_zone"`
+	ReadEndpoint              *ElastiCacheEndpoint `json:"read_endpoint"`
+}
+
+type MskClusterAttributes struct {
+	Arn                 string     `json:"arn"`
+	ClusterType         string     `json:"cluster_type"`
+	State               string     `json:"state"`
+	KafkaVersion        string     `json:"kafka_version"`
+	NumberOfBrokerNodes int32      `json:"number_of_broker_nodes"`
+	ZookeeperConnect    string     `json:"zookeeper_connect"`
+	CreationTime        *time.Time `json:"creation_time"`
+}
+
+func (a *MskClusterAttributes) ScanAttributesKind() string { return "aws/msk-cluster" }
+
+type MqBrokerAttributes struct {
+	Arn                     string `json:"arn"`
+	BrokerId                string `json:"broker_id"`
+	BrokerState             string `json:"broker_state"`
+	EngineVersion           string `json:"engine_version"`
+	InstanceType            string `json:"instance_type"`
+	DeploymentMode          string `json:"deployment_mode"`
+	AuthenticationStrategy  string `json:"authentication_strategy"`
+	PubliclyAccessible      bool   `json:"publicly_accessible"`
+	AutoMinorVersionUpgrade bool   `json:"auto_minor_version_upgrade"`
+	// Endpoints maps a protocol (e.g. console, amqps) to its endpoint
+	Endpoints                  map[string]string    `json:"endpoints"`
+	Created                    *time.Time           `json:"created"`
+	MaintenanceWindowStartTime *MqMaintenanceWindow `json:"maintenance_window_start_time"`
+	Logs                       *MqLogs              `json:"logs"`
+}
+
+func (a *MqBrokerAttributes) ScanAttributesKind() string { return "aws/mq-broker" }
+
+type MqMaintenanceWindow struct {
+	DayOfWeek string `json:"day_of_week"`
+	TimeOfDay string `json:"time_of_day"`
+	TimeZone  string `json:"time_zone"`
+}
+
+type MqLogs struct {
+	Audit   bool `json:"audit"`
+	General bool `json:"general"`
+}
+
+// SqsQueueAttributes numeric fields are null if sqs did not report the attribute
+type SqsQueueAttributes struct {
+	Url                                   string `json:"url"`
+	Arn                                   string `json:"arn"`
+	FifoQueue                             bool   `json:"fifo_queue"`
+	VisibilityTimeout                     *int64 `json:"visibility_timeout"`
+	MessageRetentionPeriod                *int64 `json:"message_retention_period"`
+	RedrivePolicy                         string `json:"redrive_policy"`
+	ApproximateNumberOfMessages           *int64 `json:"approximate_number_of_messages"`
+	ApproximateNumberOfMessagesNotVisible *int64 `json:"approximate_number_of_messages_not_visible"`
+	ApproximateNumberOfMessagesDelayed    *int64 `json:"approximate_number_of_messages_delayed"`
+	// CreatedTimestamp and LastModifiedTimestamp are in epoch seconds
+	CreatedTimestamp      *int64 `json:"created_timestamp"`
+	LastModifiedTimestamp *int64 `json:"last_modified_timestamp"`
+}
+
+func (a *SqsQueueAttributes) ScanAttributesKind() string { return "aws/sqs-queue" }
+
+// SnsTopicAttributes subscription counts are null if sns did not report the attribute
+type SnsTopicAttributes struct {
+	Arn                       string                     `json:"arn"`
+	Owner                     string                     `json:"owner"`
+	SubscriptionsConfirmed    *int64                     `json:"subscriptions_confirmed"`
+	SubscriptionsDeleted      *int64                     `json:"subscriptions_deleted"`
+	SubscriptionsPending      *int64                     `json:"subscriptions_pending"`
+	KmsMasterKeyId            string                     `json:"kms_master_key_id"`
+	FifoTopic                 bool                       `json:"fifo_topic"`
+	ContentBasedDeduplication bool                       `json:"content_based_deduplication"`
+	Policy                    string                     `json:"policy"`
+	DeliveryStatusLogging     []SnsDeliveryStatusLogging `json:"delivery_status_logging"`
+	Subscriptions             []SnsSubscription          `json:"subscriptions"`
+}
+
+func (a *SnsTopicAttributes) ScanAttributesKind() string { return "aws/sns-topic" }
+
+type SnsDeliveryStatusLogging struct {
+	ApplicationSuccessFeedbackRoleArn    string `json:"application_success_feedback_role_arn"`
+	ApplicationSuccessFeedbackSampleRate string `json:"application_success_feedback_sample_rate"`
+	HttpSuccessFeedbackRoleArn           string `json:"http_success_feedback_role_arn"`
+	HttpSuccessFeedbackSampleRate        string `json:"http_success_feedback_sample_rate"`
+	LambdaSuccessFeedbackRoleArn         string `json:"lambda_success_feedback_role_arn"`
+	LambdaSuccessFeedbackSampleRate      string `json:"lambda_success_feedback_sample_rate"`
+}
+
+type SnsSubscription struct {
+	SubscriptionArn string `json:"subscription_arn"`
+	Protocol        string `json:"protocol"`
+	Endpoint        string `json:"endpoint"`
+	Owner           string `json:"owner"`
+	TopicArn        string `json:"topic_arn"`
+}
+
+type OpenSearchDomainAttributes struct {
+	Arn                     string                             `json:"arn"`
+	Endpoint                string                             `json:"endpoint"`
+	Version                 string                             `json:"version"`
+	InstanceType            string                             `json:"instance_type"`
+	InstanceCount           int32                              `json:"instance_count"`
+	EncryptionAtRest        bool                               `json:"encryption_at_rest"`
+	NodeToNodeEncryption    bool                               `json:"node_to_node_encryption"`
+	Created                 bool                               `json:"created"`
+	Deleted                 bool                               `json:"deleted"`
+	Processing              bool                               `json:"processing"`
+	UpgradeProcessing       bool                               `json:"upgrade_processing"`
+	AccessPolicies          string                             `json:"access_policies"`
+	AdvancedOptions         map[string]string                  `json:"advanced_options"`
+	AdvancedSecurityOptions *OpenSearchAdvancedSecurityOptions `json:"advanced_security_options"`
+}
+
+func (a *OpenSearchDomainAttributes) ScanAttributesKind() string { return "aws/opensearch-domain" }
+
+type OpenSearchAdvancedSecurityOptions struct {
+	Enabled                     bool `json:"enabled"`
+	InternalUserDatabaseEnabled bool `json:"internal_user_database_enabled"`
+	SamlEnabled                 bool `json:"saml_enabled"`
+}
+
+// parseInt parses a numeric attribute that aws returns as a string
+// nil is returned if the attribute is missing or is not a number so that it is not confused with 0
+func parseInt(raw string) *int64 {
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,14 @@
+package aws_account
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInt(t *testing.T) {
+	assert.Equal(t, ptr(int64(30)), parseInt("30"))
+	assert.Equal(t, ptr(int64(0)), parseInt("0"))
+	assert.Nil(t, parseInt(""))
+	assert.Nil(t, parseInt("thirty"))
+}
@@ -75,7 +75,7 @@ func getCloudFrontDistributions(ctx context.Context, client *cloudfront.Client)
 			domains = append(domains, alias)
 		}
 
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: *dist.Id,
 			Name:     name,
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -86,18 +86,21 @@ func getCloudFrontDistributions(ctx context.Context, client *cloudfront.Client)
 			},
 			ServiceName:         "CloudFront",
 			ServiceResourceName: "Distribution",
-			Attributes: map[string]any{
-				"status":          dist.Status,
-				"enabled":         dist.Enabled,
-				"domain_name":     dist.DomainName,
-				"domains":         domains,
-				"http_version":    dist.HttpVersion,
-				"price_class":     dist.PriceClass,
-				"is_ipv6_enabled": dist.IsIPV6Enabled,
-				"web_acl_id":      dist.WebACLId,
-				"tags":            tags,
-			},
+			Region:              GlobalRegion,
+		}.WithAttributes(&CloudFrontDistributionAttributes{
+			Status:        aws.ToString(dist.Status),
+			Enabled:       aws.ToBool(dist.Enabled),
+			DomainName:    aws.ToString(dist.DomainName),
+			Domains:       domains,
+			HttpVersion:   string(dist.HttpVersion),
+			PriceClass:    string(dist.PriceClass),
+			IsIpv6Enabled: aws.ToBool(dist.IsIPV6Enabled),
+			WebAclId:      aws.ToString(dist.WebACLId),
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tags, awsTagToUniversal))
 	}
 
 	return resources, nil
@@ -53,9 +53,9 @@ func ScanEcsClusters(ctx context.Context, config aws.Config) ([]infra_sdk.ScanRe
 		}
 
 		// Collect cluster settings
-		settings := make(map[string]any)
+		settings := make(map[string]string)
 		for _, setting := range cluster.Settings {
-			settings[string(setting.Name)] = setting.Value
+			settings[string(setting.Name)] = aws.ToString(setting.Value)
 		}
 
 		// Get statistics if available
@@ -71,7 +71,7 @@ func ScanEcsClusters(ctx context.Context, config aws.Config) ([]infra_sdk.ScanRe
 		}
 
 		name := aws.ToString(cluster.ClusterName)
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: aws.ToString(cluster.ClusterArn),
 			Name:     name,
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -83,17 +83,19 @@ func ScanEcsClusters(ctx context.Context, config aws.Config) ([]infra_sdk.ScanRe
 			},
 			ServiceName:         serviceName,
 			ServiceResourceName: "Cluster",
-			Attributes: map[string]any{
-				"status":             cluster.Status,
-				"running_tasks":      cluster.RunningTasksCount,
-				"pending_tasks":      cluster.PendingTasksCount,
-				"active_services":    cluster.ActiveServicesCount,
-				"statistics":         stats,
-				"settings":           settings,
-				"capacity_providers": capacityProviders,
-				"tags":               tags,
-			},
+		}.WithAttributes(&EcsClusterAttributes{
+			Status:            aws.ToString(cluster.Status),
+			RunningTasks:      cluster.RunningTasksCount,
+			PendingTasks:      cluster.PendingTasksCount,
+			ActiveServices:    cluster.ActiveServicesCount,
+			Statistics:        stats,
+			Settings:          settings,
+			CapacityProviders: capacityProviders,
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tags, awsTagToUniversal))
 	}
 
 	return resources, nil
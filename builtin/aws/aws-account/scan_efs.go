@@ -73,42 +73,34 @@ func ScanEfsFileSystems(ctx context.Context, config aws.Config) ([]infra_sdk.Sca
 		}
 
 		// Get mount target details
-		var mountTargetDetails []map[string]any
+		mountTargetDetails := make([]EfsMountTarget, 0, len(mountTargets.MountTargets))
 		for _, mt := range mountTargets.MountTargets {
-			mtDetail := map[string]any{
-				"mount_target_id":      aws.ToString(mt.MountTargetId),
-				"subnet_id":            aws.ToString(mt.SubnetId),
-				"lifecycle_state":      string(mt.LifeCycleState),
-				"ip_address":           aws.ToString(mt.IpAddress),
-				"availability_zone":    aws.ToString(mt.AvailabilityZoneName),
-				"availability_zone_id": aws.ToString(mt.AvailabilityZoneId),
-				"owner_id":             aws.ToString(mt.OwnerId),
-			}
-
-			// Get network interface ID if available
-			if mt.NetworkInterfaceId != nil {
-				mtDetail["network_interface_id"] = *mt.NetworkInterfaceId
-			}
-
-			mountTargetDetails = append(mountTargetDetails, mtDetail)
+			mountTargetDetails = append(mountTargetDetails, EfsMountTarget{
+				MountTargetId:      aws.ToString(mt.MountTargetId),
+				SubnetId:           aws.ToString(mt.SubnetId),
+				LifecycleState:     string(mt.LifeCycleState),
+				IpAddress:          aws.ToString(mt.IpAddress),
+				AvailabilityZone:   aws.ToString(mt.AvailabilityZoneName),
+				AvailabilityZoneId: aws.ToString(mt.AvailabilityZoneId),
+				OwnerId:            aws.ToString(mt.OwnerId),
+				NetworkInterfaceId: aws.ToString(mt.NetworkInterfaceId),
+			})
 		}
 
 		// Get file system policy
-		var policy map[string]any
+		var policy string
 		policyOutput, err := client.DescribeFileSystemPolicy(ctx, &efs.DescribeFileSystemPolicyInput{
 			FileSystemId: fs.FileSystemId,
 		})
 		if err == nil && policyOutput.Policy != nil {
-			policy = map[string]any{
-				"policy": *policyOutput.Policy,
-			}
+			policy = *policyOutput.Policy
 		}
 
 		// Get file system protection
-		var protection map[string]any
+		var protection *EfsFileSystemProtection
 		if fs.FileSystemProtection != nil {
-			protection = map[string]any{
-				"replication_overwrite_protection": string(fs.FileSystemProtection.ReplicationOverwriteProtection),
+			protection = &EfsFileSystemProtection{
+				ReplicationOverwriteProtection: string(fs.FileSystemProtection.ReplicationOverwriteProtection),
 			}
 		}
 
@@ -124,7 +116,7 @@ func ScanEfsFileSystems(ctx context.Context, config aws.Config) ([]infra_sdk.Sca
 		}
 
 		// Create the resource
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: *fs.FileSystemArn,
 			Name:     *fs.Name,
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -135,52 +127,44 @@ func ScanEfsFileSystems(ctx context.Context, config aws.Config) ([]infra_sdk.Sca
 			},
 			ServiceName:         "EFS",
 			ServiceResourceName: "File System",
-			Attributes: map[string]any{
-				"arn":                             fs.FileSystemArn,
-				"file_system_id":                  fs.FileSystemId,
-				"creation_token":                  fs.CreationToken,
-				"creation_time":                   fs.CreationTime,
-				"life_cycle_state":                string(fs.LifeCycleState),
-				"number_of_mount_targets":         fs.NumberOfMountTargets,
-				"owner_id":                        fs.OwnerId,
-				"size_in_bytes":                   getSizeInBytes(fs.SizeInBytes),
-				"performance_mode":                string(fs.PerformanceMode),
-				"encrypted":                       fs.Encrypted,
-				"kms_key_id":                      fs.KmsKeyId,
-				"throughput_mode":                 throughputMode,
-				"provisioned_throughput_in_mibps": provisionedThroughputInMibps,
-				"availability_zone_name":          fs.AvailabilityZoneName,
-				"availability_zone_id":            fs.AvailabilityZoneId,
-				"mount_targets":                   mountTargetDetails,
-				"file_system_policy":              policy,
-				"file_system_protection":          protection,
-				"tags":                            tagMap[aws.ToString(fs.FileSystemArn)],
-			},
+		}.WithAttributes(&EfsFileSystemAttributes{
+			Arn:                          aws.ToString(fs.FileSystemArn),
+			FileSystemId:                 aws.ToString(fs.FileSystemId),
+			CreationToken:                aws.ToString(fs.CreationToken),
+			CreationTime:                 fs.CreationTime,
+			LifeCycleState:               string(fs.LifeCycleState),
+			NumberOfMountTargets:         fs.NumberOfMountTargets,
+			OwnerId:                      aws.ToString(fs.OwnerId),
+			SizeInBytes:                  getSizeInBytes(fs.SizeInBytes),
+			PerformanceMode:              string(fs.PerformanceMode),
+			Encrypted:                    aws.ToBool(fs.Encrypted),
+			KmsKeyId:                     aws.ToString(fs.KmsKeyId),
+			ThroughputMode:               throughputMode,
+			ProvisionedThroughputInMibps: provisionedThroughputInMibps,
+			AvailabilityZoneName:         aws.ToString(fs.AvailabilityZoneName),
+			AvailabilityZoneId:           aws.ToString(fs.AvailabilityZoneId),
+			MountTargets:                 mountTargetDetails,
+			FileSystemPolicy:             policy,
+			FileSystemProtection:         protection,
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tagMap[aws.ToString(fs.FileSystemArn)], awsTagToUniversal))
 	}
 
 	return resources, nil
 }
 
-func getSizeInBytes(size *efstypes.FileSystemSize) map[string]any {
+func getSizeInBytes(size *efstypes.FileSystemSize) *EfsFileSystemSize {
 	if size == nil {
 		return nil
 	}
-
-	result := map[string]any{
-		"value":     size.Value,
-		"timestamp": size.Timestamp,
-	}
-
-	if size.ValueInIA != nil {
-		result["value_in_ia"] = *size.ValueInIA
+	return &EfsFileSystemSize{
+		Value:           size.Value,
+		Timestamp:       size.Timestamp,
+		ValueInIa:       size.ValueInIA,
+		ValueInStandard: size.ValueInStandard,
+		ValueInArchive:  size.ValueInArchive,
 	}
-	if size.ValueInStandard != nil {
-		result["value_in_standard"] = *size.ValueInStandard
-	}
-	if size.ValueInArchive != nil {
-		result["value_in_archive"] = *size.ValueInArchive
-	}
-
-	return result
 }
@@ -96,22 +96,19 @@ func ScanElastiCacheClusters(ctx context.Context, config aws.Config) ([]infra_sd
 		}
 
 		// Get endpoints
-		var endpoints []map[string]string
+		endpoints := make([]ElastiCacheEndpoint, 0, len(cluster.CacheNodes))
 		for _, node := range cluster.CacheNodes {
-			if node.Endpoint != nil {
-				endpoints = append(endpoints, map[string]string{
-					"address": aws.ToString(node.Endpoint.Address),
-					"port":    fmt.Sprintf("%d", aws.ToInt32(node.Endpoint.Port)),
-				})
+			if endpoint := getElastiCacheEndpoint(node.Endpoint); endpoint != nil {
+				endpoints = append(endpoints, *endpoint)
 			}
 		}
 
 		// Get security groups
-		var securityGroups []map[string]string
+		securityGroups := make([]ElastiCacheSecurityGroup, 0, len(cluster.SecurityGroups))
 		for _, sg := range cluster.SecurityGroups {
-			securityGroups = append(securityGroups, map[string]string{
-				"security_group_id": aws.ToString(sg.SecurityGroupId),
-				"status":            aws.ToString(sg.Status),
+			securityGroups = append(securityGroups, ElastiCacheSecurityGroup{
+				SecurityGroupId: aws.ToString(sg.SecurityGroupId),
+				Status:          aws.ToString(sg.Status),
 			})
 		}
 
@@ -139,7 +136,7 @@ func ScanElastiCacheClusters(ctx context.Context, config aws.Config) ([]infra_sd
 			notificationConfig = *cluster.NotificationConfiguration.TopicArn
 		}
 
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: *cluster.ARN,
 			Name:     *cluster.CacheClusterId,
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -150,34 +147,36 @@ func ScanElastiCacheClusters(ctx context.Context, config aws.Config) ([]infra_sd
 			},
 			ServiceName:         "ElastiCache",
 			ServiceResourceName: "Cluster",
-			Attributes: map[string]any{
-				"arn":                          cluster.ARN,
-				"engine":                       engine,
-				"engine_version":               engineVersion,
-				"node_type":                    nodeType,
-				"num_cache_nodes":              cluster.NumCacheNodes,
-				"endpoints":                    endpoints,
-				"security_groups":              securityGroups,
-				"parameter_group":              parameterGroup,
-				"subnet_group":                 subnetGroup,
-				"maintenance_window":           maintenanceWindow,
-				"notification_configuration":   notificationConfig,
-				"auto_minor_version_upgrade":   cluster.AutoMinorVersionUpgrade,
-				"cache_cluster_status":         cluster.CacheClusterStatus,
-				"cache_node_type":              cluster.CacheNodeType,
-				"cache_parameter_group":        parameterGroup,
-				"cache_security_groups":        securityGroups,
-				"cache_subnet_group_name":      subnetGroup,
-				"client_download_landing_page": cluster.ClientDownloadLandingPage,
-				"configuration_endpoint":       cluster.ConfigurationEndpoint,
-				"preferred_availability_zone":  cluster.PreferredAvailabilityZone,
-				"preferred_maintenance_window": maintenanceWindow,
-				"replication_group_id":         cluster.ReplicationGroupId,
-				"snapshot_retention_limit":     cluster.SnapshotRetentionLimit,
-				"snapshot_window":              cluster.SnapshotWindow,
-				"tags":                         tagMap[aws.ToString(cluster.ARN)],
-			},
+		}.WithAttributes(&ElastiCacheClusterAttributes{
+			Arn:                        aws.ToString(cluster.ARN),
+			Engine:                     engine,
+			EngineVersion:              engineVersion,
+			NodeType:                   nodeType,
+			NumCacheNodes:              aws.ToInt32(cluster.NumCacheNodes),
+			Endpoints:                  endpoints,
+			SecurityGroups:             securityGroups,
+			ParameterGroup:             parameterGroup,
+			SubnetGroup:                subnetGroup,
+			MaintenanceWindow:          maintenanceWindow,
+			NotificationConfiguration:  notificationConfig,
+			AutoMinorVersionUpgrade:    aws.ToBool(cluster.AutoMinorVersionUpgrade),
+			CacheClusterStatus:         aws.ToString(cluster.CacheClusterStatus),
+			CacheNodeType:              aws.ToString(cluster.CacheNodeType),
+			CacheParameterGroup:        parameterGroup,
+			CacheSecurityGroups:        securityGroups,
+			CacheSubnetGroupName:       subnetGroup,
+			ClientDownloadLandingPage:  aws.ToString(cluster.ClientDownloadLandingPage),
+			ConfigurationEndpoint:      getElastiCacheEndpoint(cluster.ConfigurationEndpoint),
+			PreferredAvailabilityZone:  aws.ToString(cluster.PreferredAvailabilityZone),
+			PreferredMaintenanceWindow: maintenanceWindow,
+			ReplicationGroupId:         aws.ToString(cluster.ReplicationGroupId),
+			SnapshotRetentionLimit:     aws.ToInt32(cluster.SnapshotRetentionLimit),
+			SnapshotWindow:             aws.ToString(cluster.SnapshotWindow),
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tagMap[aws.ToString(cluster.ARN)], awsTagToUniversal))
 	}
 
 	// Also scan Redis replication groups
@@ -202,7 +201,7 @@ func ScanElastiCacheClusters(ctx context.Context, config aws.Config) ([]infra_sd
 				tags = tagMap[aws.ToString(rg.ARN)]
 			}
 
-			resources = append(resources, infra_sdk.ScanResource{
+			scanResource, err := infra_sdk.ScanResource{
 				UniqueId: *rg.ARN,
 				Name:     *rg.ReplicationGroupId,
 				Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -213,20 +212,22 @@ func ScanElastiCacheClusters(ctx context.Context, config aws.Config) ([]infra_sd
 				},
 				ServiceName:         "ElastiCache",
 				ServiceResourceName: "Cluster",
-				Attributes: map[string]any{
-					"arn":                      rg.ARN,
-					"status":                   rg.Status,
-					"description":              rg.Description,
-					"node_groups":              rg.NodeGroups,
-					"automatic_failover":       string(rg.AutomaticFailover),
-					"multi_az":                 string(rg.MultiAZ),
-					"configuration_endpoint":   rg.ConfigurationEndpoint,
-					"snapshot_retention_limit": rg.SnapshotRetentionLimit,
-					"snapshot_window":          rg.SnapshotWindow,
-					"cluster_enabled":          rg.ClusterEnabled,
-					"tags":                     tags,
-				},
+			}.WithAttributes(&ElastiCacheReplicationGroupAttributes{
+				Arn:                    aws.ToString(rg.ARN),
+				Status:                 aws.ToString(rg.Status),
+				Description:            aws.ToString(rg.Description),
+				NodeGroups:             getNodeGroups(rg.NodeGroups),
+				AutomaticFailover:      string(rg.AutomaticFailover),
+				MultiAz:                string(rg.MultiAZ),
+				ConfigurationEndpoint:  getElastiCacheEndpoint(rg.ConfigurationEndpoint),
+				SnapshotRetentionLimit: aws.ToInt32(rg.SnapshotRetentionLimit),
+				SnapshotWindow:         aws.ToString(rg.SnapshotWindow),
+				ClusterEnabled:         aws.ToBool(rg.ClusterEnabled),
 			})
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, scanResource.WithTags(tags, awsTagToUniversal))
 		}
 	}
 
@@ -255,3 +256,38 @@ func listReplicationGroups(ctx context.Context, client *elasticache.Client) ([]e
 
 	return replicationGroups, nil
 }
+
+func getElastiCacheEndpoint(endpoint *elasticachetypes.Endpoint) *ElastiCacheEndpoint {
+	if endpoint == nil {
+		return nil
+	}
+	return &ElastiCacheEndpoint{
+		Address: aws.ToString(endpoint.Address),
+		Port:    aws.ToInt32(endpoint.Port),
+	}
+}
+
+func getNodeGroups(nodeGroups []elasticachetypes.NodeGroup) []ElastiCacheNodeGroup {
+	result := make([]ElastiCacheNodeGroup, 0, len(nodeGroups))
+	for _, nodeGroup := range nodeGroups {
+		members := make([]ElastiCacheNodeGroupMember, 0, len(nodeGroup.NodeGroupMembers))
+		for _, member := range nodeGroup.NodeGroupMembers {
+			members = append(members, ElastiCacheNodeGroupMember{
+				CacheClusterId:            aws.ToString(member.CacheClusterId),
+				CacheNodeId:               aws.ToString(member.CacheNodeId),
+				CurrentRole:               aws.ToString(member.CurrentRole),
+				PreferredAvailabilityZone: aws.ToString(member.PreferredAvailabilityZone),
+				ReadEndpoint:              getElastiCacheEndpoint(member.ReadEndpoint),
+			})
+		}
+		result = append(result, ElastiCacheNodeGroup{
+			NodeGroupId:     aws.ToString(nodeGroup.NodeGroupId),
+			Status:          aws.ToString(nodeGroup.Status),
+			Slots:           aws.ToString(nodeGroup.Slots),
+			PrimaryEndpoint: getElastiCacheEndpoint(nodeGroup.PrimaryEndpoint),
+			ReaderEndpoint:  getElastiCacheEndpoint(nodeGroup.ReaderEndpoint),
+			Members:         members,
+		})
+	}
+	return result
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing/types"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	elbv2types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
 	infra_sdk "github.com/nullstone-io/infra-sdk"
@@ -78,7 +79,7 @@ func scanClassicLoadBalancers(ctx context.Context, config aws.Config) ([]infra_s
 
 		// For classic load balancers, we'll use 'elb' as the platform
 		// since they don't have a type field like v2 load balancers
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: name,
 			Name:     name,
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -89,17 +90,19 @@ func scanClassicLoadBalancers(ctx context.Context, config aws.Config) ([]infra_s
 			},
 			ServiceName:         "EC2",
 			ServiceResourceName: "Classic Load Balancer",
-			Attributes: map[string]any{
-				"dns_name":        dnsName,
-				"scheme":          scheme,
-				"vpc_id":          lb.VPCId,
-				"created_time":    lb.CreatedTime,
-				"security_groups": lb.SecurityGroups,
-				"listeners":       lb.ListenerDescriptions,
-				"instances":       lb.Instances,
-				"tags":            tags,
-			},
+		}.WithAttributes(&ClassicLoadBalancerAttributes{
+			DnsName:        dnsName,
+			Scheme:         scheme,
+			VpcId:          aws.ToString(lb.VPCId),
+			CreatedTime:    lb.CreatedTime,
+			SecurityGroups: lb.SecurityGroups,
+			Listeners:      getClassicListeners(lb.ListenerDescriptions),
+			Instances:      getClassicInstanceIds(lb.Instances),
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tags, awsTagToUniversal))
 	}
 
 	return resources, nil
@@ -168,7 +171,7 @@ func scanElbv2LoadBalancers(ctx context.Context, config aws.Config) ([]infra_sdk
 			subplatform = "gwlb"
 		}
 
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: *lb.LoadBalancerArn,
 			Name:     name,
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -179,20 +182,76 @@ func scanElbv2LoadBalancers(ctx context.Context, config aws.Config) ([]infra_sdk
 			},
 			ServiceName:         "EC2",
 			ServiceResourceName: "Load Balancer",
-			Attributes: map[string]any{
-				"dns_name":           lb.DNSName,
-				"scheme":             lb.Scheme,
-				"vpc_id":             lb.VpcId,
-				"created_time":       lb.CreatedTime,
-				"security_groups":    lb.SecurityGroups,
-				"ip_address_type":    lb.IpAddressType,
-				"listeners":          listeners,
-				"availability_zones": lb.AvailabilityZones,
-				"type":               lb.Type,
-				"tags":               tags,
-			},
+		}.WithAttributes(&LoadBalancerAttributes{
+			DnsName:           aws.ToString(lb.DNSName),
+			Scheme:            string(lb.Scheme),
+			VpcId:             aws.ToString(lb.VpcId),
+			CreatedTime:       lb.CreatedTime,
+			SecurityGroups:    lb.SecurityGroups,
+			IpAddressType:     string(lb.IpAddressType),
+			Listeners:         getListeners(listeners),
+			AvailabilityZones: getAvailabilityZones(lb.AvailabilityZones),
+			Type:              string(lb.Type),
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tags, awsTagToUniversal))
 	}
 
 	return resources, nil
 }
+
+func getClassicListeners(descriptions []elbtypes.ListenerDescription) []ClassicLoadBalancerListener {
+	result := make([]ClassicLoadBalancerListener, 0, len(descriptions))
+	for _, desc := range descriptions {
+		if desc.Listener == nil {
+			continue
+		}
+		result = append(result, ClassicLoadBalancerListener{
+			Protocol:         aws.ToString(desc.Listener.Protocol),
+			LoadBalancerPort: desc.Listener.LoadBalancerPort,
+			InstanceProtocol: aws.ToString(desc.Listener.InstanceProtocol),
+			InstancePort:     aws.ToInt32(desc.Listener.InstancePort),
+			SslCertificateId: aws.ToString(desc.Listener.SSLCertificateId),
+		})
+	}
+	return result
+}
+
+func getClassicInstanceIds(instances []elbtypes.Instance) []string {
+	result := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		result = append(result, aws.ToString(instance.InstanceId))
+	}
+	return result
+}
+
+func getListeners(listeners []elbv2types.Listener) []LoadBalancerListener {
+	result := make([]LoadBalancerListener, 0, len(listeners))
+	for _, listener := range listeners {
+		certificates := make([]string, 0, len(listener.Certificates))
+		for _, cert := range listener.Certificates {
+			certificates = append(certificates, aws.ToString(cert.CertificateArn))
+		}
+		result = append(result, LoadBalancerListener{
+			ListenerArn:  aws.ToString(listener.ListenerArn),
+			Protocol:     string(listener.Protocol),
+			Port:         aws.ToInt32(listener.Port),
+			SslPolicy:    aws.ToString(listener.SslPolicy),
+			Certificates: certificates,
+		})
+	}
+	return result
+}
+
+func getAvailabilityZones(zones []elbv2types.AvailabilityZone) []LoadBalancerAvailabilityZone {
+	result := make([]LoadBalancerAvailabilityZone, 0, len(zones))
+	for _, zone := range zones {
+		result = append(result, LoadBalancerAvailabilityZone{
+			ZoneName: aws.ToString(zone.ZoneName),
+			SubnetId: aws.ToString(zone.SubnetId),
+		})
+	}
+	return result
+}
@@ -133,7 +133,7 @@ func ScanMqBrokers(ctx context.Context, config aws.Config) ([]infra_sdk.ScanReso
 				authStrategy = string(descOutput.AuthenticationStrategy)
 			}
 
-			resources = append(resources, infra_sdk.ScanResource{
+			scanResource, err := infra_sdk.ScanResource{
 				UniqueId: *descOutput.BrokerArn,
 				Name:     aws.ToString(descOutput.BrokerName),
 				Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -144,46 +144,48 @@ func ScanMqBrokers(ctx context.Context, config aws.Config) ([]infra_sdk.ScanReso
 				},
 				ServiceName:         "AmazonMQ",
 				ServiceResourceName: "Broker",
-				Attributes: map[string]any{
-					"arn":                           descOutput.BrokerArn,
-					"broker_id":                     descOutput.BrokerId,
-					"broker_state":                  descOutput.BrokerState,
-					"engine_version":                descOutput.EngineVersion,
-					"instance_type":                 instanceType,
-					"deployment_mode":               deploymentMode,
-					"authentication_strategy":       authStrategy,
-					"publicly_accessible":           descOutput.PubliclyAccessible,
-					"auto_minor_version_upgrade":    descOutput.AutoMinorVersionUpgrade,
-					"endpoints":                     endpoints,
-					"created":                       descOutput.Created,
-					"maintenance_window_start_time": getMaintenanceWindow(descOutput.MaintenanceWindowStartTime),
-					"logs":                          getLogsConfig(descOutput.Logs),
-					"tags":                          tags,
-				},
+			}.WithAttributes(&MqBrokerAttributes{
+				Arn:                        aws.ToString(descOutput.BrokerArn),
+				BrokerId:                   aws.ToString(descOutput.BrokerId),
+				BrokerState:                string(descOutput.BrokerState),
+				EngineVersion:              aws.ToString(descOutput.EngineVersion),
+				InstanceType:               instanceType,
+				DeploymentMode:             deploymentMode,
+				AuthenticationStrategy:     authStrategy,
+				PubliclyAccessible:         aws.ToBool(descOutput.PubliclyAccessible),
+				AutoMinorVersionUpgrade:    aws.ToBool(descOutput.AutoMinorVersionUpgrade),
+				Endpoints:                  endpoints,
+				Created:                    descOutput.Created,
+				MaintenanceWindowStartTime: getMaintenanceWindow(descOutput.MaintenanceWindowStartTime),
+				Logs:                       getLogsConfig(descOutput.Logs),
 			})
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, scanResource.WithTags(tags, awsTagToUniversal))
 		}
 	}
 
 	return resources, nil
 }
 
-func getMaintenanceWindow(window *mqtypes.WeeklyStartTime) map[string]any {
+func getMaintenanceWindow(window *mqtypes.WeeklyStartTime) *MqMaintenanceWindow {
 	if window == nil {
 		return nil
 	}
-	return map[string]any{
-		"day_of_week": string(window.DayOfWeek),
-		"time_of_day": window.TimeOfDay,
-		"time_zone":   window.TimeZone,
+	return &MqMaintenanceWindow{
+		DayOfWeek: string(window.DayOfWeek),
+		TimeOfDay: aws.ToString(window.TimeOfDay),
+		TimeZone:  aws.ToString(window.TimeZone),
 	}
 }
 
-func getLogsConfig(logs *mqtypes.LogsSummary) map[string]any {
+func getLogsConfig(logs *mqtypes.LogsSummary) *MqLogs {
 	if logs == nil {
 		return nil
 	}
-	return map[string]any{
-		"audit":   logs.Audit,
-		"general": logs.General,
+	return &MqLogs{
+		Audit:   aws.ToBool(logs.Audit),
+		General: aws.ToBool(logs.General),
 	}
 }
@@ -83,7 +83,7 @@ func ScanMskClusters(ctx context.Context, config aws.Config) ([]infra_sdk.ScanRe
 			kafkaVersion = "Serverless"
 		}
 
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: *cluster.ClusterArn,
 			Name:     name,
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -94,17 +94,19 @@ func ScanMskClusters(ctx context.Context, config aws.Config) ([]infra_sdk.ScanRe
 			},
 			ServiceName:         "MSK",
 			ServiceResourceName: "Cluster",
-			Attributes: map[string]any{
-				"arn":                    cluster.ClusterArn,
-				"cluster_type":           string(cluster.ClusterType),
-				"state":                  cluster.State,
-				"kafka_version":          kafkaVersion,
-				"number_of_broker_nodes": brokerCount,
-				"zookeeper_connect":      zookeeperConnectString,
-				"creation_time":          cluster.CreationTime,
-				"tags":                   tagMap[aws.ToString(cluster.ClusterArn)],
-			},
+		}.WithAttributes(&MskClusterAttributes{
+			Arn:                 aws.ToString(cluster.ClusterArn),
+			ClusterType:         string(cluster.ClusterType),
+			State:               string(cluster.State),
+			KafkaVersion:        kafkaVersion,
+			NumberOfBrokerNodes: brokerCount,
+			ZookeeperConnect:    zookeeperConnectString,
+			CreationTime:        cluster.CreationTime,
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tagMap[aws.ToString(cluster.ClusterArn)], awsTagToUniversal))
 	}
 
 	return resources, nil
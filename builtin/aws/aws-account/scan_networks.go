@@ -33,7 +33,7 @@ func ScanNetworks(ctx context.Context, config aws.Config) ([]infra_sdk.ScanResou
 			name = *vpc.VpcId
 		}
 
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: *vpc.VpcId,
 			Name:     name,
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -44,14 +44,16 @@ func ScanNetworks(ctx context.Context, config aws.Config) ([]infra_sdk.ScanResou
 			},
 			ServiceName:         "VPC",
 			ServiceResourceName: "Network",
-			Attributes: map[string]any{
-				"cidr_block":       vpc.CidrBlock,
-				"is_default":       vpc.IsDefault,
-				"state":            vpc.State,
-				"instance_tenancy": vpc.InstanceTenancy,
-				"tags":             tags,
-			},
+		}.WithAttributes(&VpcAttributes{
+			CidrBlock:       aws.ToString(vpc.CidrBlock),
+			IsDefault:       aws.ToBool(vpc.IsDefault),
+			State:           string(vpc.State),
+			InstanceTenancy: string(vpc.InstanceTenancy),
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tags, awsTagToUniversal))
 	}
 	return resources, nil
 }
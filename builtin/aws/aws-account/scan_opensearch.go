@@ -117,7 +117,7 @@ func ScanOpenSearchDomains(ctx context.Context, config aws.Config) ([]infra_sdk.
 				nodeToNodeEncryption = domain.NodeToNodeEncryptionOptions.Enabled != nil && *domain.NodeToNodeEncryptionOptions.Enabled
 			}
 
-			resources = append(resources, infra_sdk.ScanResource{
+			scanResource, err := infra_sdk.ScanResource{
 				UniqueId: *domain.ARN,
 				Name:     name,
 				Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -128,37 +128,39 @@ func ScanOpenSearchDomains(ctx context.Context, config aws.Config) ([]infra_sdk.
 				},
 				ServiceName:         "OpenSearch",
 				ServiceResourceName: "Domain",
-				Attributes: map[string]any{
-					"arn":                       domain.ARN,
-					"endpoint":                  endpoint,
-					"version":                   version,
-					"instance_type":             instanceType,
-					"instance_count":            instanceCount,
-					"encryption_at_rest":        encryptionAtRest,
-					"node_to_node_encryption":   nodeToNodeEncryption,
-					"created":                   domain.Created,
-					"deleted":                   domain.Deleted,
-					"processing":                domain.Processing,
-					"upgrade_processing":        domain.UpgradeProcessing,
-					"access_policies":           domain.AccessPolicies,
-					"advanced_options":          domain.AdvancedOptions,
-					"advanced_security_options": getAdvancedSecurityOptions(domain.AdvancedSecurityOptions),
-					"tags":                      tagMap[aws.ToString(domain.ARN)],
-				},
+			}.WithAttributes(&OpenSearchDomainAttributes{
+				Arn:                     aws.ToString(domain.ARN),
+				Endpoint:                endpoint,
+				Version:                 version,
+				InstanceType:            instanceType,
+				InstanceCount:           instanceCount,
+				EncryptionAtRest:        encryptionAtRest,
+				NodeToNodeEncryption:    nodeToNodeEncryption,
+				Created:                 aws.ToBool(domain.Created),
+				Deleted:                 aws.ToBool(domain.Deleted),
+				Processing:              aws.ToBool(domain.Processing),
+				UpgradeProcessing:       aws.ToBool(domain.UpgradeProcessing),
+				AccessPolicies:          aws.ToString(domain.AccessPolicies),
+				AdvancedOptions:         domain.AdvancedOptions,
+				AdvancedSecurityOptions: getAdvancedSecurityOptions(domain.AdvancedSecurityOptions),
 			})
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, scanResource.WithTags(tagMap[aws.ToString(domain.ARN)], awsTagToUniversal))
 		}
 	}
 
 	return resources, nil
 }
 
-func getAdvancedSecurityOptions(options *opensearchtypes.AdvancedSecurityOptions) map[string]any {
+func getAdvancedSecurityOptions(options *opensearchtypes.AdvancedSecurityOptions) *OpenSearchAdvancedSecurityOptions {
 	if options == nil {
 		return nil
 	}
-	return map[string]any{
-		"enabled":                        options.Enabled != nil && *options.Enabled,
-		"internal_user_database_enabled": options.InternalUserDatabaseEnabled != nil && *options.InternalUserDatabaseEnabled,
-		"saml_enabled":                   options.SAMLOptions != nil && options.SAMLOptions.Enabled != nil && *options.SAMLOptions.Enabled,
+	return &OpenSearchAdvancedSecurityOptions{
+		Enabled:                     aws.ToBool(options.Enabled),
+		InternalUserDatabaseEnabled: aws.ToBool(options.InternalUserDatabaseEnabled),
+		SamlEnabled:                 options.SAMLOptions != nil && aws.ToBool(options.SAMLOptions.Enabled),
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdstypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
 	infra_sdk "github.com/nullstone-io/infra-sdk"
 	"gopkg.in/nullstone-io/go-api-client.v0/types"
 )
@@ -62,7 +63,7 @@ func scanRdsInstances(ctx context.Context, client *rds.Client, resources *[]infr
 				name = &nameTag
 			}
 
-			*resources = append(*resources, infra_sdk.ScanResource{
+			scanResource, err := infra_sdk.ScanResource{
 				UniqueId: *instance.DBInstanceArn,
 				Name:     *name,
 				Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -74,19 +75,21 @@ func scanRdsInstances(ctx context.Context, client *rds.Client, resources *[]infr
 				},
 				ServiceName:         "RDS",
 				ServiceResourceName: "Instance",
-				Attributes: map[string]any{
-					"identifier":        instance.DBInstanceIdentifier,
-					"engine":            instance.Engine,
-					"engine_version":    instance.EngineVersion,
-					"instance_class":    instance.DBInstanceClass,
-					"storage_type":      instance.StorageType,
-					"allocated_storage": instance.AllocatedStorage,
-					"multi_az":          instance.MultiAZ,
-					"status":            instance.DBInstanceStatus,
-					"endpoint":          instance.Endpoint,
-					"tags":              tags,
-				},
+			}.WithAttributes(&RdsInstanceAttributes{
+				Identifier:       aws.ToString(instance.DBInstanceIdentifier),
+				Engine:           aws.ToString(instance.Engine),
+				EngineVersion:    aws.ToString(instance.EngineVersion),
+				InstanceClass:    aws.ToString(instance.DBInstanceClass),
+				StorageType:      aws.ToString(instance.StorageType),
+				AllocatedStorage: aws.ToInt32(instance.AllocatedStorage),
+				MultiAz:          aws.ToBool(instance.MultiAZ),
+				Status:           aws.ToString(instance.DBInstanceStatus),
+				Endpoint:         getRdsEndpoint(instance.Endpoint),
 			})
+			if err != nil {
+				return err
+			}
+			*resources = append(*resources, scanResource.WithTags(tags, awsTagToUniversal))
 		}
 	}
 
@@ -141,7 +144,7 @@ func scanRdsClusters(ctx context.Context, client *rds.Client, resources *[]infra
 				readerEndpoints = append(readerEndpoints, *cluster.ReaderEndpoint)
 			}
 
-			*resources = append(*resources, infra_sdk.ScanResource{
+			scanResource, err := infra_sdk.ScanResource{
 				UniqueId: *cluster.DBClusterArn,
 				Name:     *name,
 				Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -153,23 +156,25 @@ func scanRdsClusters(ctx context.Context, client *rds.Client, resources *[]infra
 				},
 				ServiceName:         "RDS",
 				ServiceResourceName: "Aurora Cluster",
-				Attributes: map[string]any{
-					"identifier":          cluster.DBClusterIdentifier,
-					"engine":              cluster.Engine,
-					"engine_version":      cluster.EngineVersion,
-					"engine_mode":         cluster.EngineMode,
-					"status":              cluster.Status,
-					"writer_endpoint":     writerEndpoint,
-					"reader_endpoints":    readerEndpoints,
-					"multi_az":            cluster.MultiAZ,
-					"storage_encrypted":   cluster.StorageEncrypted,
-					"database_name":       cluster.DatabaseName,
-					"backup_retention":    cluster.BackupRetentionPeriod,
-					"cluster_members":     cluster.DBClusterMembers,
-					"vpc_security_groups": cluster.VpcSecurityGroups,
-					"tags":                tags,
-				},
+			}.WithAttributes(&RdsClusterAttributes{
+				Identifier:        aws.ToString(cluster.DBClusterIdentifier),
+				Engine:            aws.ToString(cluster.Engine),
+				EngineVersion:     aws.ToString(cluster.EngineVersion),
+				EngineMode:        aws.ToString(cluster.EngineMode),
+				Status:            aws.ToString(cluster.Status),
+				WriterEndpoint:    writerEndpoint,
+				ReaderEndpoints:   readerEndpoints,
+				MultiAz:           aws.ToBool(cluster.MultiAZ),
+				StorageEncrypted:  aws.ToBool(cluster.StorageEncrypted),
+				DatabaseName:      aws.ToString(cluster.DatabaseName),
+				BackupRetention:   aws.ToInt32(cluster.BackupRetentionPeriod),
+				ClusterMembers:    getRdsClusterMembers(cluster.DBClusterMembers),
+				VpcSecurityGroups: getRdsVpcSecurityGroups(cluster.VpcSecurityGroups),
 			})
+			if err != nil {
+				return err
+			}
+			*resources = append(*resources, scanResource.WithTags(tags, awsTagToUniversal))
 		}
 	}
 
@@ -195,3 +200,37 @@ func getRdsDatabaseType(engine, version *string) (string, string) {
 		return "", ""
 	}
 }
+
+func getRdsEndpoint(endpoint *rdstypes.Endpoint) *RdsEndpoint {
+	if endpoint == nil {
+		return nil
+	}
+	return &RdsEndpoint{
+		Address:      aws.ToString(endpoint.Address),
+		Port:         aws.ToInt32(endpoint.Port),
+		HostedZoneId: aws.ToString(endpoint.HostedZoneId),
+	}
+}
+
+func getRdsClusterMembers(members []rdstypes.DBClusterMember) []RdsClusterMember {
+	result := make([]RdsClusterMember, 0, len(members))
+	for _, member := range members {
+		result = append(result, RdsClusterMember{
+			DbInstanceIdentifier: aws.ToString(member.DBInstanceIdentifier),
+			IsClusterWriter:      aws.ToBool(member.IsClusterWriter),
+			PromotionTier:        aws.ToInt32(member.PromotionTier),
+		})
+	}
+	return result
+}
+
+func getRdsVpcSecurityGroups(groups []rdstypes.VpcSecurityGroupMembership) []RdsVpcSecurityGroup {
+	result := make([]RdsVpcSecurityGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, RdsVpcSecurityGroup{
+			VpcSecurityGroupId: aws.ToString(group.VpcSecurityGroupId),
+			Status:             aws.ToString(group.Status),
+		})
+	}
+	return result
+}
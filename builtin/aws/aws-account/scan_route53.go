@@ -45,7 +45,7 @@ func ScanRoute53(ctx context.Context, config aws.Config) ([]infra_sdk.ScanResour
 			privateZone = zone.Config.PrivateZone
 		}
 
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: aws.ToString(zone.Id),
 			Name:     aws.ToString(zone.Name),
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -55,15 +55,18 @@ func ScanRoute53(ctx context.Context, config aws.Config) ([]infra_sdk.ScanResour
 			},
 			ServiceName:         "Route53",
 			ServiceResourceName: "Hosted Zone",
-			Attributes: map[string]interface{}{
-				"name":                      strings.TrimSuffix(aws.ToString(zone.Name), "."),
-				"zone_id":                   aws.ToString(zone.Id),
-				"comment":                   comment,
-				"private_zone":              privateZone,
-				"resource_record_set_count": aws.ToInt64(zone.ResourceRecordSetCount),
-				"tags":                      tags,
-			},
+			Region:              GlobalRegion,
+		}.WithAttributes(&HostedZoneAttributes{
+			Name:                   strings.TrimSuffix(aws.ToString(zone.Name), "."),
+			ZoneId:                 aws.ToString(zone.Id),
+			Comment:                comment,
+			PrivateZone:            privateZone,
+			ResourceRecordSetCount: aws.ToInt64(zone.ResourceRecordSetCount),
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tags, awsTagToUniversal))
 	}
 
 	return resources, nil
@@ -56,6 +56,19 @@ func ScanS3Buckets(ctx context.Context, config aws.Config) ([]infra_sdk.ScanReso
 
 	var resources []infra_sdk.ScanResource
 
+	// Bucket configuration must be requested from the bucket's region, so we keep a client for each region
+	regionClients := map[string]*s3.Client{config.Region: client}
+	regionClient := func(region string) *s3.Client {
+		if cur, ok := regionClients[region]; ok {
+			return cur
+		}
+		cur := s3.NewFromConfig(config, func(o *s3.Options) {
+			o.Region = region
+		})
+		regionClients[region] = cur
+		return cur
+	}
+
 	// Process each bucket to get its details
 	for _, bucket := range buckets.Buckets {
 		bucketName := *bucket.Name
@@ -68,18 +81,20 @@ func ScanS3Buckets(ctx context.Context, config aws.Config) ([]infra_sdk.ScanReso
 		if err != nil {
 			continue
 		}
+		region := getBucketRegion(locationOutput.LocationConstraint)
+		bucketClient := regionClient(region)
 
 		// Get CORS configuration
-		corsOutput, _ := client.GetBucketCors(ctx, &s3.GetBucketCorsInput{
+		corsOutput, _ := bucketClient.GetBucketCors(ctx, &s3.GetBucketCorsInput{
 			Bucket: &bucketName,
 		})
 
 		// Get website configuration
-		websiteOutput, _ := client.GetBucketWebsite(ctx, &s3.GetBucketWebsiteInput{
+		websiteOutput, _ := bucketClient.GetBucketWebsite(ctx, &s3.GetBucketWebsiteInput{
 			Bucket: &bucketName,
 		})
 
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: arn,
 			Name:     bucketName,
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -90,15 +105,18 @@ func ScanS3Buckets(ctx context.Context, config aws.Config) ([]infra_sdk.ScanReso
 			},
 			ServiceName:         "S3",
 			ServiceResourceName: "Bucket",
-			Attributes: map[string]any{
-				"arn":           arn,
-				"creation_date": bucket.CreationDate,
-				"region":        getBucketRegion(locationOutput.LocationConstraint),
-				"cors":          getCorsDetails(corsOutput),
-				"website":       getWebsiteDetails(websiteOutput),
-				"tags":          tagMap[arn],
-			},
+			Region:              region,
+		}.WithAttributes(&S3BucketAttributes{
+			Arn:          arn,
+			CreationDate: bucket.CreationDate,
+			Region:       region,
+			Cors:         getCorsDetails(corsOutput),
+			Website:      getWebsiteDetails(websiteOutput),
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tagMap[arn], awsTagToUniversal))
 	}
 
 	return resources, nil
@@ -113,33 +131,53 @@ func getBucketRegion(constraint s3types.BucketLocationConstraint) string {
 	return string(constraint)
 }
 
-func getCorsDetails(output *s3.GetBucketCorsOutput) map[string]any {
+func getCorsDetails(output *s3.GetBucketCorsOutput) *S3BucketCors {
 	if output == nil || len(output.CORSRules) == 0 {
 		return nil
 	}
-	rules := make([]map[string]any, len(output.CORSRules))
+	rules := make([]S3BucketCorsRule, len(output.CORSRules))
 	for i, rule := range output.CORSRules {
-		rules[i] = map[string]any{
-			"allowed_headers": rule.AllowedHeaders,
-			"allowed_methods": rule.AllowedMethods,
-			"allowed_origins": rule.AllowedOrigins,
-			"expose_headers":  rule.ExposeHeaders,
-			"max_age_seconds": rule.MaxAgeSeconds,
+		rules[i] = S3BucketCorsRule{
+			AllowedHeaders: rule.AllowedHeaders,
+			AllowedMethods: rule.AllowedMethods,
+			AllowedOrigins: rule.AllowedOrigins,
+			ExposeHeaders:  rule.ExposeHeaders,
+			MaxAgeSeconds:  rule.MaxAgeSeconds,
 		}
 	}
-	return map[string]any{
-		"cors_rules": rules,
-	}
+	return &S3BucketCors{CorsRules: rules}
 }
 
-func getWebsiteDetails(output *s3.GetBucketWebsiteOutput) map[string]any {
+func getWebsiteDetails(output *s3.GetBucketWebsiteOutput) *S3BucketWebsite {
 	if output == nil {
 		return nil
 	}
-	return map[string]any{
-		"index_document":           output.IndexDocument,
-		"error_document":           output.ErrorDocument,
-		"redirect_all_requests_to": output.RedirectAllRequestsTo,
-		"routing_rules":            output.RoutingRules,
+	result := &S3BucketWebsite{
+		RoutingRules: make([]S3BucketWebsiteRoutingRule, 0, len(output.RoutingRules)),
+	}
+	if output.IndexDocument != nil {
+		result.IndexDocument = aws.ToString(output.IndexDocument.Suffix)
+	}
+	if output.ErrorDocument != nil {
+		result.ErrorDocument = aws.ToString(output.ErrorDocument.Key)
+	}
+	if output.RedirectAllRequestsTo != nil {
+		result.RedirectAllRequestsTo = aws.ToString(output.RedirectAllRequestsTo.HostName)
+	}
+	for _, rule := range output.RoutingRules {
+		cur := S3BucketWebsiteRoutingRule{}
+		if rule.Condition != nil {
+			cur.KeyPrefixEquals = aws.ToString(rule.Condition.KeyPrefixEquals)
+			cur.HttpErrorCodeReturnedEquals = aws.ToString(rule.Condition.HttpErrorCodeReturnedEquals)
+		}
+		if rule.Redirect != nil {
+			cur.HostName = aws.ToString(rule.Redirect.HostName)
+			cur.HttpRedirectCode = aws.ToString(rule.Redirect.HttpRedirectCode)
+			cur.Protocol = string(rule.Redirect.Protocol)
+			cur.ReplaceKeyPrefixWith = aws.ToString(rule.Redirect.ReplaceKeyPrefixWith)
+			cur.ReplaceKeyWith = aws.ToString(rule.Redirect.ReplaceKeyWith)
+		}
+		result.RoutingRules = append(result.RoutingRules, cur)
 	}
+	return result
 }
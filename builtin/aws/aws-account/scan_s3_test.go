@@ -0,0 +1,87 @@
+package aws_account
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signingRegion extracts the region from the credential scope of a SigV4 Authorization header
+var signingRegion = regexp.MustCompile(`Credential=[^/]+/[^/]+/([^/]+)/`)
+
+func TestScanS3Buckets(t *testing.T) {
+	var mu sync.Mutex
+	regions := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("X-Amz-Target"), "ResourceGroupsTaggingAPI") {
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			fmt.Fprint(w, `{"ResourceTagMappingList":[{"ResourceARN":"arn:aws:s3:::site","Tags":[{"Key":"Stack","Value":"core"}]}]}`)
+			return
+		}
+
+		bucket := strings.Trim(r.URL.Path, "/")
+		query := r.URL.Query()
+		var operation string
+		for _, cur := range []string{"location", "cors", "website"} {
+			if query.Has(cur) {
+				operation = cur
+			}
+		}
+		if match := signingRegion.FindStringSubmatch(r.Header.Get("Authorization")); match != nil {
+			mu.Lock()
+			regions[bucket+"?"+operation] = match[1]
+			mu.Unlock()
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		switch {
+		case bucket == "":
+			fmt.Fprint(w, `<ListAllMyBucketsResult><Buckets>
+				<Bucket><Name>site</Name><CreationDate>2025-01-01T00:00:00Z</CreationDate></Bucket>
+				<Bucket><Name>logs</Name><CreationDate>2025-01-01T00:00:00Z</CreationDate></Bucket>
+			</Buckets></ListAllMyBucketsResult>`)
+		case operation == "location" && bucket == "site":
+			fmt.Fprint(w, `<LocationConstraint>eu-west-1</LocationConstraint>`)
+		case operation == "location":
+			fmt.Fprint(w, `<LocationConstraint></LocationConstraint>`)
+		case operation == "website" && bucket == "site":
+			fmt.Fprint(w, `<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument></WebsiteConfiguration>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchConfiguration</Code></Error>`)
+		}
+	}))
+	defer ts.Close()
+
+	config, _ := testAccessor{endpoint: ts.URL}.NewConfig(globalServicesRegion)
+	resources, err := ScanS3Buckets(context.Background(), *config)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	// Bucket locations are requested from the global services region, bucket configuration from the bucket's region
+	assert.Equal(t, map[string]string{
+		"?":             globalServicesRegion,
+		"site?location": globalServicesRegion,
+		"site?cors":     "eu-west-1",
+		"site?website":  "eu-west-1",
+		"logs?location": globalServicesRegion,
+		"logs?cors":     "us-east-1",
+		"logs?website":  "us-east-1",
+	}, regions)
+
+	site := resources[0]
+	assert.Equal(t, "site", site.Name)
+	assert.Equal(t, "eu-west-1", site.Region)
+	assert.Equal(t, "core", site.Ownership.Stack)
+	require.Contains(t, site.Attributes, "website")
+	assert.Equal(t, "index.html", site.Attributes["website"].(map[string]any)["index_document"])
+	assert.Nil(t, site.Attributes["cors"])
+}
@@ -90,26 +90,17 @@ func ScanSnsTopics(ctx context.Context, config aws.Config) ([]infra_sdk.ScanReso
 			continue
 		}
 
-		// Get topic policy
-		var policy map[string]any
-		if attrs.Attributes["Policy"] != "" {
-			policy = map[string]any{
-				"policy": attrs.Attributes["Policy"],
-			}
-		}
-
 		// Get delivery status logging
-		deliveryStatusLogging := make([]map[string]any, 0)
+		deliveryStatusLogging := make([]SnsDeliveryStatusLogging, 0)
 		if attrs.Attributes["ApplicationSuccessFeedbackRoleArn"] != "" || attrs.Attributes["HTTPSuccessFeedbackRoleArn"] != "" {
-			logEntry := map[string]any{
-				"application_success_feedback_role_arn":    attrs.Attributes["ApplicationSuccessFeedbackRoleArn"],
-				"application_success_feedback_sample_rate": attrs.Attributes["ApplicationSuccessFeedbackSampleRate"],
-				"http_success_feedback_role_arn":           attrs.Attributes["HTTPSuccessFeedbackRoleArn"],
-				"http_success_feedback_sample_rate":        attrs.Attributes["HTTPSuccessFeedbackSampleRate"],
-				"lambda_success_feedback_role_arn":         attrs.Attributes["LambdaSuccessFeedbackRoleArn"],
-				"lambda_success_feedback_sample_rate":      attrs.Attributes["LambdaSuccessFeedbackSampleRate"],
-			}
-			deliveryStatusLogging = append(deliveryStatusLogging, logEntry)
+			deliveryStatusLogging = append(deliveryStatusLogging, SnsDeliveryStatusLogging{
+				ApplicationSuccessFeedbackRoleArn:    attrs.Attributes["ApplicationSuccessFeedbackRoleArn"],
+				ApplicationSuccessFeedbackSampleRate: attrs.Attributes["ApplicationSuccessFeedbackSampleRate"],
+				HttpSuccessFeedbackRoleArn:           attrs.Attributes["HTTPSuccessFeedbackRoleArn"],
+				HttpSuccessFeedbackSampleRate:        attrs.Attributes["HTTPSuccessFeedbackSampleRate"],
+				LambdaSuccessFeedbackRoleArn:         attrs.Attributes["LambdaSuccessFeedbackRoleArn"],
+				LambdaSuccessFeedbackSampleRate:      attrs.Attributes["LambdaSuccessFeedbackSampleRate"],
+			})
 		}
 
 		// Get topic display name
@@ -121,7 +112,7 @@ func ScanSnsTopics(ctx context.Context, config aws.Config) ([]infra_sdk.ScanReso
 			displayName = *topic.TopicArn
 		}
 
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: *topic.TopicArn,
 			Name:     displayName,
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -132,28 +123,30 @@ func ScanSnsTopics(ctx context.Context, config aws.Config) ([]infra_sdk.ScanReso
 			},
 			ServiceName:         "SNS",
 			ServiceResourceName: "Topic",
-			Attributes: map[string]any{
-				"arn":                         topic.TopicArn,
-				"owner":                       attrs.Attributes["Owner"],
-				"subscriptions_confirmed":     attrs.Attributes["SubscriptionsConfirmed"],
-				"subscriptions_deleted":       attrs.Attributes["SubscriptionsDeleted"],
-				"subscriptions_pending":       attrs.Attributes["SubscriptionsPending"],
-				"kms_master_key_id":           attrs.Attributes["KmsMasterKeyId"],
-				"fifo_topic":                  attrs.Attributes["FifoTopic"] == "true",
-				"content_based_deduplication": attrs.Attributes["ContentBasedDeduplication"] == "true",
-				"policy":                      policy,
-				"delivery_status_logging":     deliveryStatusLogging,
-				"subscriptions":               subscriptions,
-				"tags":                        tagMap[aws.ToString(topic.TopicArn)],
-			},
+		}.WithAttributes(&SnsTopicAttributes{
+			Arn:                       aws.ToString(topic.TopicArn),
+			Owner:                     attrs.Attributes["Owner"],
+			SubscriptionsConfirmed:    parseInt(attrs.Attributes["SubscriptionsConfirmed"]),
+			SubscriptionsDeleted:      parseInt(attrs.Attributes["SubscriptionsDeleted"]),
+			SubscriptionsPending:      parseInt(attrs.Attributes["SubscriptionsPending"]),
+			KmsMasterKeyId:            attrs.Attributes["KmsMasterKeyId"],
+			FifoTopic:                 attrs.Attributes["FifoTopic"] == "true",
+			ContentBasedDeduplication: attrs.Attributes["ContentBasedDeduplication"] == "true",
+			Policy:                    attrs.Attributes["Policy"],
+			DeliveryStatusLogging:     deliveryStatusLogging,
+			Subscriptions:             subscriptions,
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tagMap[aws.ToString(topic.TopicArn)], awsTagToUniversal))
 	}
 
 	return resources, nil
 }
 
-func listSubscriptionsByTopic(ctx context.Context, client *sns.Client, topicArn string) ([]map[string]any, error) {
-	var subscriptions []map[string]any
+func listSubscriptionsByTopic(ctx context.Context, client *sns.Client, topicArn string) ([]SnsSubscription, error) {
+	subscriptions := make([]SnsSubscription, 0)
 	var nextToken *string
 
 	for {
@@ -166,12 +159,12 @@ func listSubscriptionsByTopic(ctx context.Context, client *sns.Client, topicArn
 		}
 
 		for _, sub := range output.Subscriptions {
-			subscriptions = append(subscriptions, map[string]any{
-				"subscription_arn": sub.SubscriptionArn,
-				"protocol":         sub.Protocol,
-				"endpoint":         sub.Endpoint,
-				"owner":            sub.Owner,
-				"topic_arn":        sub.TopicArn,
+			subscriptions = append(subscriptions, SnsSubscription{
+				SubscriptionArn: aws.ToString(sub.SubscriptionArn),
+				Protocol:        aws.ToString(sub.Protocol),
+				Endpoint:        aws.ToString(sub.Endpoint),
+				Owner:           aws.ToString(sub.Owner),
+				TopicArn:        aws.ToString(sub.TopicArn),
 			})
 		}
 
@@ -93,7 +93,7 @@ func ScanSqsQueues(ctx context.Context, config aws.Config) ([]infra_sdk.ScanReso
 			approximateNumberOfMessagesDelayed = v
 		}
 
-		resources = append(resources, infra_sdk.ScanResource{
+		scanResource, err := infra_sdk.ScanResource{
 			UniqueId: queueUrl,
 			Name:     queueName,
 			Taxonomy: infra_sdk.ResourceTaxonomy{
@@ -104,21 +104,23 @@ func ScanSqsQueues(ctx context.Context, config aws.Config) ([]infra_sdk.ScanReso
 			},
 			ServiceName:         "SQS",
 			ServiceResourceName: "Queue",
-			Attributes: map[string]any{
-				"url":                            queueUrl,
-				"arn":                            attrOutput.Attributes[string(sqstypes.QueueAttributeNameQueueArn)],
-				"fifo_queue":                     isFifo,
-				"visibility_timeout":             visibilityTimeout,
-				"message_retention_period":       messageRetentionPeriod,
-				"redrive_policy":                 redrivePolicy,
-				"approximate_number_of_messages": approximateNumberOfMessages,
-				"approximate_number_of_messages_not_visible": approximateNumberOfMessagesNotVisible,
-				"approximate_number_of_messages_delayed":     approximateNumberOfMessagesDelayed,
-				"created_timestamp":                          attrOutput.Attributes[string(sqstypes.QueueAttributeNameCreatedTimestamp)],
-				"last_modified_timestamp":                    attrOutput.Attributes[string(sqstypes.QueueAttributeNameLastModifiedTimestamp)],
-				"tags":                                       tags,
-			},
+		}.WithAttributes(&SqsQueueAttributes{
+			Url:                                   queueUrl,
+			Arn:                                   attrOutput.Attributes[string(sqstypes.QueueAttributeNameQueueArn)],
+			FifoQueue:                             isFifo,
+			VisibilityTimeout:                     parseInt(visibilityTimeout),
+			MessageRetentionPeriod:                parseInt(messageRetentionPeriod),
+			RedrivePolicy:                         redrivePolicy,
+			ApproximateNumberOfMessages:           parseInt(approximateNumberOfMessages),
+			ApproximateNumberOfMessagesNotVisible: parseInt(approximateNumberOfMessagesNotVisible),
+			ApproximateNumberOfMessagesDelayed:    parseInt(approximateNumberOfMessagesDelayed),
+			CreatedTimestamp:                      parseInt(attrOutput.Attributes[string(sqstypes.QueueAttributeNameCreatedTimestamp)]),
+			LastModifiedTimestamp:                 parseInt(attrOutput.Attributes[string(sqstypes.QueueAttributeNameLastModifiedTimestamp)]),
 		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scanResource.WithTags(tags, awsTagToUniversal))
 	}
 
 	return resources, nil
@@ -4,15 +4,40 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/nullstone-io/infra-sdk"
 )
 
+const (
+	// GlobalRegion is stamped on resources that belong to global services (e.g. Route53, CloudFront)
+	GlobalRegion = "global"
+
+	// globalServicesRegion is the region used to configure clients for global services
+	globalServicesRegion = "us-east-1"
+
+	// defaultMaxConcurrentScans is the number of scanners that run at once if Scanner.MaxConcurrentScans is not set
+	defaultMaxConcurrentScans = 10
+)
+
 var (
-	AllScanners = []ResourceScanner{
+	// GlobalScanners scan services that are not bound to a region
+	// These are scanned exactly once regardless of how many regions are scanned
+	GlobalScanners = []ResourceScanner{
 		// domain/subdomain
 		ScanRoute53,
 
+		// ingress
+		ScanCdns,
+
+		// datastore
+		ScanS3Buckets,
+	}
+
+	// RegionalScanners scan services that are bound to a region
+	// These are scanned once per region
+	RegionalScanners = []ResourceScanner{
 		// network
 		ScanNetworks,
 
@@ -22,10 +47,8 @@ var (
 		// ingress
 		ScanLoadBalancers,
 		ScanApiGateways,
-		ScanCdns,
 
 		// datastore
-		ScanS3Buckets,
 		ScanEfsFileSystems,
 		ScanRdsDatabases,
 		ScanElastiCacheClusters,
@@ -35,17 +58,30 @@ var (
 		ScanSnsTopics,
 		ScanOpenSearchDomains,
 	}
+
+	AllScanners = slices.Concat(GlobalScanners, RegionalScanners)
 )
 
 type Scanner struct {
 	Accessor infra_sdk.AwsAccessor
+	// Regions is the list of regions to scan for regional services
+	// If empty, every region enabled for the account is scanned
+	Regions []string
+	// GlobalScanners overrides the package GlobalScanners if not nil
+	GlobalScanners []ResourceScanner
+	// RegionalScanners overrides the package RegionalScanners if not nil
+	RegionalScanners []ResourceScanner
+	// MaxConcurrentScans is the number of scanners that run at once
+	// Regional scanners run once per region, so this keeps large accounts from being throttled
+	// If 0, defaultMaxConcurrentScans is used
+	MaxConcurrentScans int
 }
 
 func (s Scanner) Scan(ctx context.Context) ([]infra_sdk.ScanResource, error) {
 	if s.Accessor == nil {
 		return nil, nil
 	}
-	awsConfig, err := s.Accessor.NewConfig("")
+	awsConfig, err := s.Accessor.NewConfig(globalServicesRegion)
 	if err != nil {
 		return nil, fmt.Errorf("error resolving aws config: %w", err)
 	}
@@ -53,9 +89,45 @@ func (s Scanner) Scan(ctx context.Context) ([]infra_sdk.ScanResource, error) {
 		return nil, nil
 	}
 
-	tracker := NewResourceScanTracker()
-	for _, scanner := range AllScanners {
-		tracker.Scan(ctx, *awsConfig, scanner)
+	regions := s.Regions
+	if len(regions) == 0 {
+		if regions, err = ListEnabledRegions(ctx, *awsConfig); err != nil {
+			return nil, fmt.Errorf("error listing enabled regions: %w", err)
+		}
+	}
+
+	// Resolve every regional config before scanning so that we fail fast on bad credentials/regions
+	regionConfigs := make([]aws.Config, 0, len(regions))
+	for _, region := range regions {
+		regionConfig, err := s.Accessor.NewConfig(region)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving aws config for region %q: %w", region, err)
+		}
+		if regionConfig != nil {
+			regionConfigs = append(regionConfigs, *regionConfig)
+		}
+	}
+
+	globalScanners, regionalScanners := GlobalScanners, RegionalScanners
+	if s.GlobalScanners != nil {
+		globalScanners = s.GlobalScanners
+	}
+	if s.RegionalScanners != nil {
+		regionalScanners = s.RegionalScanners
+	}
+
+	maxConcurrentScans := s.MaxConcurrentScans
+	if maxConcurrentScans <= 0 {
+		maxConcurrentScans = defaultMaxConcurrentScans
+	}
+	tracker := NewLimitedResourceScanTracker(maxConcurrentScans)
+	for _, scanner := range globalScanners {
+		tracker.ScanGlobal(ctx, *awsConfig, scanner)
+	}
+	for _, regionConfig := range regionConfigs {
+		for _, scanner := range regionalScanners {
+			tracker.Scan(ctx, regionConfig, scanner)
+		}
 	}
 	tracker.Wait()
 	if len(tracker.Errors) > 0 {
@@ -0,0 +1,146 @@
+package aws_account
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scannerPointer(rs ResourceScanner) uintptr {
+	return reflect.ValueOf(rs).Pointer()
+}
+
+func TestScannerSplit(t *testing.T) {
+	global := map[uintptr]bool{}
+	for _, rs := range GlobalScanners {
+		global[scannerPointer(rs)] = true
+	}
+	assert.Equal(t, map[uintptr]bool{
+		scannerPointer(ScanRoute53):   true,
+		scannerPointer(ScanCdns):      true,
+		scannerPointer(ScanS3Buckets): true,
+	}, global)
+
+	for _, rs := range RegionalScanners {
+		assert.False(t, global[scannerPointer(rs)], "scanner is both global and regional")
+	}
+	assert.Len(t, AllScanners, len(GlobalScanners)+len(RegionalScanners))
+}
+
+func TestResourceScanTracker(t *testing.T) {
+	ctx := context.Background()
+	config := aws.Config{Region: "us-west-2"}
+	resources := func(ctx context.Context, config aws.Config) ([]infra_sdk.ScanResource, error) {
+		return []infra_sdk.ScanResource{
+			{UniqueId: "unstamped"},
+			{UniqueId: "stamped", Region: "eu-west-1"},
+		}, nil
+	}
+	failing := func(ctx context.Context, config aws.Config) ([]infra_sdk.ScanResource, error) {
+		return nil, assert.AnError
+	}
+
+	t.Run("regional", func(t *testing.T) {
+		tracker := NewResourceScanTracker()
+		tracker.Scan(ctx, config, resources)
+		tracker.Scan(ctx, config, failing)
+		tracker.Wait()
+
+		regions := map[string]string{}
+		for _, resource := range tracker.Resources {
+			regions[resource.UniqueId] = resource.Region
+		}
+		assert.Equal(t, map[string]string{"unstamped": "us-west-2", "stamped": "eu-west-1"}, regions)
+		require.Len(t, tracker.Errors, 1)
+		assert.ErrorIs(t, tracker.Errors[0], assert.AnError)
+		assert.ErrorContains(t, tracker.Errors[0], `error scanning region "us-west-2"`)
+	})
+
+	t.Run("global", func(t *testing.T) {
+		tracker := NewResourceScanTracker()
+		tracker.ScanGlobal(ctx, config, resources)
+		tracker.ScanGlobal(ctx, config, failing)
+		tracker.Wait()
+
+		regions := map[string]string{}
+		for _, resource := range tracker.Resources {
+			regions[resource.UniqueId] = resource.Region
+		}
+		assert.Equal(t, map[string]string{"unstamped": GlobalRegion, "stamped": "eu-west-1"}, regions)
+		require.Len(t, tracker.Errors, 1)
+		assert.ErrorIs(t, tracker.Errors[0], assert.AnError)
+		assert.ErrorContains(t, tracker.Errors[0], "error scanning global services")
+		assert.NotContains(t, tracker.Errors[0].Error(), "region")
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		var mu sync.Mutex
+		running, maxRunning := 0, 0
+		slow := func(ctx context.Context, config aws.Config) ([]infra_sdk.ScanResource, error) {
+			mu.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return []infra_sdk.ScanResource{{UniqueId: "slow"}}, nil
+		}
+
+		tracker := NewLimitedResourceScanTracker(2)
+		for i := 0; i < 6; i++ {
+			tracker.Scan(ctx, config, slow)
+		}
+		tracker.Wait()
+		assert.Len(t, tracker.Resources, 6)
+		assert.Equal(t, 2, maxRunning)
+	})
+}
+
+func TestScanner_Scan(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string][]string{}
+	record := func(name string) ResourceScanner {
+		return func(ctx context.Context, config aws.Config) ([]infra_sdk.ScanResource, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls[name] = append(calls[name], config.Region)
+			return []infra_sdk.ScanResource{{UniqueId: name + "/" + config.Region}}, nil
+		}
+	}
+
+	scanner := Scanner{
+		Accessor:         testAccessor{},
+		Regions:          []string{"us-east-2", "us-west-2"},
+		GlobalScanners:   []ResourceScanner{record("global")},
+		RegionalScanners: []ResourceScanner{record("regional")},
+	}
+	resources, err := scanner.Scan(context.Background())
+	require.NoError(t, err)
+
+	// Global scanners run once with the global services region; regional scanners run once per region
+	sort.Strings(calls["regional"])
+	assert.Equal(t, map[string][]string{
+		"global":   {globalServicesRegion},
+		"regional": {"us-east-2", "us-west-2"},
+	}, calls)
+
+	regions := map[string]string{}
+	for _, resource := range resources {
+		regions[resource.UniqueId] = resource.Region
+	}
+	assert.Equal(t, map[string]string{
+		"global/us-east-1":   GlobalRegion,
+		"regional/us-east-2": "us-east-2",
+		"regional/us-west-2": "us-west-2",
+	}, regions)
+}
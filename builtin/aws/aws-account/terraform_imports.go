@@ -0,0 +1,173 @@
+package aws_account
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+)
+
+// terraformImporter maps a scanned resource to its terraform resource type, import id, and identifying arguments
+type terraformImporter func(resource infra_sdk.ScanResource) (resourceType string, id string, args []infra_sdk.TerraformImportArgument)
+
+var (
+	// terraformImporters is keyed by "<ServiceName>/<ServiceResourceName>"
+	terraformImporters = map[string]terraformImporter{
+		"S3/Bucket": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			return "aws_s3_bucket", r.Name, args("bucket", r.Name)
+		},
+		"RDS/Instance": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			id := arnResourceId(r.UniqueId)
+			return "aws_db_instance", id, args("identifier", id)
+		},
+		"RDS/Aurora Cluster": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			id := arnResourceId(r.UniqueId)
+			return "aws_rds_cluster", id, args("cluster_identifier", id)
+		},
+		"ElastiCache/Cluster": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			// Cache clusters and replication groups are both reported as clusters; the arn distinguishes them
+			// e.g. arn:aws:elasticache:us-east-1:123456789012:replicationgroup:my-group
+			id := arnResourceId(r.UniqueId)
+			if parsed, err := arn.Parse(r.UniqueId); err == nil && strings.HasPrefix(parsed.Resource, "replicationgroup:") {
+				return "aws_elasticache_replication_group", id, args("replication_group_id", id)
+			}
+			return "aws_elasticache_cluster", id, args("cluster_id", id)
+		},
+		"SQS/Queue": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			// UniqueId is the queue url, which is the import id for aws_sqs_queue
+			return "aws_sqs_queue", r.UniqueId, args("name", r.UniqueId[strings.LastIndex(r.UniqueId, "/")+1:])
+		},
+		"SNS/Topic": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			return "aws_sns_topic", r.UniqueId, args("name", arnResourceId(r.UniqueId))
+		},
+		"Route53/Hosted Zone": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			// Route53 returns zone ids as "/hostedzone/Z123..."
+			id := strings.TrimPrefix(r.UniqueId, "/hostedzone/")
+			return "aws_route53_zone", id, args("name", strings.TrimSuffix(r.Name, "."))
+		},
+		"CloudFront/Distribution": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			return "aws_cloudfront_distribution", r.UniqueId, nil
+		},
+		"EFS/File System": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			return "aws_efs_file_system", arnResourceId(r.UniqueId), nil
+		},
+		"ECS/Cluster": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			return "aws_ecs_cluster", r.Name, args("name", r.Name)
+		},
+		"Fargate/Cluster": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			return "aws_ecs_cluster", r.Name, args("name", r.Name)
+		},
+		"MSK/Cluster": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			return "aws_msk_cluster", r.UniqueId, args("cluster_name", r.Name)
+		},
+		"AmazonMQ/Broker": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			// e.g. arn:aws:mq:us-east-1:123456789012:broker:my-broker:b-1234
+			return "aws_mq_broker", arnResourceId(r.UniqueId), args("broker_name", r.Name)
+		},
+		"OpenSearch/Domain": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			id := arnResourceId(r.UniqueId)
+			return "aws_opensearch_domain", id, args("domain_name", id)
+		},
+		"VPC/Network": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			return "aws_vpc", r.UniqueId, nil
+		},
+		"EC2/Load Balancer": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			return "aws_lb", r.UniqueId, args("name", r.Name)
+		},
+		"EC2/Classic Load Balancer": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			return "aws_elb", r.UniqueId, args("name", r.UniqueId)
+		},
+		"API Gateway/REST API": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			return "aws_api_gateway_rest_api", r.UniqueId, args("name", r.Name)
+		},
+		"API Gateway/HTTP API": func(r infra_sdk.ScanResource) (string, string, []infra_sdk.TerraformImportArgument) {
+			return "aws_apigatewayv2_api", r.UniqueId, args("name", r.Name)
+		},
+	}
+)
+
+// TerraformResourceType returns the terraform resource type for a scanned resource
+// An empty string is returned if the resource does not have a known terraform resource type
+func TerraformResourceType(resource infra_sdk.ScanResource) string {
+	resourceType, _ := TerraformResourceIdentity(resource)
+	return resourceType
+}
+
+// TerraformResourceIdentity returns the terraform resource type and id for a scanned resource
+// Empty strings are returned if the resource does not have a known terraform resource type
+// This can be used as infra_sdk.ScannerModule.ResourceIdentity
+func TerraformResourceIdentity(resource infra_sdk.ScanResource) (resourceType string, id string) {
+	importer, ok := terraformImporters[fmt.Sprintf("%s/%s", resource.ServiceName, resource.ServiceResourceName)]
+	if !ok {
+		return "", ""
+	}
+	resourceType, id, _ = importer(resource)
+	return resourceType, id
+}
+
+// GenerateTerraformImports converts scanned resources into terraform imports
+// Resources that do not have a known terraform resource type are returned as skipped
+// Resources outside of defaultRegion are imported with the aws provider aliased to their region (see TerraformProviderAlias)
+func GenerateTerraformImports(resources []infra_sdk.ScanResource, defaultRegion string) (imports []infra_sdk.TerraformImport, skipped []infra_sdk.ScanResource) {
+	usedAddresses := map[string]bool{}
+	for _, resource := range resources {
+		importer, ok := terraformImporters[fmt.Sprintf("%s/%s", resource.ServiceName, resource.ServiceResourceName)]
+		if !ok {
+			skipped = append(skipped, resource)
+			continue
+		}
+		resourceType, id, arguments := importer(resource)
+
+		// Ensure every resource address is unique
+		baseName := infra_sdk.TerraformIdentifier(resource.Name)
+		name := baseName
+		for i := 2; usedAddresses[resourceType+"."+name]; i++ {
+			name = fmt.Sprintf("%s_%d", baseName, i)
+		}
+		usedAddresses[resourceType+"."+name] = true
+
+		comment := fmt.Sprintf("%s %s: %s", resource.ServiceName, resource.ServiceResourceName, resource.UniqueId)
+		if resource.Region != "" && resource.Region != GlobalRegion {
+			comment += fmt.Sprintf("\nregion: %s", resource.Region)
+		}
+		var provider string
+		if alias := TerraformProviderAlias(resource.Region, defaultRegion); alias != "" {
+			provider = "aws." + alias
+		}
+		imports = append(imports, infra_sdk.TerraformImport{
+			ResourceType: resourceType,
+			ResourceName: name,
+			Id:           id,
+			Arguments:    arguments,
+			Provider:     provider,
+			Comment:      comment,
+		})
+	}
+	return imports, skipped
+}
+
+// TerraformProviderAlias returns the alias of the aws provider configuration that manages resources in region
+// The alias is the region name (e.g. `aws.us-west-2`)
+// Global resources and resources in defaultRegion are managed by the default provider, which has no alias
+func TerraformProviderAlias(region, defaultRegion string) string {
+	if region == "" || region == GlobalRegion || region == defaultRegion {
+		return ""
+	}
+	return region
+}
+
+func args(name, value string) []infra_sdk.TerraformImportArgument {
+	return []infra_sdk.TerraformImportArgument{{Name: name, Value: value}}
+}
+
+// arnResourceId returns the last segment of the resource in an arn
+// e.g. "arn:aws:rds:us-east-1:123456789012:db:my-db" => "my-db"
+// e.g. "arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/fs-1234" => "fs-1234"
+func arnResourceId(raw string) string {
+	parsed, err := arn.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return parsed.Resource[strings.LastIndexAny(parsed.Resource, ":/")+1:]
+}
@@ -0,0 +1,156 @@
+package aws_account
+
+import (
+	"fmt"
+	"testing"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerraformImporters(t *testing.T) {
+	tests := []struct {
+		service      string
+		resource     string
+		uniqueId     string
+		name         string
+		resourceType string
+		id           string
+		args         []infra_sdk.TerraformImportArgument
+	}{
+		{
+			service: "S3", resource: "Bucket", uniqueId: "arn:aws:s3:::logs", name: "logs",
+			resourceType: "aws_s3_bucket", id: "logs", args: args("bucket", "logs"),
+		},
+		{
+			service: "RDS", resource: "Instance", uniqueId: "arn:aws:rds:us-east-1:123456789012:db:main", name: "main",
+			resourceType: "aws_db_instance", id: "main", args: args("identifier", "main"),
+		},
+		{
+			service: "RDS", resource: "Aurora Cluster", uniqueId: "arn:aws:rds:us-east-1:123456789012:cluster:aurora", name: "aurora",
+			resourceType: "aws_rds_cluster", id: "aurora", args: args("cluster_identifier", "aurora"),
+		},
+		{
+			service: "ElastiCache", resource: "Cluster", uniqueId: "arn:aws:elasticache:us-east-1:123456789012:cluster:cache", name: "cache",
+			resourceType: "aws_elasticache_cluster", id: "cache", args: args("cluster_id", "cache"),
+		},
+		{
+			service: "ElastiCache", resource: "Cluster", uniqueId: "arn:aws:elasticache:us-east-1:123456789012:replicationgroup:group", name: "group",
+			resourceType: "aws_elasticache_replication_group", id: "group", args: args("replication_group_id", "group"),
+		},
+		{
+			service: "SQS", resource: "Queue", uniqueId: "https://sqs.us-east-1.amazonaws.com/123456789012/jobs", name: "arn:aws:sqs:us-east-1:123456789012:jobs",
+			resourceType: "aws_sqs_queue", id: "https://sqs.us-east-1.amazonaws.com/123456789012/jobs", args: args("name", "jobs"),
+		},
+		{
+			service: "SNS", resource: "Topic", uniqueId: "arn:aws:sns:us-east-1:123456789012:events", name: "events",
+			resourceType: "aws_sns_topic", id: "arn:aws:sns:us-east-1:123456789012:events", args: args("name", "events"),
+		},
+		{
+			service: "Route53", resource: "Hosted Zone", uniqueId: "/hostedzone/Z123", name: "example.com.",
+			resourceType: "aws_route53_zone", id: "Z123", args: args("name", "example.com"),
+		},
+		{
+			service: "CloudFront", resource: "Distribution", uniqueId: "E123", name: "cdn",
+			resourceType: "aws_cloudfront_distribution", id: "E123",
+		},
+		{
+			service: "EFS", resource: "File System", uniqueId: "arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/fs-1234", name: "shared",
+			resourceType: "aws_efs_file_system", id: "fs-1234",
+		},
+		{
+			service: "ECS", resource: "Cluster", uniqueId: "arn:aws:ecs:us-east-1:123456789012:cluster/apps", name: "apps",
+			resourceType: "aws_ecs_cluster", id: "apps", args: args("name", "apps"),
+		},
+		{
+			service: "Fargate", resource: "Cluster", uniqueId: "arn:aws:ecs:us-east-1:123456789012:cluster/tasks", name: "tasks",
+			resourceType: "aws_ecs_cluster", id: "tasks", args: args("name", "tasks"),
+		},
+		{
+			service: "MSK", resource: "Cluster", uniqueId: "arn:aws:kafka:us-east-1:123456789012:cluster/stream/abcd-1", name: "stream",
+			resourceType: "aws_msk_cluster", id: "arn:aws:kafka:us-east-1:123456789012:cluster/stream/abcd-1", args: args("cluster_name", "stream"),
+		},
+		{
+			service: "AmazonMQ", resource: "Broker", uniqueId: "arn:aws:mq:us-east-1:123456789012:broker:my-broker:b-1234", name: "my-broker",
+			resourceType: "aws_mq_broker", id: "b-1234", args: args("broker_name", "my-broker"),
+		},
+		{
+			service: "OpenSearch", resource: "Domain", uniqueId: "arn:aws:es:us-east-1:123456789012:domain/search", name: "search",
+			resourceType: "aws_opensearch_domain", id: "search", args: args("domain_name", "search"),
+		},
+		{
+			service: "VPC", resource: "Network", uniqueId: "vpc-123", name: "main",
+			resourceType: "aws_vpc", id: "vpc-123",
+		},
+		{
+			service: "EC2", resource: "Load Balancer", uniqueId: "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/web/50dc6c495c0c9188", name: "web",
+			resourceType: "aws_lb", id: "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/web/50dc6c495c0c9188", args: args("name", "web"),
+		},
+		{
+			service: "EC2", resource: "Classic Load Balancer", uniqueId: "legacy", name: "legacy",
+			resourceType: "aws_elb", id: "legacy", args: args("name", "legacy"),
+		},
+		{
+			service: "API Gateway", resource: "REST API", uniqueId: "abc123", name: "api",
+			resourceType: "aws_api_gateway_rest_api", id: "abc123", args: args("name", "api"),
+		},
+		{
+			service: "API Gateway", resource: "HTTP API", uniqueId: "def456", name: "http",
+			resourceType: "aws_apigatewayv2_api", id: "def456", args: args("name", "http"),
+		},
+	}
+
+	covered := map[string]bool{}
+	for _, test := range tests {
+		key := fmt.Sprintf("%s/%s", test.service, test.resource)
+		covered[key] = true
+		t.Run(test.resourceType, func(t *testing.T) {
+			importer, ok := terraformImporters[key]
+			require.True(t, ok, "missing importer for %s", key)
+			resource := infra_sdk.ScanResource{UniqueId: test.uniqueId, Name: test.name, ServiceName: test.service, ServiceResourceName: test.resource}
+			resourceType, id, arguments := importer(resource)
+			assert.Equal(t, test.resourceType, resourceType)
+			assert.Equal(t, test.id, id)
+			assert.Equal(t, test.args, arguments)
+
+			gotType, gotId := TerraformResourceIdentity(resource)
+			assert.Equal(t, test.resourceType, gotType)
+			assert.Equal(t, test.id, gotId)
+		})
+	}
+	for key := range terraformImporters {
+		assert.True(t, covered[key], "importer %q is not tested", key)
+	}
+}
+
+func TestGenerateTerraformImports(t *testing.T) {
+	resources := []infra_sdk.ScanResource{
+		{UniqueId: "arn:aws:s3:::logs", Name: "logs", ServiceName: "S3", ServiceResourceName: "Bucket", Region: "us-west-2"},
+		{UniqueId: "arn:aws:rds:us-east-1:123456789012:db:logs", Name: "logs", ServiceName: "RDS", ServiceResourceName: "Instance", Region: "us-east-1"},
+		{UniqueId: "arn:aws:rds:us-east-2:123456789012:db:logs", Name: "logs", ServiceName: "RDS", ServiceResourceName: "Instance", Region: "us-east-2"},
+		{UniqueId: "/hostedzone/Z123", Name: "example.com.", ServiceName: "Route53", ServiceResourceName: "Hosted Zone", Region: GlobalRegion},
+		{UniqueId: "arn:aws:lambda:us-east-1:123456789012:function:fn", Name: "fn", ServiceName: "Lambda", ServiceResourceName: "Function"},
+	}
+
+	imports, skipped := GenerateTerraformImports(resources, "us-east-1")
+
+	addresses := make([]string, 0, len(imports))
+	for _, imp := range imports {
+		addresses = append(addresses, imp.Address())
+	}
+	// Duplicate names within a resource type are suffixed; the same name is allowed across resource types
+	assert.Equal(t, []string{"aws_s3_bucket.logs", "aws_db_instance.logs", "aws_db_instance.logs_2", "aws_route53_zone.example_com"}, addresses)
+	assert.Equal(t, "S3 Bucket: arn:aws:s3:::logs\nregion: us-west-2", imports[0].Comment)
+	assert.Equal(t, "Route53 Hosted Zone: /hostedzone/Z123", imports[3].Comment)
+
+	// Resources outside of the default region are imported with a region provider alias
+	providers := make([]string, 0, len(imports))
+	for _, imp := range imports {
+		providers = append(providers, imp.Provider)
+	}
+	assert.Equal(t, []string{"aws.us-west-2", "", "aws.us-east-2", ""}, providers)
+
+	require.Len(t, skipped, 1)
+	assert.Equal(t, "fn", skipped[0].Name)
+}
@@ -8,6 +8,20 @@ func (d AwsDimension) ToUniversal() string {
 	switch d {
 	case "LINKED_ACCOUNT":
 		return infra_sdk.UniversalDimensionAccount
+	case "RESOURCE_ID":
+		return infra_sdk.UniversalDimensionResourceId
+	case "SERVICE":
+		return infra_sdk.UniversalDimensionService
+	case "REGION":
+		return infra_sdk.UniversalDimensionRegion
+	case "USAGE_TYPE":
+		return infra_sdk.UniversalDimensionUsageType
+	case "OPERATION":
+		return infra_sdk.UniversalDimensionOperation
+	case "RECORD_TYPE":
+		return infra_sdk.UniversalDimensionChargeType
+	case "PURCHASE_TYPE":
+		return infra_sdk.UniversalDimensionPurchaseOption
 	}
 	return string(d)
 }
@@ -18,6 +32,20 @@ func (d UniversalDimension) ToAws() string {
 	switch d {
 	case infra_sdk.UniversalDimensionAccount:
 		return "LINKED_ACCOUNT"
+	case infra_sdk.UniversalDimensionResourceId:
+		return "RESOURCE_ID"
+	case infra_sdk.UniversalDimensionService:
+		return "SERVICE"
+	case infra_sdk.UniversalDimensionRegion:
+		return "REGION"
+	case infra_sdk.UniversalDimensionUsageType:
+		return "USAGE_TYPE"
+	case infra_sdk.UniversalDimensionOperation:
+		return "OPERATION"
+	case infra_sdk.UniversalDimensionChargeType:
+		return "RECORD_TYPE"
+	case infra_sdk.UniversalDimensionPurchaseOption:
+		return "PURCHASE_TYPE"
 	}
 	return string(d)
 }
@@ -36,6 +64,11 @@ func (t AwsTag) ToUniversal() string {
 	return string(t)
 }
 
+// awsTagToUniversal adapts AwsTag.ToUniversal for ScanResource.WithTags
+func awsTagToUniversal(key string) string {
+	return AwsTag(key).ToUniversal()
+}
+
 type UniversalTag string
 
 func (t UniversalTag) ToAws() string {
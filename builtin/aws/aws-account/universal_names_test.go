@@ -0,0 +1,29 @@
+package aws_account
+
+import (
+	"testing"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniversalDimension(t *testing.T) {
+	tests := map[string]string{
+		infra_sdk.UniversalDimensionAccount:        "LINKED_ACCOUNT",
+		infra_sdk.UniversalDimensionService:        "SERVICE",
+		infra_sdk.UniversalDimensionRegion:         "REGION",
+		infra_sdk.UniversalDimensionUsageType:      "USAGE_TYPE",
+		infra_sdk.UniversalDimensionOperation:      "OPERATION",
+		infra_sdk.UniversalDimensionResourceId:     "RESOURCE_ID",
+		infra_sdk.UniversalDimensionChargeType:     "RECORD_TYPE",
+		infra_sdk.UniversalDimensionPurchaseOption: "PURCHASE_TYPE",
+	}
+	for universal, aws := range tests {
+		assert.Equal(t, aws, UniversalDimension(universal).ToAws(), universal)
+		assert.Equal(t, universal, AwsDimension(aws).ToUniversal(), aws)
+	}
+
+	// Cost Explorer dimensions without a universal name are passed through
+	assert.Equal(t, "INSTANCE_TYPE", UniversalDimension("INSTANCE_TYPE").ToAws())
+	assert.Equal(t, "INSTANCE_TYPE", AwsDimension("INSTANCE_TYPE").ToUniversal())
+}
@@ -0,0 +1,404 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+)
+
+const (
+	defaultWorkspace          = "default"
+	defaultWorkspaceKeyPrefix = "env:"
+	lockfileSuffix            = ".tflock"
+	digestSuffix              = "-md5"
+)
+
+var (
+	_ infra_sdk.StateFileBroker = &S3StateFileBroker{}
+	_ infra_sdk.StateFileLoader = &S3StateFileBroker{}
+
+	ErrNoLockBackend = errors.New("state locking requires DynamoDBTable or UseLockfile")
+)
+
+// S3StateFileBroker stores state in S3 using the same layout as terraform's s3 backend
+// See https://developer.hashicorp.com/terraform/language/backend/s3
+//
+// Locking is performed with a DynamoDB table (DynamoDBTable), an S3 lock file (UseLockfile), or both.
+// If neither is configured, Lock returns ErrNoLockBackend.
+type S3StateFileBroker struct {
+	Accessor infra_sdk.AwsAccessor
+	Region   string
+	Bucket   string
+	Key      string
+	// Workspace is the terraform workspace; if empty, the "default" workspace is used
+	Workspace string
+	// WorkspaceKeyPrefix is the prefix for non-default workspaces; if empty, "env:" is used
+	WorkspaceKeyPrefix string
+	// DynamoDBTable is a table with a string hash key named "LockID"
+	DynamoDBTable string
+	// UseLockfile enables locking with a ".tflock" object that is created using S3 conditional writes
+	UseLockfile bool
+	// Operation is recorded in the lock info; if empty, infra_sdk.StateLockOperationRefresh is used
+	Operation string
+
+	mu       sync.Mutex
+	lockInfo *infra_sdk.StateLockInfo
+}
+
+// StateKey returns the object key that contains the state for the configured workspace
+func (b *S3StateFileBroker) StateKey() string {
+	if b.Workspace == "" || b.Workspace == defaultWorkspace {
+		return b.Key
+	}
+	prefix := b.WorkspaceKeyPrefix
+	if prefix == "" {
+		prefix = defaultWorkspaceKeyPrefix
+	}
+	return path.Join(prefix, b.Workspace, b.Key)
+}
+
+// lockId is the LockID used in DynamoDB
+func (b *S3StateFileBroker) lockId() string {
+	return fmt.Sprintf("%s/%s", b.Bucket, b.StateKey())
+}
+
+func (b *S3StateFileBroker) Initialize(ctx context.Context) error {
+	s3Client, ddbClient, err := b.clients()
+	if err != nil {
+		return err
+	}
+	if _, err := s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(b.Bucket)}); err != nil {
+		return fmt.Errorf("error accessing state bucket %q: %w", b.Bucket, err)
+	}
+
+	current, _, err := b.load(ctx, s3Client)
+	if err != nil || current != nil {
+		return err
+	}
+	// IfNoneMatch ensures we never overwrite a state file that was written after we checked
+	if err := b.put(ctx, s3Client, ddbClient, infra_sdk.NewStateFile(), nil); err != nil && !isPreconditionFailed(err) {
+		return fmt.Errorf("error initializing state file: %w", err)
+	}
+	return nil
+}
+
+func (b *S3StateFileBroker) Lock(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.lockInfo != nil {
+		return nil
+	}
+	if b.DynamoDBTable == "" && !b.UseLockfile {
+		return ErrNoLockBackend
+	}
+
+	s3Client, ddbClient, err := b.clients()
+	if err != nil {
+		return err
+	}
+	info := infra_sdk.NewStateLockInfo(b.Operation, b.StateKey())
+	raw, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("error encoding lock info: %w", err)
+	}
+
+	if b.DynamoDBTable != "" {
+		if err := b.lockDynamoDB(ctx, ddbClient, raw); err != nil {
+			return err
+		}
+	}
+	if b.UseLockfile {
+		if err := b.lockS3(ctx, s3Client, raw); err != nil {
+			if b.DynamoDBTable != "" {
+				// Release the DynamoDB lock so that we don't leave a dangling lock
+				_ = b.unlockDynamoDB(ctx, ddbClient, raw)
+			}
+			return err
+		}
+	}
+
+	b.lockInfo = &info
+	return nil
+}
+
+func (b *S3StateFileBroker) Unlock(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.lockInfo == nil {
+		return nil
+	}
+
+	s3Client, ddbClient, err := b.clients()
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(b.lockInfo)
+	if err != nil {
+		return fmt.Errorf("error encoding lock info: %w", err)
+	}
+
+	var errs []error
+	if b.UseLockfile {
+		if err := b.unlockS3(ctx, s3Client, b.lockInfo.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if b.DynamoDBTable != "" {
+		if err := b.unlockDynamoDB(ctx, ddbClient, raw); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	b.lockInfo = nil
+	return nil
+}
+
+func (b *S3StateFileBroker) Load(ctx context.Context) (*infra_sdk.StateFile, error) {
+	s3Client, _, err := b.clients()
+	if err != nil {
+		return nil, err
+	}
+	stateFile, _, err := b.load(ctx, s3Client)
+	return stateFile, err
+}
+
+func (b *S3StateFileBroker) Save(ctx context.Context, stateFile infra_sdk.StateFile) error {
+	b.mu.Lock()
+	locked := b.lockInfo != nil
+	b.mu.Unlock()
+	if !locked {
+		return infra_sdk.ErrStateNotLocked
+	}
+
+	s3Client, ddbClient, err := b.clients()
+	if err != nil {
+		return err
+	}
+	current, etag, err := b.load(ctx, s3Client)
+	if err != nil {
+		return err
+	}
+	next, err := infra_sdk.PrepareStateFileSave(current, stateFile)
+	if err != nil {
+		return err
+	}
+	if err := b.put(ctx, s3Client, ddbClient, next, etag); err != nil {
+		if isPreconditionFailed(err) {
+			return fmt.Errorf("state file was modified while saving: %w", err)
+		}
+		return fmt.Errorf("error saving state file: %w", err)
+	}
+	return nil
+}
+
+func (b *S3StateFileBroker) clients() (*s3.Client, *dynamodb.Client, error) {
+	if b.Accessor == nil {
+		return nil, nil, fmt.Errorf("aws accessor is required")
+	}
+	awsConfig, err := b.Accessor.NewConfig(b.Region)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error resolving aws config: %w", err)
+	}
+	if awsConfig == nil {
+		return nil, nil, fmt.Errorf("aws config is not available")
+	}
+	return s3.NewFromConfig(*awsConfig), dynamodb.NewFromConfig(*awsConfig), nil
+}
+
+// load retrieves the state file and its ETag
+func (b *S3StateFileBroker) load(ctx context.Context, client *s3.Client) (*infra_sdk.StateFile, *string, error) {
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(b.StateKey()),
+	})
+	if err != nil {
+		var nsk *s3types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("error retrieving state file: %w", err)
+	}
+	defer out.Body.Close()
+
+	raw, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading state file: %w", err)
+	}
+	if len(raw) == 0 {
+		return nil, out.ETag, nil
+	}
+	var stateFile infra_sdk.StateFile
+	if err := json.Unmarshal(raw, &stateFile); err != nil {
+		return nil, nil, fmt.Errorf("error decoding state file: %w", err)
+	}
+	return &stateFile, out.ETag, nil
+}
+
+// put writes the state file
+// If etag is nil, the write only succeeds if the object does not exist; otherwise, it must match etag
+// When locking with DynamoDB, the state digest is updated so that terraform can verify the state it reads
+func (b *S3StateFileBroker) put(ctx context.Context, s3Client *s3.Client, ddbClient *dynamodb.Client, stateFile infra_sdk.StateFile, etag *string) error {
+	raw, err := json.MarshalIndent(stateFile, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding state file: %w", err)
+	}
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(b.Bucket),
+		Key:         aws.String(b.StateKey()),
+		Body:        bytes.NewReader(raw),
+		ContentType: aws.String("application/json"),
+	}
+	if etag == nil {
+		input.IfNoneMatch = aws.String("*")
+	} else {
+		input.IfMatch = etag
+	}
+	if _, err := s3Client.PutObject(ctx, input); err != nil {
+		return err
+	}
+
+	if b.DynamoDBTable != "" {
+		sum := md5.Sum(raw)
+		_, err := ddbClient.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String(b.DynamoDBTable),
+			Item: map[string]ddbtypes.AttributeValue{
+				"LockID": &ddbtypes.AttributeValueMemberS{Value: b.lockId() + digestSuffix},
+				"Digest": &ddbtypes.AttributeValueMemberS{Value: hex.EncodeToString(sum[:])},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("error updating state digest: %w", err)
+		}
+	}
+	return nil
+}
+
+func (b *S3StateFileBroker) lockDynamoDB(ctx context.Context, client *dynamodb.Client, info []byte) error {
+	_, err := client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(b.DynamoDBTable),
+		Item: map[string]ddbtypes.AttributeValue{
+			"LockID": &ddbtypes.AttributeValueMemberS{Value: b.lockId()},
+			"Info":   &ddbtypes.AttributeValueMemberS{Value: string(info)},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(LockID)"),
+	})
+	if err == nil {
+		return nil
+	}
+	var ccf *ddbtypes.ConditionalCheckFailedException
+	if !errors.As(err, &ccf) {
+		return fmt.Errorf("error acquiring state lock: %w", err)
+	}
+
+	out, getErr := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(b.DynamoDBTable),
+		Key:            map[string]ddbtypes.AttributeValue{"LockID": &ddbtypes.AttributeValueMemberS{Value: b.lockId()}},
+		ConsistentRead: aws.Bool(true),
+	})
+	existing := infra_sdk.StateLockInfo{}
+	if getErr == nil {
+		if attr, ok := out.Item["Info"].(*ddbtypes.AttributeValueMemberS); ok {
+			_ = json.Unmarshal([]byte(attr.Value), &existing)
+		}
+	}
+	return infra_sdk.StateLockedError{Info: existing}
+}
+
+func (b *S3StateFileBroker) unlockDynamoDB(ctx context.Context, client *dynamodb.Client, info []byte) error {
+	_, err := client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(b.DynamoDBTable),
+		Key:                 map[string]ddbtypes.AttributeValue{"LockID": &ddbtypes.AttributeValueMemberS{Value: b.lockId()}},
+		ConditionExpression: aws.String("Info = :info"),
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+			":info": &ddbtypes.AttributeValueMemberS{Value: string(info)},
+		},
+	})
+	if err != nil {
+		var ccf *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return fmt.Errorf("error releasing state lock: lock is held by someone else")
+		}
+		return fmt.Errorf("error releasing state lock: %w", err)
+	}
+	return nil
+}
+
+func (b *S3StateFileBroker) lockS3(ctx context.Context, client *s3.Client, info []byte) error {
+	lockKey := b.StateKey() + lockfileSuffix
+	_, err := client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(b.Bucket),
+		Key:         aws.String(lockKey),
+		Body:        bytes.NewReader(info),
+		ContentType: aws.String("application/json"),
+		IfNoneMatch: aws.String("*"),
+	})
+	if err == nil {
+		return nil
+	}
+	if !isPreconditionFailed(err) {
+		return fmt.Errorf("error acquiring state lock: %w", err)
+	}
+	existing, _ := b.readS3Lock(ctx, client)
+	return infra_sdk.StateLockedError{Info: existing}
+}
+
+func (b *S3StateFileBroker) unlockS3(ctx context.Context, client *s3.Client, lockId string) error {
+	existing, err := b.readS3Lock(ctx, client)
+	if err != nil {
+		return fmt.Errorf("error releasing state lock: %w", err)
+	}
+	if existing.ID != lockId {
+		return fmt.Errorf("error releasing state lock: lock is held by someone else (id=%s)", existing.ID)
+	}
+	_, err = client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(b.StateKey() + lockfileSuffix),
+	})
+	if err != nil {
+		return fmt.Errorf("error releasing state lock: %w", err)
+	}
+	return nil
+}
+
+func (b *S3StateFileBroker) readS3Lock(ctx context.Context, client *s3.Client) (infra_sdk.StateLockInfo, error) {
+	info := infra_sdk.StateLockInfo{}
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(b.StateKey() + lockfileSuffix),
+	})
+	if err != nil {
+		return info, err
+	}
+	defer out.Body.Close()
+	err = json.NewDecoder(out.Body).Decode(&info)
+	return info, err
+}
+
+// isPreconditionFailed detects a failed conditional write (If-Match/If-None-Match)
+func isPreconditionFailed(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "PreconditionFailed", "ConditionalRequestConflict":
+			return true
+		}
+	}
+	return false
+}
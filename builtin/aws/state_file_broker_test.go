@@ -0,0 +1,253 @@
+package aws
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAccessor struct {
+	endpoint string
+}
+
+func (a testAccessor) NewConfig(region string) (*aws.Config, error) {
+	return &aws.Config{
+		Region:                     region,
+		BaseEndpoint:               aws.String(a.endpoint),
+		Credentials:                credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	}, nil
+}
+
+func (a testAccessor) AwsAccountId() string { return "123456789012" }
+
+// awsServer is a minimal implementation of the S3 and DynamoDB apis used by S3StateFileBroker
+// S3 requests use path-style addressing (/<bucket>/<key>); DynamoDB requests are identified by X-Amz-Target
+type awsServer struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	items   map[string]map[string]map[string]string
+	// afterGet is called after an object is retrieved; this allows tests to simulate a concurrent write
+	afterGet func(key string)
+}
+
+func newAwsServer() *awsServer {
+	return &awsServer{
+		objects: map[string][]byte{},
+		items:   map[string]map[string]map[string]string{},
+	}
+}
+
+func (s *awsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if target := r.Header.Get("X-Amz-Target"); target != "" {
+		s.serveDynamoDB(w, r, strings.TrimPrefix(target, "DynamoDB_20120810."))
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if !strings.Contains(key, "/") {
+		// HeadBucket
+		return
+	}
+	existing, exists := s.objects[key]
+	switch r.Method {
+	case http.MethodGet:
+		if !exists {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", objectETag(existing))
+		w.Write(existing)
+		if s.afterGet != nil {
+			s.afterGet(key)
+		}
+	case http.MethodPut:
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && (!exists || ifMatch != objectETag(existing)) {
+			writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		s.objects[key] = body
+		w.Header().Set("ETag", objectETag(body))
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *awsServer) serveDynamoDB(w http.ResponseWriter, r *http.Request, operation string) {
+	var input struct {
+		TableName                 string
+		Item                      map[string]map[string]string
+		Key                       map[string]map[string]string
+		ConditionExpression       string
+		ExpressionAttributeValues map[string]map[string]string
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+
+	switch operation {
+	case "PutItem":
+		lockId := input.Item["LockID"]["S"]
+		if _, exists := s.items[lockId]; exists && input.ConditionExpression == "attribute_not_exists(LockID)" {
+			writeDynamoDBError(w, "ConditionalCheckFailedException")
+			return
+		}
+		s.items[lockId] = input.Item
+		w.Write([]byte(`{}`))
+	case "GetItem":
+		item, ok := s.items[input.Key["LockID"]["S"]]
+		if !ok {
+			w.Write([]byte(`{}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"Item": item})
+	case "DeleteItem":
+		lockId := input.Key["LockID"]["S"]
+		item, exists := s.items[lockId]
+		if input.ConditionExpression == "Info = :info" && (!exists || item["Info"]["S"] != input.ExpressionAttributeValues[":info"]["S"]) {
+			writeDynamoDBError(w, "ConditionalCheckFailedException")
+			return
+		}
+		delete(s.items, lockId)
+		w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func objectETag(raw []byte) string {
+	sum := md5.Sum(raw)
+	return fmt.Sprintf("%q", hex.EncodeToString(sum[:]))
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+}
+
+func writeDynamoDBError(w http.ResponseWriter, code string) {
+	w.WriteHeader(http.StatusBadRequest)
+	fmt.Fprintf(w, `{"__type":"com.amazonaws.dynamodb.v20120810#%s","message":"%s"}`, code, code)
+}
+
+func TestS3StateFileBroker(t *testing.T) {
+	ctx := context.Background()
+	server := newAwsServer()
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	newBroker := func(dynamoDBTable string, useLockfile bool) *S3StateFileBroker {
+		return &S3StateFileBroker{
+			Accessor:      testAccessor{endpoint: ts.URL},
+			Region:        "us-east-1",
+			Bucket:        "state",
+			Key:           "app/terraform.tfstate",
+			DynamoDBTable: dynamoDBTable,
+			UseLockfile:   useLockfile,
+		}
+	}
+
+	t.Run("no lock backend", func(t *testing.T) {
+		broker := newBroker("", false)
+		assert.ErrorIs(t, broker.Lock(ctx), ErrNoLockBackend)
+		assert.ErrorIs(t, broker.Save(ctx, infra_sdk.NewStateFile()), infra_sdk.ErrStateNotLocked)
+	})
+
+	tests := []struct {
+		name          string
+		dynamoDBTable string
+		useLockfile   bool
+	}{
+		{name: "dynamodb", dynamoDBTable: "locks"},
+		{name: "lockfile", useLockfile: true},
+		{name: "dynamodb and lockfile", dynamoDBTable: "locks", useLockfile: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			broker := newBroker(test.dynamoDBTable, test.useLockfile)
+			require.NoError(t, broker.Initialize(ctx))
+			initial, err := broker.Load(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, initial)
+
+			require.NoError(t, broker.Lock(ctx))
+			var lockedErr infra_sdk.StateLockedError
+			other := newBroker(test.dynamoDBTable, test.useLockfile)
+			require.ErrorAs(t, other.Lock(ctx), &lockedErr)
+			assert.Equal(t, broker.lockInfo.ID, lockedErr.Info.ID)
+
+			next := *initial
+			next.TerraformVersion = "1.9.0"
+			require.NoError(t, broker.Save(ctx, next))
+			require.NoError(t, broker.Unlock(ctx))
+			assert.Empty(t, server.objects["state/app/terraform.tfstate"+lockfileSuffix])
+			_, dynamoDBLocked := server.items["state/app/terraform.tfstate"]
+			assert.False(t, dynamoDBLocked)
+
+			saved, err := broker.Load(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, initial.Serial+1, saved.Serial)
+			assert.Equal(t, initial.Lineage, saved.Lineage)
+			assert.Equal(t, "1.9.0", saved.TerraformVersion)
+			if test.dynamoDBTable != "" {
+				sum := md5.Sum(server.objects["state/app/terraform.tfstate"])
+				assert.Equal(t, hex.EncodeToString(sum[:]), server.items["state/app/terraform.tfstate"+digestSuffix]["Digest"]["S"])
+			}
+
+			// The lock is released, so the other broker can acquire it
+			require.NoError(t, other.Lock(ctx))
+			require.NoError(t, other.Unlock(ctx))
+		})
+	}
+
+	t.Run("save precondition", func(t *testing.T) {
+		broker := newBroker("", true)
+		require.NoError(t, broker.Initialize(ctx))
+		current, err := broker.Load(ctx)
+		require.NoError(t, err)
+		require.NoError(t, broker.Lock(ctx))
+		defer broker.Unlock(ctx)
+
+		// Simulate a write that happens between reading the current state and writing the next state
+		stateKey := "state/app/terraform.tfstate"
+		server.afterGet = func(key string) {
+			if key == stateKey {
+				server.objects[key] = append(server.objects[key], '\n')
+				server.afterGet = nil
+			}
+		}
+		before := string(server.objects[stateKey])
+		err = broker.Save(ctx, *current)
+		assert.ErrorContains(t, err, "state file was modified while saving")
+		assert.Equal(t, before+"\n", string(server.objects[stateKey]))
+	})
+}
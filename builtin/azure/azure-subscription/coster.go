@@ -0,0 +1,400 @@
+package azure_subscription
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+)
+
+const (
+	// usageQuantityUnit is reported for usage quantities since they are summed across meters with different units
+	// This matches the unit that Cost Explorer reports for UsageQuantity across mixed usage types
+	usageQuantityUnit = "N/A"
+
+	// maxGroupings is the maximum number of group by clauses that the Query API supports
+	maxGroupings = 2
+)
+
+var (
+	// granularityMappings maps a granularity to the Query API granularity
+	// The Query API does not support hourly granularity
+	// "Monthly" is accepted by the API even though the SDK only defines "Daily"
+	granularityMappings = map[infra_sdk.CostGranularity]armcostmanagement.GranularityType{
+		infra_sdk.CostGranularityDaily:   armcostmanagement.GranularityTypeDaily,
+		infra_sdk.CostGranularityMonthly: armcostmanagement.GranularityType("Monthly"),
+	}
+
+	// metricMappings maps a universal metric to an aggregation in the Query API
+	// ActualCost is the cost as it is charged, which is the equivalent of unblended cost
+	// AmortizedCost spreads reservation and savings plan purchases across usage, which is the equivalent of amortized cost
+	// Azure does not report blended or net costs, so those metrics are not supported
+	metricMappings = map[string]queryMetric{
+		infra_sdk.UniversalMetricUnblendedCost: {
+			Type:          armcostmanagement.ExportTypeActualCost,
+			Column:        "Cost",
+			ResultColumns: []string{"cost", "pretaxcost", "totalcost"},
+		},
+		infra_sdk.UniversalMetricAmortizedCost: {
+			Type:          armcostmanagement.ExportTypeAmortizedCost,
+			Column:        "Cost",
+			ResultColumns: []string{"cost", "pretaxcost", "totalcost"},
+		},
+		infra_sdk.UniversalMetricUsageQuantity: {
+			Type:          armcostmanagement.ExportTypeActualCost,
+			Column:        "UsageQuantity",
+			ResultColumns: []string{"usagequantity", "totalusagequantity"},
+			IsUsage:       true,
+		},
+	}
+)
+
+var (
+	_ infra_sdk.Coster = Coster{}
+)
+
+// queryMetric is an aggregation in the Query API that computes a universal metric
+type queryMetric struct {
+	// Type is the cost dataset that is queried
+	Type armcostmanagement.ExportType
+	// Column is the aggregated column
+	Column string
+	// ResultColumns are the lowercase names that the aggregated column may have in the results
+	ResultColumns []string
+	// IsUsage is true if the metric is a usage quantity instead of a cost in the billing currency
+	IsUsage bool
+}
+
+// costQuery is a Query API request and the universal metrics that it aggregates
+type costQuery struct {
+	Definition armcostmanagement.QueryDefinition
+	Metrics    []string
+}
+
+// Coster queries actual costs for a subscription from the Cost Management Query API
+// See https://learn.microsoft.com/en-us/rest/api/cost-management/query/usage
+type Coster struct {
+	Accessor infra_sdk.AzureAccessor
+	// ClientOptions are passed to the cost management clients
+	ClientOptions *arm.ClientOptions
+}
+
+func (c Coster) GetCosts(ctx context.Context, query infra_sdk.CostQuery) (*infra_sdk.CostResult, error) {
+	if c.Accessor == nil {
+		return nil, nil
+	}
+
+	granularity := query.Granularity
+	if granularity == "" {
+		granularity = infra_sdk.CostGranularityDaily
+	}
+	if _, ok := granularityMappings[granularity]; !ok {
+		return nil, fmt.Errorf("unsupported cost granularity %q", granularity)
+	}
+
+	groupBy := query.GroupBy.Unique()
+	queries, err := buildQueries(query, granularity, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := c.Accessor.GetCredential(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving azure credentials: %w", err)
+	}
+	if credential == nil {
+		return nil, nil
+	}
+	client, err := armcostmanagement.NewQueryClient(credential, c.ClientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure cost management client: %w", err)
+	}
+	// The generated QueryClient does not follow next links, so subsequent pages are requested through an arm client pipeline
+	armClient, err := arm.NewClient("infra-sdk", "v0.0.0", credential, c.ClientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure cost management client: %w", err)
+	}
+
+	scope := fmt.Sprintf("/subscriptions/%s", c.Accessor.AzureSubscriptionId())
+	result := infra_sdk.NewCostResult()
+	for _, cur := range queries {
+		out, err := client.Usage(ctx, scope, cur.Definition, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error querying azure cost management: %w", err)
+		}
+
+		props := out.Properties
+		for props != nil {
+			if err := addQueryResults(result, props, cur.Metrics, granularity, groupBy); err != nil {
+				return nil, fmt.Errorf("error parsing result: %w", err)
+			}
+			if props.NextLink == nil || *props.NextLink == "" {
+				break
+			}
+			if props, err = queryNextPage(ctx, armClient, *props.NextLink, cur.Definition); err != nil {
+				return nil, fmt.Errorf("error querying azure cost management: %w", err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// buildQueries translates a CostQuery into Query API requests
+// The cost dataset is chosen per request, so a request is built for each dataset that the requested metrics need
+func buildQueries(query infra_sdk.CostQuery, granularity infra_sdk.CostGranularity, groupBy infra_sdk.CostGroupIdentifiers) ([]costQuery, error) {
+	base, err := buildQueryDefinition(query, granularity, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []costQuery
+	for _, metricName := range query.UniqueMetrics() {
+		metric, ok := metricMappings[metricName]
+		if !ok {
+			return nil, fmt.Errorf("unsupported cost metric %q", metricName)
+		}
+		idx := slices.IndexFunc(queries, func(q costQuery) bool { return *q.Definition.Type == metric.Type })
+		if idx < 0 {
+			definition, dataset := base, *base.Dataset
+			definition.Type = ptr(metric.Type)
+			dataset.Aggregation = map[string]*armcostmanagement.QueryAggregation{}
+			definition.Dataset = &dataset
+			queries = append(queries, costQuery{Definition: definition})
+			idx = len(queries) - 1
+		}
+		queries[idx].Definition.Dataset.Aggregation["total"+metric.Column] = &armcostmanagement.QueryAggregation{
+			Name:     ptr(metric.Column),
+			Function: ptr(armcostmanagement.FunctionTypeSum),
+		}
+		queries[idx].Metrics = append(queries[idx].Metrics, metricName)
+	}
+	return queries, nil
+}
+
+// buildQueryDefinition builds the time period, groupings, and filters that are shared by every request
+// The cost dataset and aggregations are set by buildQueries
+func buildQueryDefinition(query infra_sdk.CostQuery, granularity infra_sdk.CostGranularity, groupBy infra_sdk.CostGroupIdentifiers) (armcostmanagement.QueryDefinition, error) {
+	if query.Filter != nil {
+		return armcostmanagement.QueryDefinition{}, fmt.Errorf("cost filter expressions are not supported for azure, use filter tags instead")
+	}
+	if len(groupBy) > maxGroupings {
+		return armcostmanagement.QueryDefinition{}, fmt.Errorf("azure supports grouping by at most %d dimensions or tags", maxGroupings)
+	}
+
+	var grouping []*armcostmanagement.QueryGrouping
+	tagGroupings := 0
+	for _, cur := range groupBy {
+		if cur.Dimension != "" {
+			dimension := UniversalDimension(cur.Dimension).ToAzure()
+			if dimension == "" {
+				return armcostmanagement.QueryDefinition{}, fmt.Errorf("unsupported cost dimension %q", cur.Dimension)
+			}
+			grouping = append(grouping, &armcostmanagement.QueryGrouping{
+				Name: ptr(dimension),
+				Type: ptr(armcostmanagement.QueryColumnTypeDimension),
+			})
+		} else {
+			// Tag groupings are returned in shared TagKey/TagValue columns, so only one is distinguishable
+			if tagGroupings++; tagGroupings > 1 {
+				return armcostmanagement.QueryDefinition{}, fmt.Errorf("azure supports grouping by at most 1 tag")
+			}
+			grouping = append(grouping, &armcostmanagement.QueryGrouping{
+				Name: ptr(UniversalTag(cur.TagKey).ToAzure()),
+				Type: ptr(armcostmanagement.QueryColumnTypeTag),
+			})
+		}
+	}
+
+	// The Query API treats the end of the time period as inclusive
+	// CostQuery.End is exclusive, so we stop 1 second before it
+	return armcostmanagement.QueryDefinition{
+		Timeframe: ptr(armcostmanagement.TimeframeTypeCustom),
+		TimePeriod: &armcostmanagement.QueryTimePeriod{
+			From: ptr(query.Start.UTC()),
+			To:   ptr(query.End.UTC().Add(-time.Second)),
+		},
+		Dataset: &armcostmanagement.QueryDataset{
+			Granularity: ptr(granularityMappings[granularity]),
+			Grouping:    grouping,
+			Filter:      costQueryToFilter(query),
+		},
+	}, nil
+}
+
+func costQueryToFilter(query infra_sdk.CostQuery) *armcostmanagement.QueryFilter {
+	var filters []*armcostmanagement.QueryFilter
+	for _, filterTag := range query.FilterTags {
+		values := make([]*string, 0, len(filterTag.Values))
+		for _, value := range filterTag.Values {
+			values = append(values, ptr(value))
+		}
+		filters = append(filters, &armcostmanagement.QueryFilter{
+			Tags: &armcostmanagement.QueryComparisonExpression{
+				Name:     ptr(UniversalTag(filterTag.Key).ToAzure()),
+				Operator: ptr(armcostmanagement.QueryOperatorTypeIn),
+				Values:   values,
+			},
+		})
+	}
+
+	switch len(filters) {
+	case 0:
+		return nil
+	case 1:
+		return filters[0]
+	default:
+		return &armcostmanagement.QueryFilter{And: filters}
+	}
+}
+
+// queryNextPage retrieves the next page of results by posting the query definition to the next link
+func queryNextPage(ctx context.Context, armClient *arm.Client, nextLink string, definition armcostmanagement.QueryDefinition) (*armcostmanagement.QueryProperties, error) {
+	req, err := runtime.NewRequest(ctx, http.MethodPost, nextLink)
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.MarshalAsJSON(req, definition); err != nil {
+		return nil, err
+	}
+	resp, err := armClient.Pipeline().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return nil, runtime.NewResponseError(resp)
+	}
+	var out armcostmanagement.QueryResult
+	if err := runtime.UnmarshalAsJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return out.Properties, nil
+}
+
+// addQueryResults adds each row in a Query API result to the CostResult
+// Rows are positional; the columns are identified by name:
+//   - Cost (or PreTaxCost), UsageQuantity: aggregated metrics
+//   - UsageDate: day of usage as a number (e.g. 20250301) for daily granularity
+//   - BillingMonth: start of the month as a timestamp for monthly granularity
+//   - Currency: currency of the cost
+//   - <dimension name>: value for each dimension grouping
+//   - TagKey/TagValue: the tag grouping
+func addQueryResults(result *infra_sdk.CostResult, props *armcostmanagement.QueryProperties, metrics []string,
+	granularity infra_sdk.CostGranularity, groupBy infra_sdk.CostGroupIdentifiers) error {
+	columns := map[string]int{}
+	for i, column := range props.Columns {
+		if column != nil {
+			columns[strings.ToLower(unptr(column.Name))] = i
+		}
+	}
+	metricIdxs := make([]int, len(metrics))
+	for i, metricName := range metrics {
+		idx, ok := findColumn(columns, metricMappings[metricName].ResultColumns...)
+		if !ok {
+			return fmt.Errorf("missing %s column in results", metricName)
+		}
+		metricIdxs[i] = idx
+	}
+	dateIdx, ok := findColumn(columns, "usagedate", "billingmonth")
+	if !ok {
+		return fmt.Errorf("missing date column in results")
+	}
+	currencyIdx, hasCurrency := findColumn(columns, "currency")
+
+	for _, row := range props.Rows {
+		start, err := parseUsageDate(columnValue(row, dateIdx))
+		if err != nil {
+			return err
+		}
+		currency := ""
+		if hasCurrency {
+			currency = columnValue(row, currencyIdx)
+		}
+
+		groupKeys := make(infra_sdk.CostSeriesGroupKeys, 0, len(groupBy))
+		for _, cur := range groupBy {
+			if cur.Dimension != "" {
+				value := ""
+				if idx, ok := findColumn(columns, strings.ToLower(UniversalDimension(cur.Dimension).ToAzure())); ok {
+					value = columnValue(row, idx)
+				}
+				groupKeys = append(groupKeys, infra_sdk.CostSeriesGroupKey{Name: cur.Dimension, Value: value})
+			} else {
+				value := ""
+				if idx, ok := findColumn(columns, "tagvalue"); ok {
+					value = columnValue(row, idx)
+				}
+				groupKeys = append(groupKeys, infra_sdk.CostSeriesGroupKey{TagKey: cur.TagKey, Value: value})
+			}
+		}
+
+		for i, metricName := range metrics {
+			unit := currency
+			if metricMappings[metricName].IsUsage {
+				unit = usageQuantityUnit
+			}
+			result.AddDatapoint(metricName, groupKeys, infra_sdk.CostSeriesDatapoint{
+				Start: start,
+				End:   windowEnd(start, granularity),
+				Unit:  unit,
+				Value: columnValue(row, metricIdxs[i]),
+			})
+		}
+	}
+	return nil
+}
+
+func findColumn(columns map[string]int, names ...string) (int, bool) {
+	for _, name := range names {
+		if idx, ok := columns[name]; ok {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// columnValue formats a row value as a string
+// Numbers are decoded from JSON as float64 and are formatted without an exponent
+func columnValue(row []any, idx int) string {
+	if idx >= len(row) {
+		return ""
+	}
+	switch v := row[idx].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// parseUsageDate parses a UsageDate (e.g. "20250301") or BillingMonth (e.g. "2025-03-01T00:00:00")
+func parseUsageDate(raw string) (time.Time, error) {
+	if t, err := time.Parse("20060102", raw); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02T15:04:05", raw); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid usage date in results %q", raw)
+}
+
+func windowEnd(start time.Time, granularity infra_sdk.CostGranularity) time.Time {
+	if granularity == infra_sdk.CostGranularityMonthly {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
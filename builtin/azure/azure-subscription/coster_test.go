@@ -0,0 +1,203 @@
+package azure_subscription
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddQueryResults(t *testing.T) {
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	props := &armcostmanagement.QueryProperties{
+		Columns: []*armcostmanagement.QueryColumn{
+			{Name: ptr("Cost"), Type: ptr("Number")},
+			{Name: ptr("UsageDate"), Type: ptr("Number")},
+			{Name: ptr("SubscriptionId"), Type: ptr("String")},
+			{Name: ptr("TagKey"), Type: ptr("String")},
+			{Name: ptr("TagValue"), Type: ptr("String")},
+			{Name: ptr("Currency"), Type: ptr("String")},
+		},
+		Rows: [][]any{
+			{1.5, float64(20250301), "sub-1", "env", "dev", "USD"},
+			{0.0025, float64(20250302), "sub-1", "env", "dev", "USD"},
+			{float64(10), float64(20250301), "sub-1", "", "", "USD"},
+		},
+	}
+	groupBy := infra_sdk.CostGroupIdentifiers{
+		{Dimension: infra_sdk.UniversalDimensionAccount},
+		{TagKey: infra_sdk.UniversalTagEnv},
+	}
+
+	result := infra_sdk.NewCostResult()
+	require.NoError(t, addQueryResults(result, props, []string{infra_sdk.UniversalMetricUnblendedCost}, infra_sdk.CostGranularityDaily, groupBy))
+
+	require.Len(t, result.Series, 2)
+	dev, ok := result.Series["nullstone.io/cloud-account$sub-1;nullstone.io/env$dev:UnblendedCost"]
+	require.True(t, ok)
+	assert.Equal(t, []infra_sdk.CostSeriesDatapoint{
+		{Start: day1, End: day2, Unit: "USD", Value: "1.5"},
+		{Start: day2, End: day2.AddDate(0, 0, 1), Unit: "USD", Value: "0.0025"},
+	}, dev.Points)
+
+	untagged, ok := result.Series["nullstone.io/cloud-account$sub-1;nullstone.io/env$:UnblendedCost"]
+	require.True(t, ok)
+	require.Len(t, untagged.Points, 1)
+	assert.Equal(t, "10", untagged.Points[0].Value)
+}
+
+func TestCoster_GetCosts(t *testing.T) {
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	// The first page links to the second page, which must be requested with the same query definition
+	var definitions []armcostmanagement.QueryDefinition
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, testSubscription+"/providers/Microsoft.CostManagement/query", r.URL.Path)
+		var definition armcostmanagement.QueryDefinition
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&definition))
+		definitions = append(definitions, definition)
+
+		columns := `[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"},{"name":"Currency","type":"String"}]`
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("$skiptoken") == "" {
+			nextLink := "https://management.azure.com" + r.URL.Path + "?api-version=2023-03-01&$skiptoken=page2"
+			fmt.Fprintf(w, `{"properties":{"nextLink":%q,"columns":%s,"rows":[[1.5,20250301,"USD"]]}}`, nextLink, columns)
+			return
+		}
+		fmt.Fprintf(w, `{"properties":{"columns":%s,"rows":[[2.5,20250302,"USD"]]}}`, columns)
+	}))
+	defer ts.Close()
+	target, _ := url.Parse(ts.URL)
+
+	coster := Coster{
+		Accessor: testAccessor{},
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: testTransport{target: target},
+				Retry:     policy.RetryOptions{MaxRetries: -1},
+			},
+		},
+	}
+	result, err := coster.GetCosts(context.Background(), infra_sdk.CostQuery{
+		Start:       day1,
+		End:         day2.AddDate(0, 0, 1),
+		Granularity: infra_sdk.CostGranularityDaily,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, definitions, 2)
+	assert.Equal(t, definitions[0], definitions[1])
+	assert.Equal(t, armcostmanagement.ExportTypeActualCost, unptr(definitions[0].Type))
+
+	require.Len(t, result.Series, 1)
+	for _, series := range result.Series {
+		assert.Equal(t, []infra_sdk.CostSeriesDatapoint{
+			{Start: day1, End: day2, Unit: "USD", Value: "1.5"},
+			{Start: day2, End: day2.AddDate(0, 0, 1), Unit: "USD", Value: "2.5"},
+		}, series.Points)
+	}
+}
+
+func TestBuildQueryDefinition_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		groupBy infra_sdk.CostGroupIdentifiers
+		errMsg  string
+	}{
+		{
+			name:    "unsupported dimension",
+			groupBy: infra_sdk.CostGroupIdentifiers{{Dimension: "unknown"}},
+			errMsg:  `unsupported cost dimension "unknown"`,
+		},
+		{
+			name: "too many groupings",
+			groupBy: infra_sdk.CostGroupIdentifiers{
+				{Dimension: infra_sdk.UniversalDimensionAccount},
+				{TagKey: infra_sdk.UniversalTagStack},
+				{TagKey: infra_sdk.UniversalTagEnv},
+			},
+			errMsg: "at most 2",
+		},
+		{
+			name: "multiple tag groupings",
+			groupBy: infra_sdk.CostGroupIdentifiers{
+				{TagKey: infra_sdk.UniversalTagStack},
+				{TagKey: infra_sdk.UniversalTagEnv},
+			},
+			errMsg: "at most 1 tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildQueryDefinition(infra_sdk.CostQuery{}, infra_sdk.CostGranularityDaily, tt.groupBy)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestBuildQueries_Metrics(t *testing.T) {
+	queries, err := buildQueries(infra_sdk.CostQuery{
+		Metrics: []string{
+			infra_sdk.UniversalMetricUnblendedCost,
+			infra_sdk.UniversalMetricAmortizedCost,
+			infra_sdk.UniversalMetricUsageQuantity,
+		},
+	}, infra_sdk.CostGranularityDaily, nil)
+	require.NoError(t, err)
+
+	require.Len(t, queries, 2)
+	assert.Equal(t, armcostmanagement.ExportTypeActualCost, *queries[0].Definition.Type)
+	assert.Equal(t, []string{infra_sdk.UniversalMetricUnblendedCost, infra_sdk.UniversalMetricUsageQuantity}, queries[0].Metrics)
+	assert.Equal(t, "Cost", *queries[0].Definition.Dataset.Aggregation["totalCost"].Name)
+	assert.Equal(t, "UsageQuantity", *queries[0].Definition.Dataset.Aggregation["totalUsageQuantity"].Name)
+	assert.Equal(t, armcostmanagement.ExportTypeAmortizedCost, *queries[1].Definition.Type)
+	assert.Equal(t, []string{infra_sdk.UniversalMetricAmortizedCost}, queries[1].Metrics)
+	assert.Len(t, queries[1].Definition.Dataset.Aggregation, 1)
+
+	_, err = buildQueries(infra_sdk.CostQuery{Metrics: []string{infra_sdk.UniversalMetricBlendedCost}}, infra_sdk.CostGranularityDaily, nil)
+	assert.ErrorContains(t, err, `unsupported cost metric "BlendedCost"`)
+}
+
+func TestAddQueryResults_Metrics(t *testing.T) {
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	props := &armcostmanagement.QueryProperties{
+		Columns: []*armcostmanagement.QueryColumn{
+			{Name: ptr("Cost"), Type: ptr("Number")},
+			{Name: ptr("UsageQuantity"), Type: ptr("Number")},
+			{Name: ptr("UsageDate"), Type: ptr("Number")},
+			{Name: ptr("Currency"), Type: ptr("String")},
+		},
+		Rows: [][]any{
+			{1.5, float64(24), float64(20250301), "USD"},
+		},
+	}
+
+	result := infra_sdk.NewCostResult()
+	metrics := []string{infra_sdk.UniversalMetricUnblendedCost, infra_sdk.UniversalMetricUsageQuantity}
+	require.NoError(t, addQueryResults(result, props, metrics, infra_sdk.CostGranularityDaily, nil))
+
+	require.Len(t, result.Series, 2)
+	assert.Equal(t, []infra_sdk.CostSeriesDatapoint{{Start: day1, End: day1.AddDate(0, 0, 1), Unit: "USD", Value: "1.5"}},
+		result.Series[":UnblendedCost"].Points)
+	assert.Equal(t, []infra_sdk.CostSeriesDatapoint{{Start: day1, End: day1.AddDate(0, 0, 1), Unit: "N/A", Value: "24"}},
+		result.Series[":UsageQuantity"].Points)
+}
@@ -0,0 +1,22 @@
+package azure_subscription
+
+func ptr[T any](t T) *T {
+	return &t
+}
+
+func unptr[T any](t *T) T {
+	var result T
+	if t != nil {
+		result = *t
+	}
+	return result
+}
+
+// unptrTags converts azure tags (which have pointer values) into a plain map
+func unptrTags(tags map[string]*string) map[string]string {
+	result := make(map[string]string, len(tags))
+	for k, v := range tags {
+		result[k] = unptr(v)
+	}
+	return result
+}
@@ -0,0 +1,55 @@
+package azure_subscription
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+)
+
+// Config contains everything a ResourceScanner needs to scan a single Azure subscription
+type Config struct {
+	SubscriptionId string
+	Credential     azcore.TokenCredential
+	// ClientOptions are passed to every resource manager client
+	ClientOptions *arm.ClientOptions
+}
+
+type ResourceScanner func(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error)
+
+func NewResourceScanTracker() *ResourceScanTracker {
+	return &ResourceScanTracker{
+		Resources: []infra_sdk.ScanResource{},
+		Errors:    []error{},
+	}
+}
+
+type ResourceScanTracker struct {
+	Resources []infra_sdk.ScanResource
+	Errors    []error
+
+	mu sync.Mutex
+	wg sync.WaitGroup
+}
+
+func (r *ResourceScanTracker) Scan(ctx context.Context, config Config, rs ResourceScanner) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		resources, err := rs(ctx, config)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if err != nil {
+			r.Errors = append(r.Errors, err)
+		}
+		r.Resources = append(r.Resources, resources...)
+	}()
+}
+
+func (r *ResourceScanTracker) Wait() {
+	r.wg.Wait()
+}
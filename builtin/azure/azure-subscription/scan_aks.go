@@ -0,0 +1,71 @@
+package azure_subscription
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+func ScanAksClusters(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	client, err := armcontainerservice.NewManagedClustersClient(config.SubscriptionId, config.Credential, config.ClientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure container service client: %w", err)
+	}
+
+	var resources []infra_sdk.ScanResource
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list AKS clusters: %w", err)
+		}
+
+		for _, cluster := range page.Value {
+			if cluster.ID == nil {
+				continue
+			}
+
+			attrs := map[string]any{}
+			if props := cluster.Properties; props != nil {
+				nodePools := make([]string, 0, len(props.AgentPoolProfiles))
+				nodeCount := int32(0)
+				for _, pool := range props.AgentPoolProfiles {
+					nodePools = append(nodePools, unptr(pool.Name))
+					nodeCount += unptr(pool.Count)
+				}
+				var powerState string
+				if props.PowerState != nil {
+					powerState = string(unptr(props.PowerState.Code))
+				}
+				attrs["provisioning_state"] = unptr(props.ProvisioningState)
+				attrs["power_state"] = powerState
+				attrs["kubernetes_version"] = unptr(props.CurrentKubernetesVersion)
+				attrs["fqdn"] = unptr(props.Fqdn)
+				attrs["node_resource_group"] = unptr(props.NodeResourceGroup)
+				attrs["node_pools"] = nodePools
+				attrs["node_count"] = nodeCount
+			}
+
+			resources = append(resources, infra_sdk.ScanResource{
+				UniqueId: *cluster.ID,
+				Name:     unptr(cluster.Name),
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category:    types.CategoryCluster,
+					Subcategory: "",
+					Platform:    "k8s",
+					Subplatform: "aks",
+					Provider:    "azure",
+				},
+				ServiceName:         "AKS",
+				ServiceResourceName: "Managed Cluster",
+				Region:              unptr(cluster.Location),
+				Attributes:          attrs,
+			}.WithTags(unptrTags(cluster.Tags), azureTagToUniversal))
+		}
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,116 @@
+package azure_subscription
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+// ScanAppServices scans App Service sites (web apps and function apps)
+func ScanAppServices(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	sites, err := listGenericResources(ctx, config, "Microsoft.Web/sites")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list App Service sites: %w", err)
+	}
+
+	resources := make([]infra_sdk.ScanResource, 0, len(sites))
+	for _, site := range sites {
+		// kind is a comma-separated list such as "app,linux,container" or "functionapp,linux"
+		kind := unptr(site.Kind)
+		subcategory, subplatform, serviceResourceName := types.SubcategoryAppServer, "web-app", "Web App"
+		if strings.Contains(kind, "functionapp") {
+			subcategory, subplatform, serviceResourceName = types.SubcategoryAppServerless, "function-app", "Function App"
+		} else if strings.Contains(kind, "container") {
+			subcategory = types.SubcategoryAppContainer
+		}
+
+		resources = append(resources, infra_sdk.ScanResource{
+			UniqueId: *site.ID,
+			Name:     unptr(site.Name),
+			Taxonomy: infra_sdk.ResourceTaxonomy{
+				Category:    types.CategoryApp,
+				Subcategory: subcategory,
+				Platform:    "app-service",
+				Subplatform: subplatform,
+				Provider:    "azure",
+			},
+			ServiceName:         "App Service",
+			ServiceResourceName: serviceResourceName,
+			Region:              unptr(site.Location),
+			Attributes:          genericResourceAttributes(site),
+		}.WithTags(unptrTags(site.Tags), azureTagToUniversal))
+	}
+	return resources, nil
+}
+
+// ScanServiceBusNamespaces scans Service Bus namespaces
+func ScanServiceBusNamespaces(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	namespaces, err := listGenericResources(ctx, config, "Microsoft.ServiceBus/namespaces")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Service Bus namespaces: %w", err)
+	}
+
+	resources := make([]infra_sdk.ScanResource, 0, len(namespaces))
+	for _, ns := range namespaces {
+		resources = append(resources, infra_sdk.ScanResource{
+			UniqueId: *ns.ID,
+			Name:     unptr(ns.Name),
+			Taxonomy: infra_sdk.ResourceTaxonomy{
+				Category:    types.CategoryDatastore,
+				Platform:    "servicebus",
+				Subplatform: "",
+				Provider:    "azure",
+			},
+			ServiceName:         "Service Bus",
+			ServiceResourceName: "Namespace",
+			Region:              unptr(ns.Location),
+			Attributes:          genericResourceAttributes(ns),
+		}.WithTags(unptrTags(ns.Tags), azureTagToUniversal))
+	}
+	return resources, nil
+}
+
+// listGenericResources lists every resource of resourceType in the subscription
+// This is used for services that do not have a dedicated resource manager client in this module
+func listGenericResources(ctx context.Context, config Config, resourceType string) ([]*armresources.GenericResourceExpanded, error) {
+	client, err := armresources.NewClient(config.SubscriptionId, config.Credential, config.ClientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure resources client: %w", err)
+	}
+
+	var result []*armresources.GenericResourceExpanded
+	pager := client.NewListPager(&armresources.ClientListOptions{
+		Filter: ptr(fmt.Sprintf("resourceType eq '%s'", resourceType)),
+		Expand: ptr("createdTime,changedTime,provisioningState"),
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, cur := range page.Value {
+			if cur.ID != nil {
+				result = append(result, cur)
+			}
+		}
+	}
+	return result, nil
+}
+
+func genericResourceAttributes(resource *armresources.GenericResourceExpanded) map[string]any {
+	attrs := map[string]any{
+		"kind":               unptr(resource.Kind),
+		"provisioning_state": unptr(resource.ProvisioningState),
+		"created_time":       resource.CreatedTime,
+		"changed_time":       resource.ChangedTime,
+	}
+	if resource.SKU != nil {
+		attrs["sku"] = unptr(resource.SKU.Name)
+		attrs["sku_tier"] = unptr(resource.SKU.Tier)
+	}
+	return attrs
+}
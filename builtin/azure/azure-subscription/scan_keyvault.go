@@ -0,0 +1,62 @@
+package azure_subscription
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+func ScanKeyVaults(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	client, err := armkeyvault.NewVaultsClient(config.SubscriptionId, config.Credential, config.ClientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure key vault client: %w", err)
+	}
+
+	var resources []infra_sdk.ScanResource
+	pager := client.NewListBySubscriptionPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list key vaults: %w", err)
+		}
+
+		for _, vault := range page.Value {
+			if vault.ID == nil {
+				continue
+			}
+
+			attrs := map[string]any{}
+			if props := vault.Properties; props != nil {
+				if props.SKU != nil {
+					attrs["sku"] = string(unptr(props.SKU.Name))
+				}
+				attrs["vault_uri"] = unptr(props.VaultURI)
+				attrs["tenant_id"] = unptr(props.TenantID)
+				attrs["enable_rbac_authorization"] = unptr(props.EnableRbacAuthorization)
+				attrs["enable_soft_delete"] = unptr(props.EnableSoftDelete)
+				attrs["enable_purge_protection"] = unptr(props.EnablePurgeProtection)
+				attrs["public_network_access"] = unptr(props.PublicNetworkAccess)
+			}
+
+			resources = append(resources, infra_sdk.ScanResource{
+				UniqueId: *vault.ID,
+				Name:     unptr(vault.Name),
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category:    types.CategoryDatastore,
+					Platform:    "key-vault",
+					Subplatform: "",
+					Provider:    "azure",
+				},
+				ServiceName:         "Key Vault",
+				ServiceResourceName: "Vault",
+				Region:              unptr(vault.Location),
+				Attributes:          attrs,
+			}.WithTags(unptrTags(vault.Tags), azureTagToUniversal))
+		}
+	}
+
+	return resources, nil
+}
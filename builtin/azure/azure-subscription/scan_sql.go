@@ -0,0 +1,90 @@
+package azure_subscription
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+func ScanSqlServers(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	client, err := armsql.NewServersClient(config.SubscriptionId, config.Credential, config.ClientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure sql client: %w", err)
+	}
+	dbClient, err := armsql.NewDatabasesClient(config.SubscriptionId, config.Credential, config.ClientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure sql client: %w", err)
+	}
+
+	var resources []infra_sdk.ScanResource
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Azure SQL servers: %w", err)
+		}
+
+		for _, server := range page.Value {
+			if server.ID == nil {
+				continue
+			}
+
+			attrs := map[string]any{
+				"kind": unptr(server.Kind),
+			}
+			if props := server.Properties; props != nil {
+				attrs["version"] = unptr(props.Version)
+				attrs["state"] = unptr(props.State)
+				attrs["fqdn"] = unptr(props.FullyQualifiedDomainName)
+				attrs["minimal_tls_version"] = unptr(props.MinimalTLSVersion)
+				attrs["public_network_access"] = string(unptr(props.PublicNetworkAccess))
+			}
+
+			// Get databases on this server
+			if id, err := arm.ParseResourceID(*server.ID); err == nil {
+				attrs["databases"] = listSqlDatabases(ctx, dbClient, id.ResourceGroupName, unptr(server.Name))
+			}
+
+			resources = append(resources, infra_sdk.ScanResource{
+				UniqueId: *server.ID,
+				Name:     unptr(server.Name),
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category:    types.CategoryDatastore,
+					Subcategory: "",
+					Platform:    "sqlserver",
+					Subplatform: "azure-sql",
+					Provider:    "azure",
+				},
+				ServiceName:         "Azure SQL",
+				ServiceResourceName: "Server",
+				Region:              unptr(server.Location),
+				Attributes:          attrs,
+			}.WithTags(unptrTags(server.Tags), azureTagToUniversal))
+		}
+	}
+
+	return resources, nil
+}
+
+// listSqlDatabases retrieves the names of the user databases on a server
+// Errors are ignored so that a single inaccessible server does not fail the scan
+func listSqlDatabases(ctx context.Context, client *armsql.DatabasesClient, resourceGroup, serverName string) []string {
+	databases := make([]string, 0)
+	pager := client.NewListByServerPager(resourceGroup, serverName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			break
+		}
+		for _, db := range page.Value {
+			if name := unptr(db.Name); name != "" && name != "master" {
+				databases = append(databases, name)
+			}
+		}
+	}
+	return databases
+}
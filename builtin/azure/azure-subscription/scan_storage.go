@@ -0,0 +1,67 @@
+package azure_subscription
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+func ScanStorageAccounts(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	client, err := armstorage.NewAccountsClient(config.SubscriptionId, config.Credential, config.ClientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure storage client: %w", err)
+	}
+
+	var resources []infra_sdk.ScanResource
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list storage accounts: %w", err)
+		}
+
+		for _, account := range page.Value {
+			if account.ID == nil {
+				continue
+			}
+
+			attrs := map[string]any{
+				"kind": string(unptr(account.Kind)),
+			}
+			if account.SKU != nil {
+				attrs["sku"] = string(unptr(account.SKU.Name))
+			}
+			if props := account.Properties; props != nil {
+				attrs["access_tier"] = string(unptr(props.AccessTier))
+				attrs["provisioning_state"] = string(unptr(props.ProvisioningState))
+				attrs["hns_enabled"] = unptr(props.IsHnsEnabled)
+				attrs["allow_blob_public_access"] = unptr(props.AllowBlobPublicAccess)
+				attrs["creation_time"] = props.CreationTime
+				if props.PrimaryEndpoints != nil {
+					attrs["blob_endpoint"] = unptr(props.PrimaryEndpoints.Blob)
+					attrs["web_endpoint"] = unptr(props.PrimaryEndpoints.Web)
+				}
+			}
+
+			resources = append(resources, infra_sdk.ScanResource{
+				UniqueId: *account.ID,
+				Name:     unptr(account.Name),
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category:    types.CategoryDatastore,
+					Platform:    "storage-account",
+					Subplatform: "",
+					Provider:    "azure",
+				},
+				ServiceName:         "Storage",
+				ServiceResourceName: "Storage Account",
+				Region:              unptr(account.Location),
+				Attributes:          attrs,
+			}.WithTags(unptrTags(account.Tags), azureTagToUniversal))
+		}
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,64 @@
+package azure_subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/nullstone-io/infra-sdk"
+)
+
+var (
+	AllScanners = []ResourceScanner{
+		// cluster
+		ScanAksClusters,
+
+		// app
+		ScanAppServices,
+
+		// datastore
+		ScanSqlServers,
+		ScanStorageAccounts,
+		ScanServiceBusNamespaces,
+		ScanKeyVaults,
+	}
+)
+
+var (
+	_ infra_sdk.Scanner = Scanner{}
+)
+
+type Scanner struct {
+	Accessor infra_sdk.AzureAccessor
+	// ClientOptions are passed to every resource manager client
+	ClientOptions *arm.ClientOptions
+}
+
+func (s Scanner) Scan(ctx context.Context) ([]infra_sdk.ScanResource, error) {
+	if s.Accessor == nil {
+		return nil, nil
+	}
+	credential, err := s.Accessor.GetCredential(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving azure credentials: %w", err)
+	}
+	if credential == nil {
+		return nil, nil
+	}
+
+	config := Config{
+		SubscriptionId: s.Accessor.AzureSubscriptionId(),
+		Credential:     credential,
+		ClientOptions:  s.ClientOptions,
+	}
+	tracker := NewResourceScanTracker()
+	for _, scanner := range AllScanners {
+		tracker.Scan(ctx, config, scanner)
+	}
+	tracker.Wait()
+	if len(tracker.Errors) > 0 {
+		err = errors.Join(tracker.Errors...)
+	}
+	return tracker.Resources, err
+}
@@ -0,0 +1,308 @@
+package azure_subscription
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+const testSubscription = "/subscriptions/sub"
+
+type testCredential struct{}
+
+func (testCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+type testAccessor struct{}
+
+func (testAccessor) GetCredential(ctx context.Context) (azcore.TokenCredential, error) {
+	return testCredential{}, nil
+}
+
+func (testAccessor) AzureSubscriptionId() string { return "sub" }
+
+// testTransport sends every request to target instead of the resource manager endpoint
+type testTransport struct {
+	target *url.URL
+}
+
+func (t testTransport) Do(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultClient.Do(req)
+}
+
+// armServer responds to resource manager list requests with canned json keyed by path
+// Generic resource requests are keyed by the resource type in the filter
+func newArmServer(t *testing.T, responses map[string]string) *arm.ClientOptions {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Path
+		if filter := r.URL.Query().Get("$filter"); filter != "" {
+			key += "?" + filter
+		}
+		body, ok := responses[key]
+		if !ok {
+			body = `{"value":[]}`
+		}
+		if body == "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":{"code":"InternalError","message":"boom"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(ts.Close)
+	target, _ := url.Parse(ts.URL)
+	return &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: testTransport{target: target},
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	}
+}
+
+func TestResourceScanners(t *testing.T) {
+	tests := []struct {
+		name      string
+		scanner   ResourceScanner
+		responses map[string]string
+		want      infra_sdk.ScanResource
+	}{
+		{
+			name:    "aks",
+			scanner: ScanAksClusters,
+			responses: map[string]string{
+				testSubscription + "/providers/Microsoft.ContainerService/managedClusters": `{"value":[{
+					"id": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/k8s",
+					"name": "k8s", "location": "eastus", "tags": {"Stack": "core", "env": "prod"},
+					"properties": {
+						"provisioningState": "Succeeded", "powerState": {"code": "Running"},
+						"currentKubernetesVersion": "1.30.0", "fqdn": "k8s.hcp.eastus.azmk8s.io", "nodeResourceGroup": "MC_rg",
+						"agentPoolProfiles": [{"name": "system", "count": 2}, {"name": "user", "count": 3}]
+					}
+				}]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/k8s",
+				Name:     "k8s",
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category: types.CategoryCluster, Platform: "k8s", Subplatform: "aks", Provider: "azure",
+				},
+				ServiceName:         "AKS",
+				ServiceResourceName: "Managed Cluster",
+				Region:              "eastus",
+				Attributes: map[string]any{
+					"provisioning_state":  "Succeeded",
+					"power_state":         "Running",
+					"kubernetes_version":  "1.30.0",
+					"fqdn":                "k8s.hcp.eastus.azmk8s.io",
+					"node_resource_group": "MC_rg",
+					"node_pools":          []string{"system", "user"},
+					"node_count":          int32(5),
+				},
+				Tags:      map[string]string{infra_sdk.UniversalTagStack: "core", "env": "prod"},
+				Ownership: infra_sdk.Ownership{Stack: "core"},
+			},
+		},
+		{
+			name:    "function app",
+			scanner: ScanAppServices,
+			responses: map[string]string{
+				testSubscription + "/resources?resourceType eq 'Microsoft.Web/sites'": `{"value":[{
+					"id": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Web/sites/fn",
+					"name": "fn", "location": "westus", "kind": "functionapp,linux", "provisioningState": "Succeeded",
+					"sku": {"name": "Y1", "tier": "Dynamic"}
+				}]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Web/sites/fn",
+				Name:     "fn",
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category: types.CategoryApp, Subcategory: types.SubcategoryAppServerless,
+					Platform: "app-service", Subplatform: "function-app", Provider: "azure",
+				},
+				ServiceName:         "App Service",
+				ServiceResourceName: "Function App",
+				Region:              "westus",
+				Attributes: map[string]any{
+					"kind":               "functionapp,linux",
+					"provisioning_state": "Succeeded",
+					"created_time":       (*time.Time)(nil),
+					"changed_time":       (*time.Time)(nil),
+					"sku":                "Y1",
+					"sku_tier":           "Dynamic",
+				},
+				Tags: map[string]string{},
+			},
+		},
+		{
+			name:    "service bus",
+			scanner: ScanServiceBusNamespaces,
+			responses: map[string]string{
+				testSubscription + "/resources?resourceType eq 'Microsoft.ServiceBus/namespaces'": `{"value":[{
+					"id": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ServiceBus/namespaces/bus",
+					"name": "bus", "location": "westus"
+				}]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ServiceBus/namespaces/bus",
+				Name:     "bus",
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category: types.CategoryDatastore, Platform: "servicebus", Provider: "azure",
+				},
+				ServiceName:         "Service Bus",
+				ServiceResourceName: "Namespace",
+				Region:              "westus",
+				Attributes: map[string]any{
+					"kind":               "",
+					"provisioning_state": "",
+					"created_time":       (*time.Time)(nil),
+					"changed_time":       (*time.Time)(nil),
+				},
+				Tags: map[string]string{},
+			},
+		},
+		{
+			name:    "sql server",
+			scanner: ScanSqlServers,
+			responses: map[string]string{
+				testSubscription + "/providers/Microsoft.Sql/servers": `{"value":[{
+					"id": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Sql/servers/db",
+					"name": "db", "location": "eastus", "kind": "v12.0",
+					"properties": {"version": "12.0", "state": "Ready", "fullyQualifiedDomainName": "db.database.windows.net", "minimalTlsVersion": "1.2", "publicNetworkAccess": "Disabled"}
+				}]}`,
+				testSubscription + "/resourceGroups/rg/providers/Microsoft.Sql/servers/db/databases": `{"value":[{"name": "master"}, {"name": "app"}]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Sql/servers/db",
+				Name:     "db",
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category: types.CategoryDatastore, Platform: "sqlserver", Subplatform: "azure-sql", Provider: "azure",
+				},
+				ServiceName:         "Azure SQL",
+				ServiceResourceName: "Server",
+				Region:              "eastus",
+				Attributes: map[string]any{
+					"kind":                  "v12.0",
+					"version":               "12.0",
+					"state":                 "Ready",
+					"fqdn":                  "db.database.windows.net",
+					"minimal_tls_version":   "1.2",
+					"public_network_access": "Disabled",
+					"databases":             []string{"app"},
+				},
+				Tags: map[string]string{},
+			},
+		},
+		{
+			name:    "storage account",
+			scanner: ScanStorageAccounts,
+			responses: map[string]string{
+				testSubscription + "/providers/Microsoft.Storage/storageAccounts": `{"value":[{
+					"id": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/files",
+					"name": "files", "location": "eastus", "kind": "StorageV2", "sku": {"name": "Standard_LRS"},
+					"properties": {
+						"accessTier": "Hot", "provisioningState": "Succeeded", "isHnsEnabled": true, "allowBlobPublicAccess": false,
+						"primaryEndpoints": {"blob": "https://files.blob.core.windows.net/", "web": "https://files.z13.web.core.windows.net/"}
+					}
+				}]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/files",
+				Name:     "files",
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category: types.CategoryDatastore, Platform: "storage-account", Provider: "azure",
+				},
+				ServiceName:         "Storage",
+				ServiceResourceName: "Storage Account",
+				Region:              "eastus",
+				Attributes: map[string]any{
+					"kind":                     "StorageV2",
+					"sku":                      "Standard_LRS",
+					"access_tier":              "Hot",
+					"provisioning_state":       "Succeeded",
+					"hns_enabled":              true,
+					"allow_blob_public_access": false,
+					"creation_time":            (*time.Time)(nil),
+					"blob_endpoint":            "https://files.blob.core.windows.net/",
+					"web_endpoint":             "https://files.z13.web.core.windows.net/",
+				},
+				Tags: map[string]string{},
+			},
+		},
+		{
+			name:    "key vault",
+			scanner: ScanKeyVaults,
+			responses: map[string]string{
+				testSubscription + "/providers/Microsoft.KeyVault/vaults": `{"value":[{
+					"id": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/secrets",
+					"name": "secrets", "location": "eastus",
+					"properties": {
+						"sku": {"family": "A", "name": "standard"}, "tenantId": "tenant", "vaultUri": "https://secrets.vault.azure.net/",
+						"enableRbacAuthorization": true, "enableSoftDelete": true, "enablePurgeProtection": false, "publicNetworkAccess": "Enabled"
+					}
+				}]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/secrets",
+				Name:     "secrets",
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category: types.CategoryDatastore, Platform: "key-vault", Provider: "azure",
+				},
+				ServiceName:         "Key Vault",
+				ServiceResourceName: "Vault",
+				Region:              "eastus",
+				Attributes: map[string]any{
+					"sku":                       "standard",
+					"vault_uri":                 "https://secrets.vault.azure.net/",
+					"tenant_id":                 "tenant",
+					"enable_rbac_authorization": true,
+					"enable_soft_delete":        true,
+					"enable_purge_protection":   false,
+					"public_network_access":     "Enabled",
+				},
+				Tags: map[string]string{},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := Config{SubscriptionId: "sub", Credential: testCredential{}, ClientOptions: newArmServer(t, test.responses)}
+			resources, err := test.scanner(context.Background(), config)
+			require.NoError(t, err)
+			require.Len(t, resources, 1)
+			assert.Equal(t, test.want, resources[0])
+		})
+	}
+}
+
+func TestScanner_Scan(t *testing.T) {
+	scanner := Scanner{
+		Accessor: testAccessor{},
+		ClientOptions: newArmServer(t, map[string]string{
+			testSubscription + "/providers/Microsoft.KeyVault/vaults":         `{"value":[{"id": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/secrets", "name": "secrets"}]}`,
+			testSubscription + "/providers/Microsoft.Storage/storageAccounts": "",
+		}),
+	}
+
+	// A failing scanner does not prevent the other scanners from reporting resources
+	resources, err := scanner.Scan(context.Background())
+	assert.ErrorContains(t, err, "failed to list storage accounts")
+	require.Len(t, resources, 1)
+	assert.Equal(t, "secrets", resources[0].Name)
+}
@@ -0,0 +1,86 @@
+package azure_subscription
+
+import infra_sdk "github.com/nullstone-io/infra-sdk"
+
+// AzureDimension is a dimension in the Cost Management Query API
+type AzureDimension string
+
+func (d AzureDimension) ToUniversal() string {
+	switch d {
+	case "SubscriptionId":
+		return infra_sdk.UniversalDimensionAccount
+	case "ResourceId":
+		return infra_sdk.UniversalDimensionResourceId
+	case "ServiceName":
+		return infra_sdk.UniversalDimensionService
+	case "ResourceLocation":
+		return infra_sdk.UniversalDimensionRegion
+	case "Meter":
+		return infra_sdk.UniversalDimensionUsageType
+	case "ChargeType":
+		return infra_sdk.UniversalDimensionChargeType
+	case "PricingModel":
+		return infra_sdk.UniversalDimensionPurchaseOption
+	}
+	return string(d)
+}
+
+type UniversalDimension string
+
+// ToAzure maps a universal dimension to its Cost Management Query API dimension
+// An empty string is returned if the dimension is not supported
+// The Query API has no equivalent for operation
+func (d UniversalDimension) ToAzure() string {
+	switch d {
+	case infra_sdk.UniversalDimensionAccount:
+		return "SubscriptionId"
+	case infra_sdk.UniversalDimensionResourceId:
+		return "ResourceId"
+	case infra_sdk.UniversalDimensionService:
+		return "ServiceName"
+	case infra_sdk.UniversalDimensionRegion:
+		return "ResourceLocation"
+	case infra_sdk.UniversalDimensionUsageType:
+		return "Meter"
+	case infra_sdk.UniversalDimensionChargeType:
+		return "ChargeType"
+	case infra_sdk.UniversalDimensionPurchaseOption:
+		return "PricingModel"
+	}
+	return ""
+}
+
+// AzureTag is a tag name on an Azure resource
+// Azure tag names do not allow '/', so nullstone tags are stored without the "nullstone.io/" prefix
+type AzureTag string
+
+func (t AzureTag) ToUniversal() string {
+	switch t {
+	case "Stack":
+		return infra_sdk.UniversalTagStack
+	case "Env":
+		return infra_sdk.UniversalTagEnv
+	case "Block":
+		return infra_sdk.UniversalTagBlock
+	}
+	return string(t)
+}
+
+// azureTagToUniversal adapts AzureTag.ToUniversal for ScanResource.WithTags
+func azureTagToUniversal(key string) string {
+	return AzureTag(key).ToUniversal()
+}
+
+type UniversalTag string
+
+func (t UniversalTag) ToAzure() string {
+	switch t {
+	case infra_sdk.UniversalTagStack:
+		return "Stack"
+	case infra_sdk.UniversalTagEnv:
+		return "Env"
+	case infra_sdk.UniversalTagBlock:
+		return "Block"
+	}
+	return string(t)
+}
@@ -0,0 +1,26 @@
+package azure_subscription
+
+import (
+	"testing"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniversalDimension(t *testing.T) {
+	tests := map[string]string{
+		infra_sdk.UniversalDimensionAccount:        "SubscriptionId",
+		infra_sdk.UniversalDimensionService:        "ServiceName",
+		infra_sdk.UniversalDimensionRegion:         "ResourceLocation",
+		infra_sdk.UniversalDimensionUsageType:      "Meter",
+		infra_sdk.UniversalDimensionResourceId:     "ResourceId",
+		infra_sdk.UniversalDimensionChargeType:     "ChargeType",
+		infra_sdk.UniversalDimensionPurchaseOption: "PricingModel",
+	}
+	for universal, azure := range tests {
+		assert.Equal(t, azure, UniversalDimension(universal).ToAzure(), universal)
+		assert.Equal(t, universal, AzureDimension(azure).ToUniversal(), azure)
+	}
+
+	assert.Empty(t, UniversalDimension(infra_sdk.UniversalDimensionOperation).ToAzure())
+}
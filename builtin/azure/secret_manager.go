@@ -0,0 +1,164 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+var (
+	_ infra_sdk.SecretManager = SecretManager{}
+
+	// ErrSecretDeleted is returned when writing a secret that is soft-deleted in the key vault
+	ErrSecretDeleted = errors.New("secret is deleted but recoverable, recover or purge it before writing")
+)
+
+type SecretManager struct {
+	Accessor infra_sdk.AzureAccessor
+	// ClientOptions are passed to the key vault client
+	ClientOptions *azsecrets.ClientOptions
+}
+
+func (s SecretManager) List(ctx context.Context, location types.SecretLocation) ([]types.Secret, error) {
+	client, err := s.kvClient(ctx, location.AzureVaultName)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, nil
+	}
+
+	result := make([]types.Secret, 0)
+	pager := client.NewListSecretPropertiesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing secrets: %w", err)
+		}
+		for _, cur := range page.Value {
+			if cur.ID == nil {
+				continue
+			}
+			result = append(result, types.Secret{
+				Identity: types.SecretIdentity{
+					Name: cur.ID.Name(),
+					SecretLocation: types.SecretLocation{
+						Platform:       types.SecretLocationPlatformAzure,
+						AzureVaultName: location.AzureVaultName,
+					},
+				},
+				Metadata: map[string]any{
+					"content_type": cur.ContentType,
+					"tags":         cur.Tags,
+				},
+				Value:    "",
+				Redacted: true,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (s SecretManager) Create(ctx context.Context, identity types.SecretIdentity, value string) (*types.Secret, error) {
+	client, err := s.kvClient(ctx, identity.AzureVaultName)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, nil
+	}
+
+	// SetSecret creates a new version if the secret already exists, check for an existing secret first
+	// A soft-deleted secret is not found here, SetSecret reports it as a conflict
+	if _, err := client.GetSecret(ctx, identity.Name, "", nil); err == nil {
+		return nil, infra_sdk.ErrSecretAlreadyExists
+	} else if !isNotFound(err) {
+		return nil, fmt.Errorf("error creating secret: %w", err)
+	}
+
+	return s.setSecret(ctx, client, identity, value)
+}
+
+func (s SecretManager) Update(ctx context.Context, identity types.SecretIdentity, value string) (*types.Secret, error) {
+	client, err := s.kvClient(ctx, identity.AzureVaultName)
+	if err != nil {
+		return nil, err
+	} else if client == nil {
+		return nil, nil
+	}
+
+	// SetSecret creates the secret if it does not exist, check for an existing secret first
+	if _, err := client.GetSecret(ctx, identity.Name, "", nil); err != nil {
+		if isNotFound(err) {
+			return nil, infra_sdk.ErrDoesNotExist
+		}
+		return nil, fmt.Errorf("error updating secret: %w", err)
+	}
+
+	return s.setSecret(ctx, client, identity, value)
+}
+
+// setSecret writes value as the latest version of the secret
+// Key Vault has no conditional writes, so a secret created by someone else after the existence check is overwritten
+// A soft-deleted secret cannot be written until it is recovered or purged; this is reported as ErrSecretDeleted
+func (s SecretManager) setSecret(ctx context.Context, client *azsecrets.Client, identity types.SecretIdentity, value string) (*types.Secret, error) {
+	out, err := client.SetSecret(ctx, identity.Name, azsecrets.SetSecretParameters{Value: &value}, nil)
+	if err != nil {
+		if isConflict(err) {
+			return nil, fmt.Errorf("error setting secret %q: %w", identity.Name, ErrSecretDeleted)
+		}
+		return nil, fmt.Errorf("error setting secret: %w", err)
+	}
+
+	identity.Platform = types.SecretLocationPlatformAzure
+	if out.ID != nil {
+		identity.AzureSecretVersion = out.ID.Version()
+	}
+	return &types.Secret{
+		Identity: identity,
+		Metadata: nil,
+		Value:    "",
+		Redacted: false,
+	}, nil
+}
+
+func (s SecretManager) kvClient(ctx context.Context, vaultName string) (*azsecrets.Client, error) {
+	if s.Accessor == nil {
+		return nil, nil
+	}
+	if vaultName == "" {
+		return nil, fmt.Errorf("an azure key vault name is required")
+	}
+	credential, err := s.Accessor.GetCredential(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving azure credentials: %w", err)
+	}
+	if credential == nil {
+		return nil, nil
+	}
+
+	vaultUrl := fmt.Sprintf("https://%s.vault.azure.net/", vaultName)
+	client, err := azsecrets.NewClient(vaultUrl, credential, s.ClientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure key vault client: %w", err)
+	}
+	return client, nil
+}
+
+func isNotFound(err error) bool {
+	var re *azcore.ResponseError
+	return errors.As(err, &re) && re.StatusCode == http.StatusNotFound
+}
+
+// isConflict reports whether Key Vault rejected a write with 409 Conflict
+// SetSecret only conflicts when the secret is soft-deleted (ObjectIsDeletedButRecoverable) or is being recovered
+func isConflict(err error) bool {
+	var re *azcore.ResponseError
+	return errors.As(err, &re) && re.StatusCode == http.StatusConflict
+}
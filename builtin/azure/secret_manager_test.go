@@ -0,0 +1,153 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+type testCredential struct{}
+
+func (testCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+type testAccessor struct{}
+
+func (testAccessor) GetCredential(ctx context.Context) (azcore.TokenCredential, error) {
+	return testCredential{}, nil
+}
+
+func (testAccessor) AzureSubscriptionId() string { return "subscription" }
+
+// testTransport sends every request to target while keeping the original host for challenge verification
+type testTransport struct {
+	target *url.URL
+}
+
+func (t testTransport) Do(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultClient.Do(req)
+}
+
+// vaultServer is a minimal implementation of the key vault secrets api used by SecretManager
+type vaultServer struct {
+	mu       sync.Mutex
+	versions map[string]int
+	deleted  map[string]bool
+}
+
+func (s *vaultServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The first request is sent without a token to discover the authorization challenge
+	if r.Header.Get("Authorization") == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	name, ok := strings.CutPrefix(r.URL.Path, "/secrets/")
+	if !ok {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method == http.MethodGet {
+		// A soft-deleted secret is only visible through the deleted secrets api
+		name = strings.TrimSuffix(name, "/")
+		if s.versions[name] == 0 || s.deleted[name] {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error":{"code":"SecretNotFound","message":"A secret with (name/id) %s was not found in this key vault"}}`, name)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"value": "value",
+			"id":    fmt.Sprintf("https://%s/secrets/%s/v%d", r.Host, name, s.versions[name]),
+		})
+		return
+	}
+	if r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if s.deleted[name] {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error":{"code":"Conflict","message":"Secret %s is currently in a deleted but recoverable state","innererror":{"code":"ObjectIsDeletedButRecoverable"}}}`, name)
+		return
+	}
+	var params struct{ Value string }
+	json.NewDecoder(r.Body).Decode(&params)
+	s.versions[name]++
+	json.NewEncoder(w).Encode(map[string]any{
+		"value": params.Value,
+		"id":    fmt.Sprintf("https://%s/secrets/%s/v%d", r.Host, name, s.versions[name]),
+	})
+}
+
+func TestSecretManager_Write(t *testing.T) {
+	ctx := context.Background()
+	server := &vaultServer{versions: map[string]int{"existing": 1}, deleted: map[string]bool{"deleted": true}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	target, _ := url.Parse(ts.URL)
+
+	sm := SecretManager{
+		Accessor: testAccessor{},
+		ClientOptions: &azsecrets.ClientOptions{
+			ClientOptions: azcore.ClientOptions{Transport: testTransport{target: target}},
+		},
+	}
+	identity := func(name string) types.SecretIdentity {
+		return types.SecretIdentity{Name: name, SecretLocation: types.SecretLocation{AzureVaultName: "vault"}}
+	}
+
+	tests := []struct {
+		name    string
+		write   func(ctx context.Context, identity types.SecretIdentity, value string) (*types.Secret, error)
+		secret  string
+		version string
+		err     error
+	}{
+		{name: "create new", write: sm.Create, secret: "new", version: "v1"},
+		{name: "create existing", write: sm.Create, secret: "existing", err: infra_sdk.ErrSecretAlreadyExists},
+		{name: "create deleted", write: sm.Create, secret: "deleted", err: ErrSecretDeleted},
+		{name: "update existing", write: sm.Update, secret: "existing", version: "v2"},
+		{name: "update missing", write: sm.Update, secret: "missing", err: infra_sdk.ErrDoesNotExist},
+		{name: "update deleted", write: sm.Update, secret: "deleted", err: infra_sdk.ErrDoesNotExist},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			secret, err := test.write(ctx, identity(test.secret), "value")
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.secret, secret.Identity.Name)
+			assert.Equal(t, types.SecretLocationPlatformAzure, secret.Identity.Platform)
+			assert.Equal(t, test.version, secret.Identity.AzureSecretVersion)
+		})
+	}
+
+	// Failed writes do not create secrets
+	assert.NotContains(t, server.versions, "missing")
+}
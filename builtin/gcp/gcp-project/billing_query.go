@@ -0,0 +1,157 @@
+package gcp_project
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+const (
+	// queryPollInitialInterval is the delay before polling an incomplete query job again; it doubles up to queryPollMaxInterval
+	queryPollInitialInterval = 250 * time.Millisecond
+	queryPollMaxInterval     = 5 * time.Second
+)
+
+// BillingQuery is a GoogleSQL query against the Cloud Billing BigQuery export with named parameters
+type BillingQuery struct {
+	Sql        string
+	Parameters []BillingQueryParameter
+}
+
+// BillingQueryParameter is a named query parameter
+// If Values is populated, the parameter is an ARRAY of Type; otherwise, Value is used
+type BillingQueryParameter struct {
+	Name   string
+	Type   string
+	Value  string
+	Values []string
+}
+
+// BillingRow is a single result row keyed by column name
+// Every value is returned in its string form; NULL values are returned as empty strings
+type BillingRow map[string]string
+
+// BillingQueryRunner executes a BillingQuery
+// This allows Coster to be tested against a local stub instead of BigQuery
+type BillingQueryRunner interface {
+	RunQuery(ctx context.Context, query BillingQuery) ([]BillingRow, error)
+}
+
+var (
+	_ BillingQueryRunner = BigQueryRunner{}
+)
+
+// BigQueryRunner runs billing queries as BigQuery jobs in the accessor's project
+type BigQueryRunner struct {
+	Accessor infra_sdk.GcpAccessor
+}
+
+func (r BigQueryRunner) RunQuery(ctx context.Context, query BillingQuery) ([]BillingRow, error) {
+	tokenSource, err := r.Accessor.GetTokenSource(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving gcp credentials: %w", err)
+	}
+	client, err := bigquery.NewService(ctx, option.WithTokenSource(tokenSource))
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcp bigquery client: %w", err)
+	}
+
+	projectId := r.Accessor.GcpProjectId()
+	useLegacySql := false
+	out, err := client.Jobs.Query(projectId, &bigquery.QueryRequest{
+		Query:           query.Sql,
+		UseLegacySql:    &useLegacySql,
+		ParameterMode:   "NAMED",
+		QueryParameters: toBigQueryParameters(query.Parameters),
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	// The initial response may not include every row (or any rows if the job has not completed yet)
+	// Poll/page through the job's results until every row has been collected
+	schema := out.Schema
+	rows := appendBillingRows(make([]BillingRow, 0), schema, out.Rows)
+	complete, pageToken := out.JobComplete, out.PageToken
+	pollInterval := queryPollInitialInterval
+	for !complete || pageToken != "" {
+		if out.JobReference == nil {
+			return nil, fmt.Errorf("query did not complete and no job reference was returned")
+		}
+		call := client.Jobs.GetQueryResults(projectId, out.JobReference.JobId).Location(out.JobReference.Location)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		res, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		if !res.JobComplete {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(pollInterval):
+			}
+			pollInterval = min(pollInterval*2, queryPollMaxInterval)
+			continue
+		}
+		if res.Schema != nil {
+			schema = res.Schema
+		}
+		rows = appendBillingRows(rows, schema, res.Rows)
+		complete, pageToken = true, res.PageToken
+	}
+	return rows, nil
+}
+
+func toBigQueryParameters(params []BillingQueryParameter) []*bigquery.QueryParameter {
+	result := make([]*bigquery.QueryParameter, 0, len(params))
+	for _, param := range params {
+		if param.Values != nil {
+			values := make([]*bigquery.QueryParameterValue, 0, len(param.Values))
+			for _, v := range param.Values {
+				values = append(values, &bigquery.QueryParameterValue{Value: v})
+			}
+			result = append(result, &bigquery.QueryParameter{
+				Name: param.Name,
+				ParameterType: &bigquery.QueryParameterType{
+					Type:      "ARRAY",
+					ArrayType: &bigquery.QueryParameterType{Type: param.Type},
+				},
+				ParameterValue: &bigquery.QueryParameterValue{ArrayValues: values},
+			})
+		} else {
+			result = append(result, &bigquery.QueryParameter{
+				Name:           param.Name,
+				ParameterType:  &bigquery.QueryParameterType{Type: param.Type},
+				ParameterValue: &bigquery.QueryParameterValue{Value: param.Value},
+			})
+		}
+	}
+	return result
+}
+
+func appendBillingRows(rows []BillingRow, schema *bigquery.TableSchema, tableRows []*bigquery.TableRow) []BillingRow {
+	if schema == nil {
+		return rows
+	}
+	for _, tableRow := range tableRows {
+		row := BillingRow{}
+		for i, cell := range tableRow.F {
+			if i >= len(schema.Fields) {
+				break
+			}
+			if s, ok := cell.V.(string); ok {
+				row[schema.Fields[i].Name] = s
+			} else {
+				row[schema.Fields[i].Name] = ""
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -0,0 +1,281 @@
+package gcp_project
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+)
+
+const (
+	// usageQuantityUnit is reported for usage quantities since they are summed across SKUs with different pricing units
+	// This matches the unit that Cost Explorer reports for UsageQuantity across mixed usage types
+	usageQuantityUnit = "N/A"
+
+	billingTimestampFormat = "2006-01-02 15:04:05.999999-07:00"
+)
+
+var (
+	// granularityMappings maps a granularity to the TIMESTAMP_TRUNC date part
+	granularityMappings = map[infra_sdk.CostGranularity]string{
+		infra_sdk.CostGranularityHourly:  "HOUR",
+		infra_sdk.CostGranularityDaily:   "DAY",
+		infra_sdk.CostGranularityMonthly: "MONTH",
+	}
+
+	// metricMappings maps a universal metric to an aggregate over the billing export
+	// The `cost` column is the usage cost before credits, which is the equivalent of unblended cost
+	// Credits are negative amounts, so adding them yields the equivalent of net unblended cost
+	// The standard export does not amortize commitments, so amortized and blended metrics are not supported
+	metricMappings = map[string]billingMetric{
+		infra_sdk.UniversalMetricUnblendedCost: {
+			Column:     "cost",
+			Expression: "SUM(cost)",
+		},
+		infra_sdk.UniversalMetricNetUnblendedCost: {
+			Column:     "net_cost",
+			Expression: "SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) AS c), 0))",
+		},
+		infra_sdk.UniversalMetricUsageQuantity: {
+			Column:     "usage_quantity",
+			Expression: "SUM(usage.amount_in_pricing_units)",
+			IsUsage:    true,
+		},
+	}
+
+	validTableName = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+)
+
+var (
+	_ infra_sdk.Coster = Coster{}
+)
+
+// billingMetric is an aggregate over the billing export that computes a universal metric
+type billingMetric struct {
+	// Column is the alias of the aggregate in the results
+	Column     string
+	Expression string
+	// IsUsage is true if the metric is a usage quantity instead of a cost in the billing currency
+	IsUsage bool
+}
+
+// Coster queries costs from the standard Cloud Billing export in BigQuery
+// See https://cloud.google.com/billing/docs/how-to/export-data-bigquery-tables/standard-usage
+type Coster struct {
+	Accessor infra_sdk.GcpAccessor
+	// BillingExportTable is the fully-qualified table that contains the billing export
+	// e.g. "my-project.billing.gcp_billing_export_v1_XXXXXX_XXXXXX_XXXXXX"
+	BillingExportTable string
+	// QueryRunner executes the billing query
+	// If nil, the query is run as a BigQuery job in the accessor's project
+	QueryRunner BillingQueryRunner
+}
+
+func (c Coster) GetCosts(ctx context.Context, query infra_sdk.CostQuery) (*infra_sdk.CostResult, error) {
+	runner := c.QueryRunner
+	if runner == nil {
+		if c.Accessor == nil {
+			return nil, nil
+		}
+		runner = BigQueryRunner{Accessor: c.Accessor}
+	}
+
+	granularity := query.Granularity
+	if _, ok := granularityMappings[granularity]; !ok {
+		granularity = infra_sdk.CostGranularityDaily
+	}
+
+	groupBy := query.GroupBy.Unique()
+	billingQuery, err := c.buildQuery(query, granularity, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := runner.RunQuery(ctx, billingQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error querying gcp billing export: %w", err)
+	}
+
+	// Rows are grouped by currency, so a series can have more than one row for the same period
+	// These rows are summed into a single datapoint
+	points := make([]billingPoint, 0, len(rows))
+	pointIdxs := map[string]int{}
+	for _, row := range rows {
+		start, err := parseUsageWindow(row["usage_window"])
+		if err != nil {
+			return nil, fmt.Errorf("error parsing result: %w", err)
+		}
+		groupKeys := parseResultGroupKeys(groupBy, row)
+		for _, metricName := range query.UniqueMetrics() {
+			metric := metricMappings[metricName]
+			unit := row["currency"]
+			if metric.IsUsage {
+				unit = usageQuantityUnit
+			}
+			key := fmt.Sprintf("%s:%s:%d", groupKeys.UniqueIdentifier(), metricName, start.Unix())
+			idx, ok := pointIdxs[key]
+			if !ok {
+				pointIdxs[key] = len(points)
+				points = append(points, billingPoint{
+					MetricName: metricName,
+					GroupKeys:  groupKeys,
+					Datapoint: infra_sdk.CostSeriesDatapoint{
+						Start: start,
+						End:   windowEnd(start, granularity),
+						Unit:  unit,
+						Value: formatCost(row[metric.Column]),
+					},
+				})
+				continue
+			}
+			existing := &points[idx].Datapoint
+			if existing.Unit != unit {
+				return nil, fmt.Errorf("error parsing result: %s has costs in more than one currency (%s, %s)", metricName, existing.Unit, unit)
+			}
+			if existing.Value, err = sumCosts(existing.Value, row[metric.Column]); err != nil {
+				return nil, fmt.Errorf("error parsing result: %w", err)
+			}
+		}
+	}
+
+	result := infra_sdk.NewCostResult()
+	for _, point := range points {
+		result.AddDatapoint(point.MetricName, point.GroupKeys, point.Datapoint)
+	}
+	return result, nil
+}
+
+// billingPoint is a datapoint for a single series that is accumulated from the billing export rows
+type billingPoint struct {
+	MetricName string
+	GroupKeys  infra_sdk.CostSeriesGroupKeys
+	Datapoint  infra_sdk.CostSeriesDatapoint
+}
+
+// buildQuery translates a CostQuery into a query against the billing export
+// Every value that comes from the CostQuery is passed as a query parameter
+func (c Coster) buildQuery(query infra_sdk.CostQuery, granularity infra_sdk.CostGranularity, groupBy infra_sdk.CostGroupIdentifiers) (BillingQuery, error) {
+	if !validTableName.MatchString(c.BillingExportTable) {
+		return BillingQuery{}, fmt.Errorf("invalid billing export table %q", c.BillingExportTable)
+	}
+	if query.Filter != nil {
+		return BillingQuery{}, fmt.Errorf("cost filter expressions are not supported for gcp billing exports, use filter tags instead")
+	}
+
+	params := []BillingQueryParameter{
+		{Name: "start", Type: "TIMESTAMP", Value: query.Start.UTC().Format(billingTimestampFormat)},
+		{Name: "end", Type: "TIMESTAMP", Value: query.End.UTC().Format(billingTimestampFormat)},
+	}
+
+	columns := []string{
+		fmt.Sprintf("UNIX_SECONDS(TIMESTAMP_TRUNC(usage_start_time, %s, 'UTC')) AS usage_window", granularityMappings[granularity]),
+	}
+	groupColumns := []string{"usage_window"}
+	for i, cur := range groupBy {
+		alias := fmt.Sprintf("group_%d", i)
+		if cur.Dimension != "" {
+			column := UniversalDimension(cur.Dimension).ToGcp()
+			if column == "" {
+				return BillingQuery{}, fmt.Errorf("unsupported cost dimension %q", cur.Dimension)
+			}
+			columns = append(columns, fmt.Sprintf("%s AS %s", column, alias))
+		} else {
+			keyParam := alias + "_key"
+			params = append(params, BillingQueryParameter{Name: keyParam, Type: "STRING", Value: UniversalTag(cur.TagKey).ToGcp()})
+			columns = append(columns, fmt.Sprintf("(SELECT l.value FROM UNNEST(labels) AS l WHERE l.key = @%s LIMIT 1) AS %s", keyParam, alias))
+		}
+		groupColumns = append(groupColumns, alias)
+	}
+	columns = append(columns, "currency")
+	for _, metricName := range query.UniqueMetrics() {
+		metric, ok := metricMappings[metricName]
+		if !ok {
+			return BillingQuery{}, fmt.Errorf("unsupported cost metric %q", metricName)
+		}
+		columns = append(columns, fmt.Sprintf("%s AS %s", metric.Expression, metric.Column))
+	}
+	groupColumns = append(groupColumns, "currency")
+
+	conditions := []string{"usage_start_time >= @start", "usage_start_time < @end"}
+	for i, filterTag := range query.FilterTags {
+		keyParam, valuesParam := fmt.Sprintf("filter_%d_key", i), fmt.Sprintf("filter_%d_values", i)
+		values := filterTag.Values
+		if values == nil {
+			values = []string{}
+		}
+		params = append(params,
+			BillingQueryParameter{Name: keyParam, Type: "STRING", Value: UniversalTag(filterTag.Key).ToGcp()},
+			BillingQueryParameter{Name: valuesParam, Type: "STRING", Values: values},
+		)
+		conditions = append(conditions, fmt.Sprintf("EXISTS(SELECT 1 FROM UNNEST(labels) AS l WHERE l.key = @%s AND l.value IN UNNEST(@%s))", keyParam, valuesParam))
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("SELECT\n  ")
+	sb.WriteString(strings.Join(columns, ",\n  "))
+	sb.WriteString(fmt.Sprintf("\nFROM `%s`\nWHERE ", c.BillingExportTable))
+	sb.WriteString(strings.Join(conditions, "\n  AND "))
+	sb.WriteString("\nGROUP BY ")
+	sb.WriteString(strings.Join(groupColumns, ", "))
+	sb.WriteString("\nORDER BY usage_window")
+
+	return BillingQuery{Sql: sb.String(), Parameters: params}, nil
+}
+
+func parseUsageWindow(raw string) (time.Time, error) {
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid usage window in results %q: %w", raw, err)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+func windowEnd(start time.Time, granularity infra_sdk.CostGranularity) time.Time {
+	switch granularity {
+	case infra_sdk.CostGranularityHourly:
+		return start.Add(time.Hour)
+	case infra_sdk.CostGranularityMonthly:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+func parseResultGroupKeys(groupBy infra_sdk.CostGroupIdentifiers, row BillingRow) infra_sdk.CostSeriesGroupKeys {
+	result := make(infra_sdk.CostSeriesGroupKeys, 0, len(groupBy))
+	for i, cur := range groupBy {
+		value := row[fmt.Sprintf("group_%d", i)]
+		if cur.Dimension != "" {
+			result = append(result, infra_sdk.CostSeriesGroupKey{Name: cur.Dimension, Value: value})
+		} else {
+			result = append(result, infra_sdk.CostSeriesGroupKey{TagKey: cur.TagKey, Value: value})
+		}
+	}
+	return result
+}
+
+// sumCosts adds a FLOAT64 from BigQuery to a formatted cost
+func sumCosts(total string, raw string) (string, error) {
+	a, err := strconv.ParseFloat(total, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid cost in results %q: %w", total, err)
+	}
+	b, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid cost in results %q: %w", raw, err)
+	}
+	return strconv.FormatFloat(a+b, 'f', -1, 64), nil
+}
+
+// formatCost normalizes a FLOAT64 from BigQuery (which may be in exponent form) into a decimal string
+func formatCost(raw string) string {
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return raw
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
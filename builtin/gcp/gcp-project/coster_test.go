@@ -0,0 +1,213 @@
+package gcp_project
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubQueryRunner struct {
+	rows  []BillingRow
+	err   error
+	query BillingQuery
+}
+
+func (s *stubQueryRunner) RunQuery(ctx context.Context, query BillingQuery) ([]BillingRow, error) {
+	s.query = query
+	return s.rows, s.err
+}
+
+func TestCoster_GetCosts(t *testing.T) {
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	runner := &stubQueryRunner{
+		rows: []BillingRow{
+			{"usage_window": "1740787200", "group_0": "my-project", "group_1": "dev", "currency": "USD", "cost": "1.5"},
+			{"usage_window": "1740873600", "group_0": "my-project", "group_1": "dev", "currency": "USD", "cost": "2.5E-3"},
+			{"usage_window": "1740787200", "group_0": "my-project", "group_1": "", "currency": "USD", "cost": "10"},
+		},
+	}
+	coster := Coster{
+		BillingExportTable: "billing-project.billing.gcp_billing_export_v1_ABC",
+		QueryRunner:        runner,
+	}
+
+	result, err := coster.GetCosts(context.Background(), infra_sdk.CostQuery{
+		Start:       day1,
+		End:         day2.AddDate(0, 0, 1),
+		Granularity: infra_sdk.CostGranularityDaily,
+		FilterTags: []infra_sdk.CostFilterTag{
+			{Key: infra_sdk.UniversalTagStack, Values: []string{"core"}},
+		},
+		GroupBy: infra_sdk.CostGroupIdentifiers{
+			{Dimension: infra_sdk.UniversalDimensionAccount},
+			{TagKey: infra_sdk.UniversalTagEnv},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, runner.query.Sql, "FROM `billing-project.billing.gcp_billing_export_v1_ABC`")
+	assert.Contains(t, runner.query.Sql, "TIMESTAMP_TRUNC(usage_start_time, DAY, 'UTC')")
+	assert.Contains(t, runner.query.Sql, "project.id AS group_0")
+	assert.Contains(t, runner.query.Sql, "l.key = @filter_0_key AND l.value IN UNNEST(@filter_0_values)")
+	assert.Contains(t, runner.query.Parameters, BillingQueryParameter{Name: "filter_0_key", Type: "STRING", Value: "stack"})
+	assert.Contains(t, runner.query.Parameters, BillingQueryParameter{Name: "filter_0_values", Type: "STRING", Values: []string{"core"}})
+	assert.Contains(t, runner.query.Parameters, BillingQueryParameter{Name: "group_1_key", Type: "STRING", Value: "env"})
+
+	require.Len(t, result.Series, 2)
+	dev, ok := result.Series["nullstone.io/cloud-account$my-project;nullstone.io/env$dev:UnblendedCost"]
+	require.True(t, ok)
+	assert.Equal(t, "UnblendedCost", dev.MetricName)
+	assert.Equal(t, []infra_sdk.CostSeriesDatapoint{
+		{Start: day1, End: day2, Unit: "USD", Value: "1.5"},
+		{Start: day2, End: day2.AddDate(0, 0, 1), Unit: "USD", Value: "0.0025"},
+	}, dev.Points)
+
+	untagged, ok := result.Series["nullstone.io/cloud-account$my-project;nullstone.io/env$:UnblendedCost"]
+	require.True(t, ok)
+	require.Len(t, untagged.Points, 1)
+	assert.Equal(t, "10", untagged.Points[0].Value)
+}
+
+func TestCoster_GetCosts_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		table  string
+		query  infra_sdk.CostQuery
+		errMsg string
+	}{
+		{
+			name:   "table name with backtick",
+			table:  "project.dataset.table` WHERE 1=1 --",
+			errMsg: "invalid billing export table",
+		},
+		{
+			name:  "unsupported dimension",
+			table: "project.dataset.table",
+			query: infra_sdk.CostQuery{
+				GroupBy: infra_sdk.CostGroupIdentifiers{{Dimension: "unknown"}},
+			},
+			errMsg: `unsupported cost dimension "unknown"`,
+		},
+		{
+			name:  "unsupported metric",
+			table: "project.dataset.table",
+			query: infra_sdk.CostQuery{
+				Metrics: []string{infra_sdk.UniversalMetricAmortizedCost},
+			},
+			errMsg: `unsupported cost metric "AmortizedCost"`,
+		},
+		{
+			name:  "filter expression",
+			table: "project.dataset.table",
+			query: infra_sdk.CostQuery{
+				Filter: &infra_sdk.CostFilter{Tag: &infra_sdk.CostFilterCondition{Key: infra_sdk.UniversalTagEnv, Values: []string{"dev"}}},
+			},
+			errMsg: "cost filter expressions are not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coster := Coster{BillingExportTable: tt.table, QueryRunner: &stubQueryRunner{}}
+			_, err := coster.GetCosts(context.Background(), tt.query)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestCoster_GetCosts_Metrics(t *testing.T) {
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	runner := &stubQueryRunner{
+		rows: []BillingRow{
+			{"usage_window": "1740787200", "currency": "USD", "cost": "10", "net_cost": "7.5", "usage_quantity": "42"},
+		},
+	}
+	coster := Coster{BillingExportTable: "project.dataset.table", QueryRunner: runner}
+
+	result, err := coster.GetCosts(context.Background(), infra_sdk.CostQuery{
+		Start:       day1,
+		End:         day1.AddDate(0, 0, 1),
+		Granularity: infra_sdk.CostGranularityDaily,
+		Metrics: []string{
+			infra_sdk.UniversalMetricUnblendedCost,
+			infra_sdk.UniversalMetricNetUnblendedCost,
+			infra_sdk.UniversalMetricUsageQuantity,
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, runner.query.Sql, "SUM(cost) AS cost")
+	assert.Contains(t, runner.query.Sql, "FROM UNNEST(credits) AS c), 0)) AS net_cost")
+	assert.Contains(t, runner.query.Sql, "SUM(usage.amount_in_pricing_units) AS usage_quantity")
+
+	require.Len(t, result.Series, 3)
+	assert.Equal(t, []infra_sdk.CostSeriesDatapoint{{Start: day1, End: day1.AddDate(0, 0, 1), Unit: "USD", Value: "10"}},
+		result.Series[":UnblendedCost"].Points)
+	assert.Equal(t, []infra_sdk.CostSeriesDatapoint{{Start: day1, End: day1.AddDate(0, 0, 1), Unit: "USD", Value: "7.5"}},
+		result.Series[":NetUnblendedCost"].Points)
+	assert.Equal(t, []infra_sdk.CostSeriesDatapoint{{Start: day1, End: day1.AddDate(0, 0, 1), Unit: "N/A", Value: "42"}},
+		result.Series[":UsageQuantity"].Points)
+}
+
+func TestCoster_GetCosts_GroupByService(t *testing.T) {
+	runner := &stubQueryRunner{
+		rows: []BillingRow{
+			{"usage_window": "1740787200", "group_0": "Compute Engine", "group_1": "us-central1", "currency": "USD", "cost": "3"},
+		},
+	}
+	coster := Coster{BillingExportTable: "project.dataset.table", QueryRunner: runner}
+
+	result, err := coster.GetCosts(context.Background(), infra_sdk.CostQuery{
+		Start: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC),
+		GroupBy: infra_sdk.CostGroupIdentifiers{
+			{Dimension: infra_sdk.UniversalDimensionService},
+			{Dimension: infra_sdk.UniversalDimensionRegion},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, runner.query.Sql, "service.description AS group_0")
+	assert.Contains(t, runner.query.Sql, "location.region AS group_1")
+	_, ok := result.Series["nullstone.io/service$Compute Engine;nullstone.io/region$us-central1:UnblendedCost"]
+	assert.True(t, ok)
+}
+
+func TestCoster_GetCosts_Currencies(t *testing.T) {
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	query := infra_sdk.CostQuery{
+		Start:   day1,
+		End:     day1.AddDate(0, 0, 1),
+		Metrics: []string{infra_sdk.UniversalMetricUnblendedCost, infra_sdk.UniversalMetricUsageQuantity},
+	}
+
+	// Rows for the same series and period are summed into one datapoint
+	runner := &stubQueryRunner{
+		rows: []BillingRow{
+			{"usage_window": "1740787200", "currency": "USD", "cost": "1.5", "usage_quantity": "40"},
+			{"usage_window": "1740787200", "currency": "USD", "cost": "2.5E-1", "usage_quantity": "2"},
+		},
+	}
+	result, err := Coster{BillingExportTable: "project.dataset.table", QueryRunner: runner}.GetCosts(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, []infra_sdk.CostSeriesDatapoint{{Start: day1, End: day1.AddDate(0, 0, 1), Unit: "USD", Value: "1.75"}},
+		result.Series[":UnblendedCost"].Points)
+	assert.Equal(t, []infra_sdk.CostSeriesDatapoint{{Start: day1, End: day1.AddDate(0, 0, 1), Unit: "N/A", Value: "42"}},
+		result.Series[":UsageQuantity"].Points)
+
+	// Costs in different currencies cannot be summed
+	runner.rows = []BillingRow{
+		{"usage_window": "1740787200", "currency": "USD", "cost": "1.5", "usage_quantity": "40"},
+		{"usage_window": "1740787200", "currency": "EUR", "cost": "1", "usage_quantity": "2"},
+	}
+	_, err = Coster{BillingExportTable: "project.dataset.table", QueryRunner: runner}.GetCosts(context.Background(), query)
+	assert.ErrorContains(t, err, "UnblendedCost has costs in more than one currency (USD, EUR)")
+}
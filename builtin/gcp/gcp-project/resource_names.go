@@ -0,0 +1,24 @@
+package gcp_project
+
+import "strings"
+
+// resourceNameSegment extracts the value following key in a GCP relative resource name
+// e.g. resourceNameSegment("projects/p/locations/us-east1/services/api", "locations") => "us-east1"
+func resourceNameSegment(name string, key string) string {
+	tokens := strings.Split(name, "/")
+	for i := 0; i < len(tokens)-1; i++ {
+		if tokens[i] == key {
+			return tokens[i+1]
+		}
+	}
+	return ""
+}
+
+// resourceShortName extracts the last segment of a GCP relative resource name or self link
+// e.g. resourceShortName("projects/p/topics/events") => "events"
+func resourceShortName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
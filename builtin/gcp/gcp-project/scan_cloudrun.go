@@ -0,0 +1,62 @@
+package gcp_project
+
+import (
+	"context"
+	"fmt"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"google.golang.org/api/run/v2"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+func ScanCloudRunServices(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	client, err := run.NewService(ctx, config.ClientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcp cloud run client: %w", err)
+	}
+
+	// "-" lists services in every location
+	parent := fmt.Sprintf("projects/%s/locations/-", config.ProjectId)
+	var resources []infra_sdk.ScanResource
+	err = client.Projects.Locations.Services.List(parent).Pages(ctx, func(page *run.GoogleCloudRunV2ListServicesResponse) error {
+		for _, svc := range page.Services {
+			// Collect container images
+			images := make([]string, 0)
+			if svc.Template != nil {
+				for _, c := range svc.Template.Containers {
+					images = append(images, c.Image)
+				}
+			}
+
+			resources = append(resources, infra_sdk.ScanResource{
+				UniqueId: svc.Name,
+				Name:     resourceShortName(svc.Name),
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category:    types.CategoryApp,
+					Subcategory: types.SubcategoryAppContainer,
+					Platform:    "cloudrun",
+					Provider:    "gcp",
+				},
+				ServiceName:         "Cloud Run",
+				ServiceResourceName: "Service",
+				Region:              resourceNameSegment(svc.Name, "locations"),
+				Attributes: map[string]any{
+					"uri":                   svc.Uri,
+					"urls":                  svc.Urls,
+					"ingress":               svc.Ingress,
+					"description":           svc.Description,
+					"latest_ready_revision": svc.LatestReadyRevision,
+					"images":                images,
+					"created_time":          svc.CreateTime,
+					"updated_time":          svc.UpdateTime,
+				},
+			}.WithTags(svc.Labels, gcpLabelToUniversal))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Cloud Run services: %w", err)
+	}
+
+	return resources, nil
+}
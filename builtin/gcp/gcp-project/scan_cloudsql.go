@@ -0,0 +1,92 @@
+package gcp_project
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"google.golang.org/api/sqladmin/v1"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+func ScanCloudSqlInstances(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	client, err := sqladmin.NewService(ctx, config.ClientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcp cloud sql client: %w", err)
+	}
+
+	var resources []infra_sdk.ScanResource
+	err = client.Instances.List(config.ProjectId).Pages(ctx, func(page *sqladmin.InstancesListResponse) error {
+		for _, instance := range page.Items {
+			// Determine database type
+			dbType := getCloudSqlDatabaseType(instance.DatabaseVersion)
+			if dbType == "" {
+				continue // Skip unsupported database types
+			}
+
+			var tier, availabilityType string
+			var labels map[string]string
+			if instance.Settings != nil {
+				tier = instance.Settings.Tier
+				availabilityType = instance.Settings.AvailabilityType
+				labels = instance.Settings.UserLabels
+			}
+
+			ipAddresses := make([]map[string]string, 0, len(instance.IpAddresses))
+			for _, ip := range instance.IpAddresses {
+				ipAddresses = append(ipAddresses, map[string]string{
+					"type":       ip.Type,
+					"ip_address": ip.IpAddress,
+				})
+			}
+
+			resources = append(resources, infra_sdk.ScanResource{
+				UniqueId: fmt.Sprintf("projects/%s/instances/%s", config.ProjectId, instance.Name),
+				Name:     instance.Name,
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category:    types.CategoryDatastore,
+					Subcategory: "",
+					Platform:    dbType,
+					Subplatform: "cloudsql",
+					Provider:    "gcp",
+				},
+				ServiceName:         "Cloud SQL",
+				ServiceResourceName: "Instance",
+				Region:              instance.Region,
+				Attributes: map[string]any{
+					"connection_name":   instance.ConnectionName,
+					"database_version":  instance.DatabaseVersion,
+					"instance_type":     instance.InstanceType,
+					"tier":              tier,
+					"availability_type": availabilityType,
+					"state":             instance.State,
+					"ip_addresses":      ipAddresses,
+					"master_instance":   instance.MasterInstanceName,
+					"replica_names":     instance.ReplicaNames,
+					"created_time":      instance.CreateTime,
+				},
+			}.WithTags(labels, gcpLabelToUniversal))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Cloud SQL instances: %w", err)
+	}
+
+	return resources, nil
+}
+
+// getCloudSqlDatabaseType determines the database type from a Cloud SQL database version (e.g. POSTGRES_15, MYSQL_8_0)
+func getCloudSqlDatabaseType(databaseVersion string) string {
+	switch {
+	case strings.HasPrefix(databaseVersion, "POSTGRES"):
+		return "postgres"
+	case strings.HasPrefix(databaseVersion, "MYSQL"):
+		return "mysql"
+	case strings.HasPrefix(databaseVersion, "SQLSERVER"):
+		return "sqlserver"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,65 @@
+package gcp_project
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"golang.org/x/net/publicsuffix"
+	"google.golang.org/api/dns/v1"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+// ScanDnsZones scans Cloud DNS managed zones and returns them as scan resources
+// A managed zone is considered a subdomain if its name is not a registrable domain
+func ScanDnsZones(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	client, err := dns.NewService(ctx, config.ClientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcp dns client: %w", err)
+	}
+
+	var resources []infra_sdk.ScanResource
+	err = client.ManagedZones.List(config.ProjectId).Pages(ctx, func(page *dns.ManagedZonesListResponse) error {
+		for _, zone := range page.ManagedZones {
+			dnsName := strings.TrimSuffix(zone.DnsName, ".")
+			category := types.CategoryDomain
+			if isSubdomain(dnsName) {
+				category = types.CategorySubdomain
+			}
+
+			resources = append(resources, infra_sdk.ScanResource{
+				UniqueId: fmt.Sprintf("projects/%s/managedZones/%s", config.ProjectId, zone.Name),
+				Name:     zone.DnsName,
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category: category,
+					Provider: "gcp",
+					Platform: "cloud-dns",
+				},
+				ServiceName:         "Cloud DNS",
+				ServiceResourceName: "Managed Zone",
+				Region:              GlobalRegion,
+				Attributes: map[string]any{
+					"name":         dnsName,
+					"zone_name":    zone.Name,
+					"zone_id":      zone.Id,
+					"description":  zone.Description,
+					"visibility":   zone.Visibility,
+					"name_servers": zone.NameServers,
+					"created_time": zone.CreationTime,
+				},
+			}.WithTags(zone.Labels, gcpLabelToUniversal))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list managed zones: %w", err)
+	}
+
+	return resources, nil
+}
+
+func isSubdomain(domain string) bool {
+	sld, _ := publicsuffix.EffectiveTLDPlusOne(domain)
+	return sld != domain
+}
@@ -0,0 +1,65 @@
+package gcp_project
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"google.golang.org/api/storage/v1"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+func ScanGcsBuckets(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	client, err := storage.NewService(ctx, config.ClientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcp storage client: %w", err)
+	}
+
+	var resources []infra_sdk.ScanResource
+	err = client.Buckets.List(config.ProjectId).Pages(ctx, func(page *storage.Buckets) error {
+		for _, bucket := range page.Items {
+			var website map[string]any
+			if bucket.Website != nil {
+				website = map[string]any{
+					"main_page_suffix": bucket.Website.MainPageSuffix,
+					"not_found_page":   bucket.Website.NotFoundPage,
+				}
+			}
+
+			versioning := false
+			if bucket.Versioning != nil {
+				versioning = bucket.Versioning.Enabled
+			}
+
+			resources = append(resources, infra_sdk.ScanResource{
+				UniqueId: fmt.Sprintf("projects/_/buckets/%s", bucket.Name),
+				Name:     bucket.Name,
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category:    types.CategoryDatastore,
+					Platform:    "gcs",
+					Subplatform: "",
+					Provider:    "gcp",
+				},
+				ServiceName:         "Cloud Storage",
+				ServiceResourceName: "Bucket",
+				// GCS reports locations in upper-case (e.g. US-EAST1, US)
+				Region: strings.ToLower(bucket.Location),
+				Attributes: map[string]any{
+					"location":      bucket.Location,
+					"location_type": bucket.LocationType,
+					"storage_class": bucket.StorageClass,
+					"versioning":    versioning,
+					"website":       website,
+					"creation_date": bucket.TimeCreated,
+				},
+			}.WithTags(bucket.Labels, gcpLabelToUniversal))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list GCS buckets: %w", err)
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,72 @@
+package gcp_project
+
+import (
+	"context"
+	"fmt"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"google.golang.org/api/container/v1"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+func ScanGkeClusters(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	client, err := container.NewService(ctx, config.ClientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcp container client: %w", err)
+	}
+
+	// "-" lists clusters in every location (zones and regions)
+	parent := fmt.Sprintf("projects/%s/locations/-", config.ProjectId)
+	output, err := client.Projects.Locations.Clusters.List(parent).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list GKE clusters: %w", err)
+	}
+
+	resources := make([]infra_sdk.ScanResource, 0, len(output.Clusters))
+	for _, cluster := range output.Clusters {
+		if cluster.Status == "STOPPING" {
+			continue
+		}
+
+		// Determine cluster mode (Autopilot or Standard)
+		clusterMode := "standard"
+		if cluster.Autopilot != nil && cluster.Autopilot.Enabled {
+			clusterMode = "autopilot"
+		}
+
+		nodePools := make([]string, 0, len(cluster.NodePools))
+		for _, pool := range cluster.NodePools {
+			nodePools = append(nodePools, pool.Name)
+		}
+
+		resources = append(resources, infra_sdk.ScanResource{
+			UniqueId: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", config.ProjectId, cluster.Location, cluster.Name),
+			Name:     cluster.Name,
+			Taxonomy: infra_sdk.ResourceTaxonomy{
+				Category:    types.CategoryCluster,
+				Subcategory: "",
+				Platform:    "k8s",
+				Subplatform: "gke",
+				Provider:    "gcp",
+			},
+			ServiceName:         "GKE",
+			ServiceResourceName: "Cluster",
+			Region:              cluster.Location,
+			Attributes: map[string]any{
+				"status":             cluster.Status,
+				"mode":               clusterMode,
+				"endpoint":           cluster.Endpoint,
+				"master_version":     cluster.CurrentMasterVersion,
+				"node_version":       cluster.CurrentNodeVersion,
+				"current_node_count": cluster.CurrentNodeCount,
+				"node_pools":         nodePools,
+				"network":            cluster.Network,
+				"subnetwork":         cluster.Subnetwork,
+				"locations":          cluster.Locations,
+				"created_time":       cluster.CreateTime,
+			},
+		}.WithTags(cluster.ResourceLabels, gcpLabelToUniversal))
+	}
+
+	return resources, nil
+}
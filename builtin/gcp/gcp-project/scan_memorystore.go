@@ -0,0 +1,66 @@
+package gcp_project
+
+import (
+	"context"
+	"fmt"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"google.golang.org/api/redis/v1"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+func ScanMemorystoreInstances(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	client, err := redis.NewService(ctx, config.ClientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcp memorystore client: %w", err)
+	}
+
+	// "-" lists instances in every location
+	parent := fmt.Sprintf("projects/%s/locations/-", config.ProjectId)
+	var resources []infra_sdk.ScanResource
+	err = client.Projects.Locations.Instances.List(parent).Pages(ctx, func(page *redis.ListInstancesResponse) error {
+		for _, instance := range page.Instances {
+			if instance.State == "DELETING" {
+				continue
+			}
+
+			name := instance.DisplayName
+			if name == "" {
+				name = resourceShortName(instance.Name)
+			}
+
+			resources = append(resources, infra_sdk.ScanResource{
+				UniqueId: instance.Name,
+				Name:     name,
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category:    types.CategoryDatastore,
+					Platform:    "redis",
+					Subplatform: "memorystore",
+					Provider:    "gcp",
+				},
+				ServiceName:         "Memorystore",
+				ServiceResourceName: "Instance",
+				Region:              resourceNameSegment(instance.Name, "locations"),
+				Attributes: map[string]any{
+					"state":              instance.State,
+					"tier":               instance.Tier,
+					"redis_version":      instance.RedisVersion,
+					"memory_size_gb":     instance.MemorySizeGb,
+					"host":               instance.Host,
+					"port":               instance.Port,
+					"read_endpoint":      instance.ReadEndpoint,
+					"replica_count":      instance.ReplicaCount,
+					"authorized_network": instance.AuthorizedNetwork,
+					"location_id":        instance.LocationId,
+					"created_time":       instance.CreateTime,
+				},
+			}.WithTags(instance.Labels, gcpLabelToUniversal))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Memorystore instances: %w", err)
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,56 @@
+package gcp_project
+
+import (
+	"context"
+	"fmt"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"google.golang.org/api/compute/v1"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+func ScanNetworks(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	client, err := compute.NewService(ctx, config.ClientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcp compute client: %w", err)
+	}
+
+	resources := make([]infra_sdk.ScanResource, 0)
+	err = client.Networks.List(config.ProjectId).Pages(ctx, func(page *compute.NetworkList) error {
+		for _, network := range page.Items {
+			var routingMode string
+			if network.RoutingConfig != nil {
+				routingMode = network.RoutingConfig.RoutingMode
+			}
+
+			resources = append(resources, infra_sdk.ScanResource{
+				UniqueId: fmt.Sprintf("projects/%s/global/networks/%s", config.ProjectId, network.Name),
+				Name:     network.Name,
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category:    types.CategoryNetwork,
+					Subcategory: "",
+					Platform:    "vpc",
+					Provider:    "gcp",
+				},
+				ServiceName:         "VPC",
+				ServiceResourceName: "Network",
+				Region:              GlobalRegion,
+				Attributes: map[string]any{
+					"network_id":              network.Id,
+					"description":             network.Description,
+					"auto_create_subnetworks": network.AutoCreateSubnetworks,
+					"routing_mode":            routingMode,
+					"mtu":                     network.Mtu,
+					"subnetworks":             network.Subnetworks,
+					"created_time":            network.CreationTimestamp,
+				},
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VPC networks: %w", err)
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,54 @@
+package gcp_project
+
+import (
+	"context"
+	"fmt"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"google.golang.org/api/pubsub/v1"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+func ScanPubSubTopics(ctx context.Context, config Config) ([]infra_sdk.ScanResource, error) {
+	client, err := pubsub.NewService(ctx, config.ClientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcp pubsub client: %w", err)
+	}
+
+	project := fmt.Sprintf("projects/%s", config.ProjectId)
+	var resources []infra_sdk.ScanResource
+	err = client.Projects.Topics.List(project).Pages(ctx, func(page *pubsub.ListTopicsResponse) error {
+		for _, topic := range page.Topics {
+			var allowedRegions []string
+			if topic.MessageStoragePolicy != nil {
+				allowedRegions = topic.MessageStoragePolicy.AllowedPersistenceRegions
+			}
+
+			resources = append(resources, infra_sdk.ScanResource{
+				UniqueId: topic.Name,
+				Name:     resourceShortName(topic.Name),
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category:    types.CategoryDatastore,
+					Platform:    "pubsub",
+					Subplatform: "",
+					Provider:    "gcp",
+				},
+				ServiceName:         "Pub/Sub",
+				ServiceResourceName: "Topic",
+				Region:              GlobalRegion,
+				Attributes: map[string]any{
+					"state":                       topic.State,
+					"kms_key_name":                topic.KmsKeyName,
+					"message_retention_duration":  topic.MessageRetentionDuration,
+					"allowed_persistence_regions": allowedRegions,
+				},
+			}.WithTags(topic.Labels, gcpLabelToUniversal))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Pub/Sub topics: %w", err)
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,72 @@
+package gcp_project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nullstone-io/infra-sdk"
+	"google.golang.org/api/option"
+)
+
+const (
+	// GlobalRegion is stamped on resources that are not bound to a region (e.g. VPC networks, Cloud DNS zones)
+	GlobalRegion = "global"
+)
+
+var (
+	AllScanners = []ResourceScanner{
+		// domain/subdomain
+		ScanDnsZones,
+
+		// network
+		ScanNetworks,
+
+		// cluster
+		ScanGkeClusters,
+
+		// app
+		ScanCloudRunServices,
+
+		// datastore
+		ScanGcsBuckets,
+		ScanCloudSqlInstances,
+		ScanMemorystoreInstances,
+		ScanPubSubTopics,
+	}
+)
+
+var (
+	_ infra_sdk.Scanner = Scanner{}
+)
+
+type Scanner struct {
+	Accessor infra_sdk.GcpAccessor
+}
+
+func (s Scanner) Scan(ctx context.Context) ([]infra_sdk.ScanResource, error) {
+	if s.Accessor == nil {
+		return nil, nil
+	}
+	tokenSource, err := s.Accessor.GetTokenSource(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving gcp credentials: %w", err)
+	}
+	if tokenSource == nil {
+		return nil, nil
+	}
+
+	config := Config{
+		ProjectId:     s.Accessor.GcpProjectId(),
+		ClientOptions: []option.ClientOption{option.WithTokenSource(tokenSource)},
+	}
+	tracker := NewResourceScanTracker()
+	for _, scanner := range AllScanners {
+		tracker.Scan(ctx, config, scanner)
+	}
+	tracker.Wait()
+	if len(tracker.Errors) > 0 {
+		err = errors.Join(tracker.Errors...)
+	}
+	return tracker.Resources, err
+}
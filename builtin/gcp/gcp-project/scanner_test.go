@@ -0,0 +1,337 @@
+package gcp_project
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"gopkg.in/nullstone-io/go-api-client.v0/types"
+)
+
+// newGcpServer responds to gcp api list requests with canned json keyed by path
+// Every api is served from the same endpoint, so paths do not include the api's base path (e.g. /compute/v1)
+func newGcpServer(t *testing.T, responses map[string]string) []option.ClientOption {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			body = `{}`
+		}
+		if body == "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":{"code":500,"message":"boom"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(ts.Close)
+	return []option.ClientOption{option.WithEndpoint(ts.URL + "/"), option.WithoutAuthentication()}
+}
+
+func TestResourceScanners(t *testing.T) {
+	tests := []struct {
+		name      string
+		scanner   ResourceScanner
+		responses map[string]string
+		want      infra_sdk.ScanResource
+	}{
+		{
+			name:    "dns zone",
+			scanner: ScanDnsZones,
+			responses: map[string]string{
+				"/dns/v1/projects/proj/managedZones": `{"managedZones":[{
+					"name": "api-zone", "dnsName": "api.example.com.", "id": "123", "description": "api",
+					"visibility": "public", "nameServers": ["ns-cloud-a1.googledomains.com."],
+					"creationTime": "2025-01-01T00:00:00Z", "labels": {"stack": "core", "team": "web"}
+				}]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId:            "projects/proj/managedZones/api-zone",
+				Name:                "api.example.com.",
+				Taxonomy:            infra_sdk.ResourceTaxonomy{Category: types.CategorySubdomain, Provider: "gcp", Platform: "cloud-dns"},
+				ServiceName:         "Cloud DNS",
+				ServiceResourceName: "Managed Zone",
+				Region:              GlobalRegion,
+				Attributes: map[string]any{
+					"name":         "api.example.com",
+					"zone_name":    "api-zone",
+					"zone_id":      uint64(123),
+					"description":  "api",
+					"visibility":   "public",
+					"name_servers": []string{"ns-cloud-a1.googledomains.com."},
+					"created_time": "2025-01-01T00:00:00Z",
+				},
+				Tags:      map[string]string{infra_sdk.UniversalTagStack: "core", "team": "web"},
+				Ownership: infra_sdk.Ownership{Stack: "core"},
+			},
+		},
+		{
+			name:    "network",
+			scanner: ScanNetworks,
+			responses: map[string]string{
+				"/projects/proj/global/networks": `{"items":[{
+					"name": "main", "id": "456", "description": "primary", "autoCreateSubnetworks": false,
+					"routingConfig": {"routingMode": "GLOBAL"}, "mtu": 1460,
+					"subnetworks": ["https://www.googleapis.com/compute/v1/projects/proj/regions/us-east1/subnetworks/private"],
+					"creationTimestamp": "2025-01-01T00:00:00Z"
+				}]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId:            "projects/proj/global/networks/main",
+				Name:                "main",
+				Taxonomy:            infra_sdk.ResourceTaxonomy{Category: types.CategoryNetwork, Platform: "vpc", Provider: "gcp"},
+				ServiceName:         "VPC",
+				ServiceResourceName: "Network",
+				Region:              GlobalRegion,
+				Attributes: map[string]any{
+					"network_id":              uint64(456),
+					"description":             "primary",
+					"auto_create_subnetworks": false,
+					"routing_mode":            "GLOBAL",
+					"mtu":                     int64(1460),
+					"subnetworks":             []string{"https://www.googleapis.com/compute/v1/projects/proj/regions/us-east1/subnetworks/private"},
+					"created_time":            "2025-01-01T00:00:00Z",
+				},
+			},
+		},
+		{
+			name:    "gke cluster",
+			scanner: ScanGkeClusters,
+			responses: map[string]string{
+				"/v1/projects/proj/locations/-/clusters": `{"clusters":[
+					{
+						"name": "k8s", "location": "us-east1", "status": "RUNNING", "autopilot": {"enabled": true},
+						"endpoint": "10.0.0.1", "currentMasterVersion": "1.30", "currentNodeVersion": "1.30", "currentNodeCount": 3,
+						"nodePools": [{"name": "default"}], "network": "main", "subnetwork": "private", "locations": ["us-east1-b"],
+						"createTime": "2025-01-01T00:00:00Z", "resourceLabels": {"env": "prod"}
+					},
+					{"name": "old", "location": "us-east1", "status": "STOPPING"}
+				]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId:            "projects/proj/locations/us-east1/clusters/k8s",
+				Name:                "k8s",
+				Taxonomy:            infra_sdk.ResourceTaxonomy{Category: types.CategoryCluster, Platform: "k8s", Subplatform: "gke", Provider: "gcp"},
+				ServiceName:         "GKE",
+				ServiceResourceName: "Cluster",
+				Region:              "us-east1",
+				Attributes: map[string]any{
+					"status":             "RUNNING",
+					"mode":               "autopilot",
+					"endpoint":           "10.0.0.1",
+					"master_version":     "1.30",
+					"node_version":       "1.30",
+					"current_node_count": int64(3),
+					"node_pools":         []string{"default"},
+					"network":            "main",
+					"subnetwork":         "private",
+					"locations":          []string{"us-east1-b"},
+					"created_time":       "2025-01-01T00:00:00Z",
+				},
+				Tags:      map[string]string{infra_sdk.UniversalTagEnv: "prod"},
+				Ownership: infra_sdk.Ownership{Env: "prod"},
+			},
+		},
+		{
+			name:    "cloud run service",
+			scanner: ScanCloudRunServices,
+			responses: map[string]string{
+				"/v2/projects/proj/locations/-/services": `{"services":[{
+					"name": "projects/proj/locations/us-central1/services/api", "uri": "https://api-abc.a.run.app",
+					"urls": ["https://api-abc.a.run.app"], "ingress": "INGRESS_TRAFFIC_ALL", "description": "api",
+					"latestReadyRevision": "api-00001", "template": {"containers": [{"image": "gcr.io/proj/api:1"}]},
+					"createTime": "2025-01-01T00:00:00Z", "updateTime": "2025-01-02T00:00:00Z", "labels": {"block": "api"}
+				}]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId: "projects/proj/locations/us-central1/services/api",
+				Name:     "api",
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category: types.CategoryApp, Subcategory: types.SubcategoryAppContainer, Platform: "cloudrun", Provider: "gcp",
+				},
+				ServiceName:         "Cloud Run",
+				ServiceResourceName: "Service",
+				Region:              "us-central1",
+				Attributes: map[string]any{
+					"uri":                   "https://api-abc.a.run.app",
+					"urls":                  []string{"https://api-abc.a.run.app"},
+					"ingress":               "INGRESS_TRAFFIC_ALL",
+					"description":           "api",
+					"latest_ready_revision": "api-00001",
+					"images":                []string{"gcr.io/proj/api:1"},
+					"created_time":          "2025-01-01T00:00:00Z",
+					"updated_time":          "2025-01-02T00:00:00Z",
+				},
+				Tags:      map[string]string{infra_sdk.UniversalTagBlock: "api"},
+				Ownership: infra_sdk.Ownership{Block: "api"},
+			},
+		},
+		{
+			name:    "gcs bucket",
+			scanner: ScanGcsBuckets,
+			responses: map[string]string{
+				"/b": `{"items":[{
+					"name": "assets", "location": "US-EAST1", "locationType": "region", "storageClass": "STANDARD",
+					"versioning": {"enabled": true}, "website": {"mainPageSuffix": "index.html", "notFoundPage": "404.html"},
+					"timeCreated": "2025-01-01T00:00:00Z", "labels": {}
+				}]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId:            "projects/_/buckets/assets",
+				Name:                "assets",
+				Taxonomy:            infra_sdk.ResourceTaxonomy{Category: types.CategoryDatastore, Platform: "gcs", Provider: "gcp"},
+				ServiceName:         "Cloud Storage",
+				ServiceResourceName: "Bucket",
+				Region:              "us-east1",
+				Attributes: map[string]any{
+					"location":      "US-EAST1",
+					"location_type": "region",
+					"storage_class": "STANDARD",
+					"versioning":    true,
+					"website":       map[string]any{"main_page_suffix": "index.html", "not_found_page": "404.html"},
+					"creation_date": "2025-01-01T00:00:00Z",
+				},
+				Tags: map[string]string{},
+			},
+		},
+		{
+			name:    "cloud sql instance",
+			scanner: ScanCloudSqlInstances,
+			responses: map[string]string{
+				"/v1/projects/proj/instances": `{"items":[
+					{
+						"name": "db", "region": "us-east1", "databaseVersion": "POSTGRES_15", "connectionName": "proj:us-east1:db",
+						"instanceType": "CLOUD_SQL_INSTANCE", "state": "RUNNABLE", "createTime": "2025-01-01T00:00:00Z",
+						"settings": {"tier": "db-f1-micro", "availabilityType": "ZONAL", "userLabels": {"stack": "core"}},
+						"ipAddresses": [{"type": "PRIVATE", "ipAddress": "10.0.0.2"}], "replicaNames": ["db-replica"]
+					},
+					{"name": "unsupported", "databaseVersion": "SQL_DATABASE_VERSION_UNSPECIFIED"}
+				]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId: "projects/proj/instances/db",
+				Name:     "db",
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category: types.CategoryDatastore, Platform: "postgres", Subplatform: "cloudsql", Provider: "gcp",
+				},
+				ServiceName:         "Cloud SQL",
+				ServiceResourceName: "Instance",
+				Region:              "us-east1",
+				Attributes: map[string]any{
+					"connection_name":   "proj:us-east1:db",
+					"database_version":  "POSTGRES_15",
+					"instance_type":     "CLOUD_SQL_INSTANCE",
+					"tier":              "db-f1-micro",
+					"availability_type": "ZONAL",
+					"state":             "RUNNABLE",
+					"ip_addresses":      []map[string]string{{"type": "PRIVATE", "ip_address": "10.0.0.2"}},
+					"master_instance":   "",
+					"replica_names":     []string{"db-replica"},
+					"created_time":      "2025-01-01T00:00:00Z",
+				},
+				Tags:      map[string]string{infra_sdk.UniversalTagStack: "core"},
+				Ownership: infra_sdk.Ownership{Stack: "core"},
+			},
+		},
+		{
+			name:    "memorystore instance",
+			scanner: ScanMemorystoreInstances,
+			responses: map[string]string{
+				"/v1/projects/proj/locations/-/instances": `{"instances":[
+					{
+						"name": "projects/proj/locations/us-east1/instances/cache", "state": "READY", "tier": "BASIC",
+						"redisVersion": "REDIS_7_0", "memorySizeGb": 1, "host": "10.0.0.3", "port": 6379,
+						"authorizedNetwork": "main", "locationId": "us-east1-b", "createTime": "2025-01-01T00:00:00Z"
+					},
+					{"name": "projects/proj/locations/us-east1/instances/old", "state": "DELETING"}
+				]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId: "projects/proj/locations/us-east1/instances/cache",
+				Name:     "cache",
+				Taxonomy: infra_sdk.ResourceTaxonomy{
+					Category: types.CategoryDatastore, Platform: "redis", Subplatform: "memorystore", Provider: "gcp",
+				},
+				ServiceName:         "Memorystore",
+				ServiceResourceName: "Instance",
+				Region:              "us-east1",
+				Attributes: map[string]any{
+					"state":              "READY",
+					"tier":               "BASIC",
+					"redis_version":      "REDIS_7_0",
+					"memory_size_gb":     int64(1),
+					"host":               "10.0.0.3",
+					"port":               int64(6379),
+					"read_endpoint":      "",
+					"replica_count":      int64(0),
+					"authorized_network": "main",
+					"location_id":        "us-east1-b",
+					"created_time":       "2025-01-01T00:00:00Z",
+				},
+				Tags: map[string]string{},
+			},
+		},
+		{
+			name:    "pubsub topic",
+			scanner: ScanPubSubTopics,
+			responses: map[string]string{
+				"/v1/projects/proj/topics": `{"topics":[{
+					"name": "projects/proj/topics/events", "state": "ACTIVE", "messageRetentionDuration": "86400s",
+					"messageStoragePolicy": {"allowedPersistenceRegions": ["us-east1"]}, "labels": {"env": "prod"}
+				}]}`,
+			},
+			want: infra_sdk.ScanResource{
+				UniqueId:            "projects/proj/topics/events",
+				Name:                "events",
+				Taxonomy:            infra_sdk.ResourceTaxonomy{Category: types.CategoryDatastore, Platform: "pubsub", Provider: "gcp"},
+				ServiceName:         "Pub/Sub",
+				ServiceResourceName: "Topic",
+				Region:              GlobalRegion,
+				Attributes: map[string]any{
+					"state":                       "ACTIVE",
+					"kms_key_name":                "",
+					"message_retention_duration":  "86400s",
+					"allowed_persistence_regions": []string{"us-east1"},
+				},
+				Tags:      map[string]string{infra_sdk.UniversalTagEnv: "prod"},
+				Ownership: infra_sdk.Ownership{Env: "prod"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := Config{ProjectId: "proj", ClientOptions: newGcpServer(t, test.responses)}
+			resources, err := test.scanner(context.Background(), config)
+			require.NoError(t, err)
+			require.Len(t, resources, 1)
+			assert.Equal(t, test.want, resources[0])
+		})
+	}
+}
+
+func TestResourceScanTracker(t *testing.T) {
+	config := Config{
+		ProjectId: "proj",
+		ClientOptions: newGcpServer(t, map[string]string{
+			"/v1/projects/proj/topics": `{"topics":[{"name": "projects/proj/topics/events"}]}`,
+			"/b":                       "",
+		}),
+	}
+
+	// A failing scanner does not prevent the other scanners from reporting resources
+	tracker := NewResourceScanTracker()
+	tracker.Scan(context.Background(), config, ScanPubSubTopics)
+	tracker.Scan(context.Background(), config, ScanGcsBuckets)
+	tracker.Wait()
+	require.Len(t, tracker.Errors, 1)
+	assert.ErrorContains(t, tracker.Errors[0], "failed to list GCS buckets")
+	require.Len(t, tracker.Resources, 1)
+	assert.Equal(t, "events", tracker.Resources[0].Name)
+}
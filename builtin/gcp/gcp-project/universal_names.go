@@ -0,0 +1,78 @@
+package gcp_project
+
+import infra_sdk "github.com/nullstone-io/infra-sdk"
+
+// GcpDimension is a column in the Cloud Billing BigQuery export
+type GcpDimension string
+
+func (d GcpDimension) ToUniversal() string {
+	switch d {
+	case "project.id":
+		return infra_sdk.UniversalDimensionAccount
+	case "service.description":
+		return infra_sdk.UniversalDimensionService
+	case "location.region":
+		return infra_sdk.UniversalDimensionRegion
+	case "sku.description":
+		return infra_sdk.UniversalDimensionUsageType
+	case "cost_type":
+		return infra_sdk.UniversalDimensionChargeType
+	}
+	return string(d)
+}
+
+type UniversalDimension string
+
+// ToGcp maps a universal dimension to its column in the Cloud Billing BigQuery export
+// An empty string is returned if the dimension is not supported
+// The standard export has no equivalent for operation, resource id, or purchase option
+func (d UniversalDimension) ToGcp() string {
+	switch d {
+	case infra_sdk.UniversalDimensionAccount:
+		return "project.id"
+	case infra_sdk.UniversalDimensionService:
+		return "service.description"
+	case infra_sdk.UniversalDimensionRegion:
+		return "location.region"
+	case infra_sdk.UniversalDimensionUsageType:
+		return "sku.description"
+	case infra_sdk.UniversalDimensionChargeType:
+		return "cost_type"
+	}
+	return ""
+}
+
+// GcpLabel is a label key on a GCP resource
+// GCP label keys only allow lowercase letters, numbers, underscores, and dashes
+type GcpLabel string
+
+func (t GcpLabel) ToUniversal() string {
+	switch t {
+	case "stack":
+		return infra_sdk.UniversalTagStack
+	case "env":
+		return infra_sdk.UniversalTagEnv
+	case "block":
+		return infra_sdk.UniversalTagBlock
+	}
+	return string(t)
+}
+
+// gcpLabelToUniversal adapts GcpLabel.ToUniversal for ScanResource.WithTags
+func gcpLabelToUniversal(key string) string {
+	return GcpLabel(key).ToUniversal()
+}
+
+type UniversalTag string
+
+func (t UniversalTag) ToGcp() string {
+	switch t {
+	case infra_sdk.UniversalTagStack:
+		return "stack"
+	case infra_sdk.UniversalTagEnv:
+		return "env"
+	case infra_sdk.UniversalTagBlock:
+		return "block"
+	}
+	return string(t)
+}
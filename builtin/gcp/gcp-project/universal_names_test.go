@@ -0,0 +1,26 @@
+package gcp_project
+
+import (
+	"testing"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniversalDimension(t *testing.T) {
+	tests := map[string]string{
+		infra_sdk.UniversalDimensionAccount:    "project.id",
+		infra_sdk.UniversalDimensionService:    "service.description",
+		infra_sdk.UniversalDimensionRegion:     "location.region",
+		infra_sdk.UniversalDimensionUsageType:  "sku.description",
+		infra_sdk.UniversalDimensionChargeType: "cost_type",
+	}
+	for universal, gcp := range tests {
+		assert.Equal(t, gcp, UniversalDimension(universal).ToGcp(), universal)
+		assert.Equal(t, universal, GcpDimension(gcp).ToUniversal(), gcp)
+	}
+
+	for _, unsupported := range []string{infra_sdk.UniversalDimensionOperation, infra_sdk.UniversalDimensionResourceId, infra_sdk.UniversalDimensionPurchaseOption} {
+		assert.Empty(t, UniversalDimension(unsupported).ToGcp(), unsupported)
+	}
+}
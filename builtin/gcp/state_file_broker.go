@@ -0,0 +1,261 @@
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
+)
+
+const (
+	defaultWorkspace = "default"
+	stateFileSuffix  = ".tfstate"
+	lockFileSuffix   = ".tflock"
+)
+
+var (
+	_ infra_sdk.StateFileBroker = &GcsStateFileBroker{}
+	_ infra_sdk.StateFileLoader = &GcsStateFileBroker{}
+)
+
+// GcsStateFileBroker stores state in a GCS bucket using the same layout as terraform's gcs backend
+// State is stored in `<prefix>/<workspace>.tfstate` and locked with `<prefix>/<workspace>.tflock`
+// See https://developer.hashicorp.com/terraform/language/backend/gcs
+//
+// Every write uses a generation-match precondition so that concurrent updates are never lost.
+type GcsStateFileBroker struct {
+	Accessor infra_sdk.GcpAccessor
+	Bucket   string
+	Prefix   string
+	// Workspace is the terraform workspace; if empty, the "default" workspace is used
+	Workspace string
+	// Operation is recorded in the lock info; if empty, infra_sdk.StateLockOperationRefresh is used
+	Operation string
+	// ClientOptions are added to the storage client options (e.g. option.WithEndpoint)
+	ClientOptions []option.ClientOption
+
+	mu             sync.Mutex
+	lockInfo       *infra_sdk.StateLockInfo
+	lockGeneration int64
+}
+
+func (b *GcsStateFileBroker) workspace() string {
+	if b.Workspace == "" {
+		return defaultWorkspace
+	}
+	return b.Workspace
+}
+
+// StateObjectName returns the name of the object that contains the state for the configured workspace
+func (b *GcsStateFileBroker) StateObjectName() string {
+	return path.Join(b.Prefix, b.workspace()+stateFileSuffix)
+}
+
+// LockObjectName returns the name of the object that is used to lock the state for the configured workspace
+func (b *GcsStateFileBroker) LockObjectName() string {
+	return path.Join(b.Prefix, b.workspace()+lockFileSuffix)
+}
+
+func (b *GcsStateFileBroker) Initialize(ctx context.Context) error {
+	client, err := b.storageClient(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := client.Buckets.Get(b.Bucket).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("error accessing state bucket %q: %w", b.Bucket, err)
+	}
+
+	current, _, err := b.load(ctx, client)
+	if err != nil || current != nil {
+		return err
+	}
+	// A generation of 0 ensures we never overwrite a state file that was written after we checked
+	if err := b.put(ctx, client, infra_sdk.NewStateFile(), 0); err != nil && !isHttpStatus(err, http.StatusPreconditionFailed) {
+		return fmt.Errorf("error initializing state file: %w", err)
+	}
+	return nil
+}
+
+func (b *GcsStateFileBroker) Lock(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.lockInfo != nil {
+		return nil
+	}
+
+	client, err := b.storageClient(ctx)
+	if err != nil {
+		return err
+	}
+	info := infra_sdk.NewStateLockInfo(b.Operation, b.StateObjectName())
+	raw, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("error encoding lock info: %w", err)
+	}
+
+	obj := &storage.Object{Name: b.LockObjectName(), ContentType: "application/json"}
+	created, err := client.Objects.Insert(b.Bucket, obj).Media(bytes.NewReader(raw)).IfGenerationMatch(0).Context(ctx).Do()
+	if err != nil {
+		if !isHttpStatus(err, http.StatusPreconditionFailed) {
+			return fmt.Errorf("error acquiring state lock: %w", err)
+		}
+		existing, _, _ := b.readLock(ctx, client)
+		return infra_sdk.StateLockedError{Info: existing}
+	}
+
+	b.lockInfo = &info
+	b.lockGeneration = created.Generation
+	return nil
+}
+
+func (b *GcsStateFileBroker) Unlock(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.lockInfo == nil {
+		return nil
+	}
+
+	client, err := b.storageClient(ctx)
+	if err != nil {
+		return err
+	}
+	existing, generation, err := b.readLock(ctx, client)
+	if err != nil {
+		return fmt.Errorf("error releasing state lock: %w", err)
+	}
+	if existing.ID != b.lockInfo.ID {
+		return fmt.Errorf("error releasing state lock: lock is held by someone else (id=%s)", existing.ID)
+	}
+	if err := client.Objects.Delete(b.Bucket, b.LockObjectName()).IfGenerationMatch(generation).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("error releasing state lock: %w", err)
+	}
+
+	b.lockInfo = nil
+	b.lockGeneration = 0
+	return nil
+}
+
+func (b *GcsStateFileBroker) Load(ctx context.Context) (*infra_sdk.StateFile, error) {
+	client, err := b.storageClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stateFile, _, err := b.load(ctx, client)
+	return stateFile, err
+}
+
+func (b *GcsStateFileBroker) Save(ctx context.Context, stateFile infra_sdk.StateFile) error {
+	b.mu.Lock()
+	locked := b.lockInfo != nil
+	b.mu.Unlock()
+	if !locked {
+		return infra_sdk.ErrStateNotLocked
+	}
+
+	client, err := b.storageClient(ctx)
+	if err != nil {
+		return err
+	}
+	current, generation, err := b.load(ctx, client)
+	if err != nil {
+		return err
+	}
+	next, err := infra_sdk.PrepareStateFileSave(current, stateFile)
+	if err != nil {
+		return err
+	}
+	if err := b.put(ctx, client, next, generation); err != nil {
+		if isHttpStatus(err, http.StatusPreconditionFailed) {
+			return fmt.Errorf("state file was modified while saving: %w", err)
+		}
+		return fmt.Errorf("error saving state file: %w", err)
+	}
+	return nil
+}
+
+func (b *GcsStateFileBroker) storageClient(ctx context.Context) (*storage.Service, error) {
+	if b.Accessor == nil {
+		return nil, fmt.Errorf("gcp accessor is required")
+	}
+	tokenSource, err := b.Accessor.GetTokenSource(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving gcp credentials: %w", err)
+	}
+	opts := append([]option.ClientOption{option.WithTokenSource(tokenSource)}, b.ClientOptions...)
+	client, err := storage.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcp storage client: %w", err)
+	}
+	return client, nil
+}
+
+// load retrieves the state file and its generation
+// A generation of 0 is returned if the state file does not exist
+func (b *GcsStateFileBroker) load(ctx context.Context, client *storage.Service) (*infra_sdk.StateFile, int64, error) {
+	raw, generation, err := b.download(ctx, client, b.StateObjectName())
+	if err != nil {
+		if isHttpStatus(err, http.StatusNotFound) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("error retrieving state file: %w", err)
+	}
+	if len(raw) == 0 {
+		return nil, generation, nil
+	}
+	var stateFile infra_sdk.StateFile
+	if err := json.Unmarshal(raw, &stateFile); err != nil {
+		return nil, 0, fmt.Errorf("error decoding state file: %w", err)
+	}
+	return &stateFile, generation, nil
+}
+
+// put writes the state file only if the existing object matches generation (0 means the object must not exist)
+func (b *GcsStateFileBroker) put(ctx context.Context, client *storage.Service, stateFile infra_sdk.StateFile, generation int64) error {
+	raw, err := json.MarshalIndent(stateFile, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding state file: %w", err)
+	}
+	obj := &storage.Object{Name: b.StateObjectName(), ContentType: "application/json"}
+	_, err = client.Objects.Insert(b.Bucket, obj).Media(bytes.NewReader(raw)).IfGenerationMatch(generation).Context(ctx).Do()
+	return err
+}
+
+func (b *GcsStateFileBroker) readLock(ctx context.Context, client *storage.Service) (infra_sdk.StateLockInfo, int64, error) {
+	info := infra_sdk.StateLockInfo{}
+	raw, generation, err := b.download(ctx, client, b.LockObjectName())
+	if err != nil {
+		return info, 0, err
+	}
+	err = json.Unmarshal(raw, &info)
+	return info, generation, err
+}
+
+func (b *GcsStateFileBroker) download(ctx context.Context, client *storage.Service, name string) ([]byte, int64, error) {
+	resp, err := client.Objects.Get(b.Bucket, name).Context(ctx).Download()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	generation, _ := strconv.ParseInt(resp.Header.Get("X-Goog-Generation"), 10, 64)
+	return raw, generation, nil
+}
+
+func isHttpStatus(err error, code int) bool {
+	var gerr *googleapi.Error
+	return errors.As(err, &gerr) && gerr.Code == code
+}
@@ -0,0 +1,224 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+)
+
+type testAccessor struct{}
+
+func (testAccessor) GetTokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}), nil
+}
+
+func (testAccessor) GcpProjectId() string { return "project" }
+
+type gcsObject struct {
+	data       []byte
+	generation int64
+}
+
+// gcsServer is a minimal implementation of the GCS json api used by GcsStateFileBroker
+// Every write honors the ifGenerationMatch precondition
+type gcsServer struct {
+	mu         sync.Mutex
+	objects    map[string]gcsObject
+	generation int64
+	// afterGet is called after an object is downloaded; this allows tests to simulate a concurrent write
+	afterGet func(name string)
+}
+
+func newGcsServer() *gcsServer {
+	return &gcsServer{objects: map[string]gcsObject{}}
+}
+
+func (s *gcsServer) write(name string, data []byte) gcsObject {
+	s.generation++
+	obj := gcsObject{data: data, generation: s.generation}
+	s.objects[name] = obj
+	return obj
+}
+
+func (s *gcsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Object names contain '/', so they are parsed from the escaped path
+	_, objectPath, isObject := strings.Cut(r.URL.EscapedPath(), "/o/")
+	name, _ := url.PathUnescape(objectPath)
+	existing, exists := s.objects[name]
+
+	switch {
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/upload/"):
+		name, data, err := readMultipartUpload(r)
+		if err != nil {
+			writeGcsError(w, http.StatusBadRequest)
+			return
+		}
+		if !s.matchesGeneration(r, s.objects[name].generation) {
+			writeGcsError(w, http.StatusPreconditionFailed)
+			return
+		}
+		obj := s.write(name, data)
+		json.NewEncoder(w).Encode(map[string]any{"name": name, "generation": strconv.FormatInt(obj.generation, 10)})
+	case r.Method == http.MethodGet && !isObject:
+		json.NewEncoder(w).Encode(map[string]any{"name": "state"})
+	case r.Method == http.MethodGet:
+		if !exists {
+			writeGcsError(w, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-Goog-Generation", strconv.FormatInt(existing.generation, 10))
+		w.Write(existing.data)
+		if s.afterGet != nil {
+			s.afterGet(name)
+		}
+	case r.Method == http.MethodDelete:
+		if !exists {
+			writeGcsError(w, http.StatusNotFound)
+			return
+		}
+		if !s.matchesGeneration(r, existing.generation) {
+			writeGcsError(w, http.StatusPreconditionFailed)
+			return
+		}
+		delete(s.objects, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// matchesGeneration checks the ifGenerationMatch precondition; a generation of 0 means the object must not exist
+func (s *gcsServer) matchesGeneration(r *http.Request, generation int64) bool {
+	raw := r.URL.Query().Get("ifGenerationMatch")
+	if raw == "" {
+		return true
+	}
+	want, _ := strconv.ParseInt(raw, 10, 64)
+	return want == generation
+}
+
+// readMultipartUpload reads the object name from the metadata part and the data from the media part
+func readMultipartUpload(r *http.Request) (string, []byte, error) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return "", nil, err
+	}
+	reader := multipart.NewReader(r.Body, params["boundary"])
+	metadata, err := reader.NextPart()
+	if err != nil {
+		return "", nil, err
+	}
+	var obj struct{ Name string }
+	if err := json.NewDecoder(metadata).Decode(&obj); err != nil {
+		return "", nil, err
+	}
+	media, err := reader.NextPart()
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := io.ReadAll(media)
+	return obj.Name, data, err
+}
+
+func writeGcsError(w http.ResponseWriter, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	fmt.Fprintf(w, `{"error":{"code":%d,"message":"%s"}}`, code, http.StatusText(code))
+}
+
+func TestGcsStateFileBroker(t *testing.T) {
+	ctx := context.Background()
+	server := newGcsServer()
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	newBroker := func() *GcsStateFileBroker {
+		return &GcsStateFileBroker{
+			Accessor:      testAccessor{},
+			Bucket:        "state",
+			Prefix:        "app",
+			ClientOptions: []option.ClientOption{option.WithEndpoint(ts.URL + "/storage/v1/")},
+		}
+	}
+	broker := newBroker()
+
+	require.NoError(t, broker.Initialize(ctx))
+	initial, err := broker.Load(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, initial)
+	initialGeneration := server.objects["app/default.tfstate"].generation
+	// Initializing again does not overwrite the state file
+	require.NoError(t, newBroker().Initialize(ctx))
+	assert.Equal(t, initialGeneration, server.objects["app/default.tfstate"].generation)
+
+	assert.ErrorIs(t, broker.Save(ctx, *initial), infra_sdk.ErrStateNotLocked)
+
+	require.NoError(t, broker.Lock(ctx))
+	lock, ok := server.objects["app/default.tflock"]
+	require.True(t, ok, "lock file was not created")
+	var lockInfo infra_sdk.StateLockInfo
+	require.NoError(t, json.Unmarshal(lock.data, &lockInfo))
+	assert.Equal(t, broker.lockInfo.ID, lockInfo.ID)
+
+	var lockedErr infra_sdk.StateLockedError
+	other := newBroker()
+	require.ErrorAs(t, other.Lock(ctx), &lockedErr)
+	assert.Equal(t, broker.lockInfo.ID, lockedErr.Info.ID)
+	// A broker that failed to acquire the lock does not release it
+	require.NoError(t, other.Unlock(ctx))
+	assert.Contains(t, server.objects, "app/default.tflock")
+
+	require.NoError(t, broker.Save(ctx, *initial))
+	require.NoError(t, broker.Unlock(ctx))
+	assert.NotContains(t, server.objects, "app/default.tflock")
+
+	saved, err := broker.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, initial.Serial+1, saved.Serial)
+	assert.Equal(t, initial.Lineage, saved.Lineage)
+
+	t.Run("save precondition", func(t *testing.T) {
+		require.NoError(t, broker.Lock(ctx))
+		defer broker.Unlock(ctx)
+
+		// Simulate a write that happens between reading the current state and writing the next state
+		server.afterGet = func(name string) {
+			if name == "app/default.tfstate" {
+				server.write(name, server.objects[name].data)
+				server.afterGet = nil
+			}
+		}
+		err := broker.Save(ctx, *saved)
+		assert.ErrorContains(t, err, "state file was modified while saving")
+	})
+
+	t.Run("unlock after lock is replaced", func(t *testing.T) {
+		require.NoError(t, broker.Lock(ctx))
+		replaced := infra_sdk.NewStateLockInfo("", broker.StateObjectName())
+		raw, _ := json.Marshal(replaced)
+		server.write("app/default.tflock", raw)
+
+		assert.ErrorContains(t, broker.Unlock(ctx), "lock is held by someone else")
+		assert.Contains(t, server.objects, "app/default.tflock")
+		delete(server.objects, "app/default.tflock")
+	})
+}
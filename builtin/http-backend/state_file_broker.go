@@ -0,0 +1,269 @@
+package http_backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+)
+
+const (
+	defaultUpdateMethod = http.MethodPost
+	defaultLockMethod   = "LOCK"
+	defaultUnlockMethod = "UNLOCK"
+)
+
+var (
+	_ infra_sdk.StateFileBroker = &StateFileBroker{}
+	_ infra_sdk.StateFileLoader = &StateFileBroker{}
+)
+
+// StateFileBroker stores state on a server that implements terraform's http backend protocol
+// See https://developer.hashicorp.com/terraform/language/backend/http
+//
+// Locking is only performed against the server if LockAddress is set; otherwise, locks are only tracked in-process.
+type StateFileBroker struct {
+	// Address is the url of the state; state is retrieved with GET and saved with UpdateMethod
+	Address string
+	// UpdateMethod is the http method used to save state; if empty, POST is used
+	UpdateMethod string
+	LockAddress  string
+	// LockMethod is the http method used to lock state; if empty, LOCK is used
+	LockMethod    string
+	UnlockAddress string
+	// UnlockMethod is the http method used to unlock state; if empty, UNLOCK is used
+	UnlockMethod string
+	Username     string
+	Password     string
+	// Headers are added to every request
+	Headers map[string]string
+	// Client is used to send requests; if nil, http.DefaultClient is used
+	Client *http.Client
+	// Operation is recorded in the lock info; if empty, infra_sdk.StateLockOperationRefresh is used
+	Operation string
+
+	mu       sync.Mutex
+	lockInfo *infra_sdk.StateLockInfo
+}
+
+func (b *StateFileBroker) Initialize(ctx context.Context) error {
+	current, err := b.Load(ctx)
+	if err != nil || current != nil {
+		return err
+	}
+	if err := b.put(ctx, infra_sdk.NewStateFile()); err != nil {
+		return fmt.Errorf("error initializing state file: %w", err)
+	}
+	return nil
+}
+
+func (b *StateFileBroker) Lock(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.lockInfo != nil {
+		return nil
+	}
+
+	info := infra_sdk.NewStateLockInfo(b.Operation, b.Address)
+	if b.LockAddress != "" {
+		raw, err := json.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("error encoding lock info: %w", err)
+		}
+		resp, err := b.do(ctx, valueOrDefault(b.LockMethod, defaultLockMethod), b.LockAddress, raw)
+		if err != nil {
+			return fmt.Errorf("error acquiring state lock: %w", err)
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusConflict, http.StatusLocked:
+			// The server may respond with the lock info of the current holder
+			existing := infra_sdk.StateLockInfo{}
+			body, _ := io.ReadAll(resp.Body)
+			_ = json.Unmarshal(body, &existing)
+			return infra_sdk.StateLockedError{Info: existing}
+		default:
+			return fmt.Errorf("error acquiring state lock: %w", unexpectedStatus(resp))
+		}
+	}
+
+	b.lockInfo = &info
+	return nil
+}
+
+func (b *StateFileBroker) Unlock(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.lockInfo == nil {
+		return nil
+	}
+
+	if b.UnlockAddress != "" {
+		raw, err := json.Marshal(b.lockInfo)
+		if err != nil {
+			return fmt.Errorf("error encoding lock info: %w", err)
+		}
+		resp, err := b.do(ctx, valueOrDefault(b.UnlockMethod, defaultUnlockMethod), b.UnlockAddress, raw)
+		if err != nil {
+			return fmt.Errorf("error releasing state lock: %w", err)
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusConflict, http.StatusLocked:
+			return fmt.Errorf("error releasing state lock: lock is held by someone else: %w", unexpectedStatus(resp))
+		default:
+			return fmt.Errorf("error releasing state lock: %w", unexpectedStatus(resp))
+		}
+	}
+
+	b.lockInfo = nil
+	return nil
+}
+
+func (b *StateFileBroker) Load(ctx context.Context) (*infra_sdk.StateFile, error) {
+	resp, err := b.do(ctx, http.MethodGet, b.Address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving state file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent, http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("error retrieving state file: %w", unexpectedStatus(resp))
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var stateFile infra_sdk.StateFile
+	if err := json.Unmarshal(raw, &stateFile); err != nil {
+		return nil, fmt.Errorf("error decoding state file: %w", err)
+	}
+	return &stateFile, nil
+}
+
+func (b *StateFileBroker) Save(ctx context.Context, stateFile infra_sdk.StateFile) error {
+	b.mu.Lock()
+	locked := b.lockInfo != nil
+	b.mu.Unlock()
+	if !locked {
+		return infra_sdk.ErrStateNotLocked
+	}
+
+	current, err := b.Load(ctx)
+	if err != nil {
+		return err
+	}
+	next, err := infra_sdk.PrepareStateFileSave(current, stateFile)
+	if err != nil {
+		return err
+	}
+	if err := b.put(ctx, next); err != nil {
+		return fmt.Errorf("error saving state file: %w", err)
+	}
+	return nil
+}
+
+// put sends the state file to the server
+// If we hold a server lock, the lock id is sent in the ID query parameter
+func (b *StateFileBroker) put(ctx context.Context, stateFile infra_sdk.StateFile) error {
+	raw, err := json.MarshalIndent(stateFile, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding state file: %w", err)
+	}
+
+	address := b.Address
+	b.mu.Lock()
+	if b.lockInfo != nil && b.LockAddress != "" {
+		u, err := url.Parse(address)
+		if err != nil {
+			b.mu.Unlock()
+			return fmt.Errorf("invalid address: %w", err)
+		}
+		query := u.Query()
+		query.Set("ID", b.lockInfo.ID)
+		u.RawQuery = query.Encode()
+		address = u.String()
+	}
+	b.mu.Unlock()
+
+	resp, err := b.do(ctx, valueOrDefault(b.UpdateMethod, defaultUpdateMethod), address, raw)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusConflict, http.StatusLocked:
+		existing := infra_sdk.StateLockInfo{}
+		body, _ := io.ReadAll(resp.Body)
+		_ = json.Unmarshal(body, &existing)
+		return infra_sdk.StateLockedError{Info: existing}
+	default:
+		return unexpectedStatus(resp)
+	}
+}
+
+func (b *StateFileBroker) do(ctx context.Context, method, address string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, address, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		sum := md5.Sum(body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	}
+	for k, v := range b.Headers {
+		req.Header.Set(k, v)
+	}
+	if b.Username != "" || b.Password != "" {
+		req.SetBasicAuth(b.Username, b.Password)
+	}
+
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+func unexpectedStatus(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if len(body) > 0 {
+		return fmt.Errorf("unexpected http status %d: %s", resp.StatusCode, string(body))
+	}
+	return fmt.Errorf("unexpected http status %d", resp.StatusCode)
+}
+
+func valueOrDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
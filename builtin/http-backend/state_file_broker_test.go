@@ -0,0 +1,112 @@
+package http_backend
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stateServer is a minimal implementation of the terraform http backend protocol
+type stateServer struct {
+	mu       sync.Mutex
+	state    []byte
+	lock     *infra_sdk.StateLockInfo
+	updateId string
+}
+
+func (s *stateServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	switch r.Method {
+	case http.MethodGet:
+		if s.state == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write(s.state)
+	case http.MethodPost:
+		if s.lock != nil && r.URL.Query().Get("ID") != s.lock.ID {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(s.lock)
+			return
+		}
+		s.updateId = r.URL.Query().Get("ID")
+		s.state = body
+	case "LOCK":
+		if s.lock != nil {
+			w.WriteHeader(http.StatusLocked)
+			json.NewEncoder(w).Encode(s.lock)
+			return
+		}
+		s.lock = &infra_sdk.StateLockInfo{}
+		json.Unmarshal(body, s.lock)
+	case "UNLOCK":
+		info := infra_sdk.StateLockInfo{}
+		json.Unmarshal(body, &info)
+		if s.lock == nil || s.lock.ID != info.ID {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.lock = nil
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestStateFileBroker(t *testing.T) {
+	ctx := context.Background()
+	server := &stateServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	newBroker := func() *StateFileBroker {
+		return &StateFileBroker{
+			Address:       ts.URL + "/state",
+			LockAddress:   ts.URL + "/state",
+			UnlockAddress: ts.URL + "/state",
+			Username:      "user",
+			Password:      "pass",
+		}
+	}
+	broker := newBroker()
+
+	require.NoError(t, broker.Initialize(ctx))
+	initial, err := broker.Load(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, initial)
+
+	require.NoError(t, broker.Lock(ctx))
+	var lockedErr infra_sdk.StateLockedError
+	other := newBroker()
+	require.ErrorAs(t, other.Lock(ctx), &lockedErr)
+	assert.Equal(t, broker.lockInfo.ID, lockedErr.Info.ID)
+
+	require.NoError(t, broker.Save(ctx, *initial))
+	assert.Equal(t, broker.lockInfo.ID, server.updateId)
+	require.NoError(t, broker.Unlock(ctx))
+
+	saved, err := broker.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), saved.Serial)
+	assert.Equal(t, initial.Lineage, saved.Lineage)
+
+	// A foreign lineage is refused
+	require.NoError(t, other.Lock(ctx))
+	assert.ErrorIs(t, other.Save(ctx, infra_sdk.StateFile{Serial: 1, Lineage: "foreign"}), infra_sdk.ErrStateLineageConflict)
+	require.NoError(t, other.Unlock(ctx))
+}
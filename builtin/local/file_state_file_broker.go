@@ -0,0 +1,205 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+)
+
+const (
+	defaultStatePath = "terraform.tfstate"
+	backupSuffix     = ".backup"
+)
+
+var (
+	errLockHeld = errors.New("lock is held by another process")
+)
+
+var (
+	_ infra_sdk.StateFileBroker = &FileStateFileBroker{}
+	_ infra_sdk.StateFileLoader = &FileStateFileBroker{}
+)
+
+// FileStateFileBroker stores state in a file on the local filesystem using the same layout as terraform's local backend
+// The state file is locked with an OS file lock and lock info is written to `.<name>.lock.info` alongside it
+// Before each save, the previous state is copied to `<path>.backup`
+type FileStateFileBroker struct {
+	// Path is the location of the state file; if empty, "terraform.tfstate" is used
+	Path string
+	// Operation is recorded in the lock info; if empty, infra_sdk.StateLockOperationRefresh is used
+	Operation string
+
+	mu       sync.Mutex
+	file     *os.File
+	lockInfo *infra_sdk.StateLockInfo
+}
+
+func (b *FileStateFileBroker) statePath() string {
+	if b.Path == "" {
+		return defaultStatePath
+	}
+	return b.Path
+}
+
+// BackupPath returns the location of the backup that is written before each save
+func (b *FileStateFileBroker) BackupPath() string {
+	return b.statePath() + backupSuffix
+}
+
+func (b *FileStateFileBroker) lockInfoPath() string {
+	dir, name := filepath.Split(b.statePath())
+	return filepath.Join(dir, fmt.Sprintf(".%s.lock.info", name))
+}
+
+func (b *FileStateFileBroker) Initialize(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(b.statePath()), 0755); err != nil {
+		return fmt.Errorf("error creating state directory: %w", err)
+	}
+	f, err := os.OpenFile(b.statePath(), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error initializing state file: %w", err)
+	}
+	defer f.Close()
+	if err := writeStateFile(f, infra_sdk.NewStateFile()); err != nil {
+		return fmt.Errorf("error initializing state file: %w", err)
+	}
+	return nil
+}
+
+func (b *FileStateFileBroker) Lock(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.file != nil {
+		return nil
+	}
+
+	f, err := os.OpenFile(b.statePath(), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening state file: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		if errors.Is(err, errLockHeld) {
+			existing := infra_sdk.StateLockInfo{}
+			if raw, err := os.ReadFile(b.lockInfoPath()); err == nil {
+				_ = json.Unmarshal(raw, &existing)
+			}
+			return infra_sdk.StateLockedError{Info: existing}
+		}
+		return fmt.Errorf("error acquiring state lock: %w", err)
+	}
+
+	info := infra_sdk.NewStateLockInfo(b.Operation, b.statePath())
+	raw, _ := json.Marshal(info)
+	if err := os.WriteFile(b.lockInfoPath(), raw, 0644); err != nil {
+		unlockFile(f)
+		f.Close()
+		return fmt.Errorf("error writing lock info: %w", err)
+	}
+
+	b.file = f
+	b.lockInfo = &info
+	return nil
+}
+
+func (b *FileStateFileBroker) Unlock(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.file == nil {
+		return nil
+	}
+
+	// Remove the lock info before releasing the lock so that we never remove someone else's lock info
+	if err := os.Remove(b.lockInfoPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing lock info: %w", err)
+	}
+	err := unlockFile(b.file)
+	b.file.Close()
+	b.file, b.lockInfo = nil, nil
+	if err != nil {
+		return fmt.Errorf("error releasing state lock: %w", err)
+	}
+	return nil
+}
+
+func (b *FileStateFileBroker) Load(ctx context.Context) (*infra_sdk.StateFile, error) {
+	raw, err := os.ReadFile(b.statePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+	return decodeStateFile(raw)
+}
+
+func (b *FileStateFileBroker) Save(ctx context.Context, stateFile infra_sdk.StateFile) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.file == nil {
+		return infra_sdk.ErrStateNotLocked
+	}
+
+	// Read through the locked file handle since we write to it in place
+	if _, err := b.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error reading state file: %w", err)
+	}
+	raw, err := io.ReadAll(b.file)
+	if err != nil {
+		return fmt.Errorf("error reading state file: %w", err)
+	}
+	current, err := decodeStateFile(raw)
+	if err != nil {
+		return err
+	}
+	next, err := infra_sdk.PrepareStateFileSave(current, stateFile)
+	if err != nil {
+		return err
+	}
+
+	if len(raw) > 0 {
+		if err := os.WriteFile(b.BackupPath(), raw, 0644); err != nil {
+			return fmt.Errorf("error writing state backup: %w", err)
+		}
+	}
+
+	// The state file is rewritten in place because replacing the file would release our lock
+	if err := b.file.Truncate(0); err != nil {
+		return fmt.Errorf("error saving state file: %w", err)
+	}
+	if _, err := b.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error saving state file: %w", err)
+	}
+	if err := writeStateFile(b.file, next); err != nil {
+		return fmt.Errorf("error saving state file: %w", err)
+	}
+	return b.file.Sync()
+}
+
+func decodeStateFile(raw []byte) (*infra_sdk.StateFile, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var stateFile infra_sdk.StateFile
+	if err := json.Unmarshal(raw, &stateFile); err != nil {
+		return nil, fmt.Errorf("error decoding state file: %w", err)
+	}
+	return &stateFile, nil
+}
+
+func writeStateFile(w io.Writer, stateFile infra_sdk.StateFile) error {
+	raw, err := json.MarshalIndent(stateFile, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(raw)
+	return err
+}
@@ -0,0 +1,77 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStateFileBroker(t *testing.T) {
+	ctx := context.Background()
+	statePath := filepath.Join(t.TempDir(), "state", "terraform.tfstate")
+	broker := &FileStateFileBroker{Path: statePath}
+
+	require.NoError(t, broker.Initialize(ctx))
+	initial, err := broker.Load(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, initial)
+	assert.Equal(t, uint64(0), initial.Serial)
+
+	assert.ErrorIs(t, broker.Save(ctx, *initial), infra_sdk.ErrStateNotLocked)
+
+	require.NoError(t, broker.Lock(ctx))
+	assert.FileExists(t, filepath.Join(filepath.Dir(statePath), ".terraform.tfstate.lock.info"))
+
+	// A second broker must not be able to acquire the lock
+	other := &FileStateFileBroker{Path: statePath}
+	var lockedErr infra_sdk.StateLockedError
+	assert.ErrorAs(t, other.Lock(ctx), &lockedErr)
+
+	next := *initial
+	next.TerraformVersion = "1.9.0"
+	require.NoError(t, broker.Save(ctx, next))
+	require.NoError(t, broker.Unlock(ctx))
+
+	saved, err := broker.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), saved.Serial)
+	assert.Equal(t, initial.Lineage, saved.Lineage)
+	assert.Equal(t, "1.9.0", saved.TerraformVersion)
+
+	backup, err := os.ReadFile(broker.BackupPath())
+	require.NoError(t, err)
+	decoded, err := decodeStateFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), decoded.Serial)
+
+	// After unlocking, the other broker can acquire the lock
+	require.NoError(t, other.Lock(ctx))
+	require.NoError(t, other.Unlock(ctx))
+}
+
+func TestMemoryStateFileBroker(t *testing.T) {
+	ctx := context.Background()
+	broker := &MemoryStateFileBroker{}
+
+	require.NoError(t, broker.Initialize(ctx))
+	require.NoError(t, broker.Lock(ctx))
+	var lockedErr infra_sdk.StateLockedError
+	assert.ErrorAs(t, broker.Lock(ctx), &lockedErr)
+	require.NoError(t, broker.Save(ctx, infra_sdk.StateFile{}))
+	require.NoError(t, broker.Unlock(ctx))
+
+	assert.Equal(t, uint64(1), broker.StateFile.Serial)
+	assert.Equal(t, 2, broker.CallCount(MemoryCallLock))
+	assert.Equal(t, 1, broker.CallCount(MemoryCallSave))
+	assert.False(t, broker.IsLocked())
+
+	broker.Errors = map[string]error{MemoryCallLoad: assert.AnError}
+	_, err := broker.Load(ctx)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, assert.AnError, broker.Calls[len(broker.Calls)-1].Err)
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package local
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile acquires an exclusive, non-blocking lock on f
+// lockFile returns errLockHeld if another process holds the lock
+func lockFile(f *os.File) error {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return errLockHeld
+	}
+	return err
+}
+
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
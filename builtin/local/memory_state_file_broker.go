@@ -0,0 +1,140 @@
+package local
+
+import (
+	"context"
+	"sync"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+)
+
+const (
+	MemoryCallLock       = "Lock"
+	MemoryCallUnlock     = "Unlock"
+	MemoryCallInitialize = "Initialize"
+	MemoryCallLoad       = "Load"
+	MemoryCallSave       = "Save"
+)
+
+var (
+	_ infra_sdk.StateFileBroker = &MemoryStateFileBroker{}
+	_ infra_sdk.StateFileLoader = &MemoryStateFileBroker{}
+)
+
+// MemoryStateFileBrokerCall records a single call to a MemoryStateFileBroker
+type MemoryStateFileBrokerCall struct {
+	Method string
+	// StateFile is the state file passed to Save
+	StateFile *infra_sdk.StateFile
+	Err       error
+}
+
+// MemoryStateFileBroker keeps state in memory and records every call
+// This is intended for unit tests and offline refreshes
+type MemoryStateFileBroker struct {
+	// StateFile is the current state; it may be seeded before use
+	StateFile *infra_sdk.StateFile
+	// Errors forces a method (e.g. MemoryCallSave) to fail with the specified error
+	Errors map[string]error
+	// Calls contains every call in the order they were made
+	Calls []MemoryStateFileBrokerCall
+
+	mu     sync.Mutex
+	locked bool
+}
+
+// CallCount returns the number of calls made to method
+func (b *MemoryStateFileBroker) CallCount(method string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	count := 0
+	for _, call := range b.Calls {
+		if call.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+func (b *MemoryStateFileBroker) IsLocked() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.locked
+}
+
+func (b *MemoryStateFileBroker) Lock(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.errorFor(MemoryCallLock)
+	if err == nil {
+		if b.locked {
+			err = infra_sdk.StateLockedError{}
+		} else {
+			b.locked = true
+		}
+	}
+	b.record(MemoryStateFileBrokerCall{Method: MemoryCallLock, Err: err})
+	return err
+}
+
+func (b *MemoryStateFileBroker) Unlock(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.errorFor(MemoryCallUnlock)
+	if err == nil {
+		b.locked = false
+	}
+	b.record(MemoryStateFileBrokerCall{Method: MemoryCallUnlock, Err: err})
+	return err
+}
+
+func (b *MemoryStateFileBroker) Initialize(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.errorFor(MemoryCallInitialize)
+	if err == nil && b.StateFile == nil {
+		stateFile := infra_sdk.NewStateFile()
+		b.StateFile = &stateFile
+	}
+	b.record(MemoryStateFileBrokerCall{Method: MemoryCallInitialize, Err: err})
+	return err
+}
+
+func (b *MemoryStateFileBroker) Load(ctx context.Context) (*infra_sdk.StateFile, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.errorFor(MemoryCallLoad)
+	b.record(MemoryStateFileBrokerCall{Method: MemoryCallLoad, Err: err})
+	if err != nil || b.StateFile == nil {
+		return nil, err
+	}
+	stateFile := *b.StateFile
+	return &stateFile, nil
+}
+
+func (b *MemoryStateFileBroker) Save(ctx context.Context, stateFile infra_sdk.StateFile) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.errorFor(MemoryCallSave)
+	if err == nil && !b.locked {
+		err = infra_sdk.ErrStateNotLocked
+	}
+	if err == nil {
+		var next infra_sdk.StateFile
+		if next, err = infra_sdk.PrepareStateFileSave(b.StateFile, stateFile); err == nil {
+			b.StateFile = &next
+		}
+	}
+	b.record(MemoryStateFileBrokerCall{Method: MemoryCallSave, StateFile: &stateFile, Err: err})
+	return err
+}
+
+func (b *MemoryStateFileBroker) errorFor(method string) error {
+	if b.Errors == nil {
+		return nil
+	}
+	return b.Errors[method]
+}
+
+func (b *MemoryStateFileBroker) record(call MemoryStateFileBrokerCall) {
+	b.Calls = append(b.Calls, call)
+}
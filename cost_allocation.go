@@ -0,0 +1,220 @@
+package infra_sdk
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var (
+	// unallocatedResourceIds are resource id values that the cloud providers use for spend that is not tied to a resource
+	unallocatedResourceIds = []string{"", "NoResourceId", "No ResourceId"}
+)
+
+// CostAllocation is the cost of a CostResult allocated to scanned resources
+type CostAllocation struct {
+	MetricName string         `json:"metricName"`
+	Resources  []ResourceCost `json:"resources"`
+	// Unallocated is the spend that could not be matched to a scanned resource
+	Unallocated UnallocatedCost `json:"unallocated"`
+}
+
+// Find returns the cost allocated to the resource with the specified ScanResource.UniqueId
+func (a CostAllocation) Find(uniqueId string) (ResourceCost, bool) {
+	for _, cur := range a.Resources {
+		if cur.Resource.UniqueId == uniqueId {
+			return cur, true
+		}
+	}
+	return ResourceCost{}, false
+}
+
+// AllocatedCost is the total cost and cost over time for a resource or bucket
+// Points are sorted by Start and contain the sum of every matching series for that window
+type AllocatedCost struct {
+	Unit   string                `json:"unit"`
+	Total  string                `json:"total"`
+	Points []CostSeriesDatapoint `json:"points"`
+}
+
+type ResourceCost struct {
+	Resource ScanResource `json:"resource"`
+	AllocatedCost
+}
+
+type UnallocatedCost struct {
+	// ResourceIds are the resource ids in the cost result that did not match a scanned resource
+	// Spend that is not tied to any resource (e.g. support, tax) is included in the cost, but has no resource id
+	ResourceIds []string `json:"resourceIds"`
+	AllocatedCost
+}
+
+// AllocateCosts assigns the cost in result to each scanned resource
+// result must be grouped by UniversalDimensionResourceId; other group keys (e.g. tags) are summed together
+// Only series for metricName are allocated
+// Every scanned resource is included in the allocation, even if it has no cost
+func AllocateCosts(result CostResult, metricName string, resources []ScanResource) (CostAllocation, error) {
+	allocation := CostAllocation{
+		MetricName: metricName,
+		Resources:  make([]ResourceCost, len(resources)),
+		Unallocated: UnallocatedCost{
+			ResourceIds:   []string{},
+			AllocatedCost: AllocatedCost{Total: "0", Points: []CostSeriesDatapoint{}},
+		},
+	}
+	for i, resource := range resources {
+		allocation.Resources[i] = ResourceCost{
+			Resource:      resource,
+			AllocatedCost: AllocatedCost{Total: "0", Points: []CostSeriesDatapoint{}},
+		}
+	}
+	matcher := newCostResourceMatcher(resources)
+
+	seriesKeys := make([]string, 0, len(result.Series))
+	for key := range result.Series {
+		seriesKeys = append(seriesKeys, key)
+	}
+	sort.Strings(seriesKeys)
+
+	for _, key := range seriesKeys {
+		series := result.Series[key]
+		if series.MetricName != metricName {
+			continue
+		}
+		resourceId, ok := series.GroupKeys.resourceId()
+		if !ok {
+			return allocation, fmt.Errorf("cost series %q is not grouped by %s", key, UniversalDimensionResourceId)
+		}
+
+		target := &allocation.Unallocated.AllocatedCost
+		if idx, found := matcher.match(resourceId); found {
+			target = &allocation.Resources[idx].AllocatedCost
+		} else if !isUnallocatedResourceId(resourceId) && !slices.Contains(allocation.Unallocated.ResourceIds, resourceId) {
+			allocation.Unallocated.ResourceIds = append(allocation.Unallocated.ResourceIds, resourceId)
+		}
+		for _, point := range series.Points {
+			if err := target.add(point); err != nil {
+				return allocation, fmt.Errorf("error allocating cost for %q: %w", resourceId, err)
+			}
+		}
+	}
+	sort.Strings(allocation.Unallocated.ResourceIds)
+	return allocation, nil
+}
+
+func (c *AllocatedCost) add(point CostSeriesDatapoint) error {
+	value, ok := new(big.Rat).SetString(point.Value)
+	if !ok {
+		return fmt.Errorf("invalid cost value %q", point.Value)
+	}
+	if c.Unit != "" && point.Unit != "" && point.Unit != c.Unit {
+		return fmt.Errorf("cannot add cost in %s to cost in %s", point.Unit, c.Unit)
+	}
+	c.Total = sumCostValues(c.Total, value)
+	if c.Unit == "" {
+		c.Unit = point.Unit
+	}
+
+	for i, cur := range c.Points {
+		if cur.Start.Equal(point.Start) && cur.End.Equal(point.End) {
+			c.Points[i].Value = sumCostValues(cur.Value, value)
+			return nil
+		}
+	}
+	point.Value = sumCostValues("0", value)
+	idx := sort.Search(len(c.Points), func(i int) bool { return c.Points[i].Start.After(point.Start) })
+	c.Points = slices.Insert(c.Points, idx, point)
+	return nil
+}
+
+func (s CostSeriesGroupKeys) resourceId() (string, bool) {
+	for _, key := range s {
+		if key.TagKey == "" && key.Name == UniversalDimensionResourceId {
+			return key.Value, true
+		}
+	}
+	return "", false
+}
+
+// costResourceMatcher matches resource ids in a cost result to scanned resources
+// Cost providers do not always report the same identifier as the scanner
+//   - AWS reports an ARN for most resources, but only the bucket name for S3 and the instance id for EC2
+//   - Azure reports resource ids in lowercase
+type costResourceMatcher struct {
+	byUniqueId map[string]int
+	// byShortId contains the last segment of the ARN resource of each resource (e.g. bucket name, instance id)
+	// A short identifier shared by multiple resources is ambiguous and is recorded as -1
+	byShortId map[string]int
+}
+
+func newCostResourceMatcher(resources []ScanResource) costResourceMatcher {
+	m := costResourceMatcher{byUniqueId: map[string]int{}, byShortId: map[string]int{}}
+	for i, resource := range resources {
+		m.byUniqueId[normalizeUniqueId(resource.UniqueId)] = i
+	}
+	for i, resource := range resources {
+		shortId, ok := arnShortResourceId(resource.UniqueId)
+		if !ok {
+			continue
+		}
+		shortId = normalizeUniqueId(shortId)
+		if existing, ok := m.byShortId[shortId]; ok && existing != i {
+			m.byShortId[shortId] = -1
+		} else {
+			m.byShortId[shortId] = i
+		}
+	}
+	return m
+}
+
+func (m costResourceMatcher) match(resourceId string) (int, bool) {
+	if isUnallocatedResourceId(resourceId) {
+		return 0, false
+	}
+	if idx, ok := m.byUniqueId[normalizeUniqueId(resourceId)]; ok {
+		return idx, true
+	}
+	candidate := resourceId
+	if shortId, ok := arnShortResourceId(resourceId); ok {
+		candidate = shortId
+	}
+	if idx, ok := m.byShortId[normalizeUniqueId(candidate)]; ok && idx >= 0 {
+		return idx, true
+	}
+	return 0, false
+}
+
+// arnShortResourceId returns the last segment of the resource in an ARN
+// e.g. `arn:aws:s3:::my-bucket` => `my-bucket`, `arn:aws:ec2:us-east-1:123:instance/i-abc` => `i-abc`
+// This returns false if id is not an ARN
+func arnShortResourceId(id string) (string, bool) {
+	// arn:partition:service:region:account-id:resource
+	tokens := strings.SplitN(id, ":", 6)
+	if len(tokens) != 6 || tokens[0] != "arn" || tokens[5] == "" {
+		return "", false
+	}
+	resource := tokens[5]
+	if idx := strings.LastIndexAny(resource, "/:"); idx >= 0 {
+		resource = resource[idx+1:]
+	}
+	return resource, resource != ""
+}
+
+func isUnallocatedResourceId(resourceId string) bool {
+	return slices.Contains(unallocatedResourceIds, resourceId)
+}
+
+// sumCostValues adds value to a decimal string without losing precision
+func sumCostValues(total string, value *big.Rat) string {
+	sum, ok := new(big.Rat).SetString(total)
+	if !ok {
+		sum = new(big.Rat)
+	}
+	sum.Add(sum, value)
+	// Cost values are never more precise than 10 decimal places
+	formatted := sum.FloatString(10)
+	formatted = strings.TrimRight(formatted, "0")
+	return strings.TrimSuffix(formatted, ".")
+}
@@ -0,0 +1,112 @@
+package infra_sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocateCosts(t *testing.T) {
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	point := func(start time.Time, value string) CostSeriesDatapoint {
+		return CostSeriesDatapoint{Start: start, End: start.AddDate(0, 0, 1), Unit: "USD", Value: value}
+	}
+	byResource := func(resourceId string, env string) CostSeriesGroupKeys {
+		return CostSeriesGroupKeys{
+			{Name: UniversalDimensionResourceId, Value: resourceId},
+			{TagKey: UniversalTagEnv, Value: env},
+		}
+	}
+
+	result := NewCostResult()
+	result.AddDatapoint("UnblendedCost", byResource("arn:aws:rds:us-east-1:123:db:main", "prod"), point(day1, "1.1"))
+	result.AddDatapoint("UnblendedCost", byResource("arn:aws:rds:us-east-1:123:db:main", "prod"), point(day2, "2.2"))
+	result.AddDatapoint("UnblendedCost", byResource("arn:aws:rds:us-east-1:123:db:main", ""), point(day1, "0.2"))
+	result.AddDatapoint("UnblendedCost", byResource("logs-bucket", "prod"), point(day1, "0.5"))
+	result.AddDatapoint("UnblendedCost", byResource("i-0abc", "prod"), point(day1, "3"))
+	result.AddDatapoint("UnblendedCost", byResource("NoResourceId", ""), point(day1, "10"))
+	result.AddDatapoint("BlendedCost", byResource("logs-bucket", "prod"), point(day1, "99"))
+
+	resources := []ScanResource{
+		{UniqueId: "arn:aws:rds:us-east-1:123:db:main", Name: "main"},
+		{UniqueId: "arn:aws:s3:::logs-bucket", Name: "logs-bucket"},
+		{UniqueId: "arn:aws:sqs:us-east-1:123:idle", Name: "idle"},
+	}
+
+	allocation, err := AllocateCosts(*result, "UnblendedCost", resources)
+	require.NoError(t, err)
+	require.Len(t, allocation.Resources, 3)
+
+	db, ok := allocation.Find("arn:aws:rds:us-east-1:123:db:main")
+	require.True(t, ok)
+	assert.Equal(t, "3.5", db.Total)
+	assert.Equal(t, "USD", db.Unit)
+	assert.Equal(t, []CostSeriesDatapoint{point(day1, "1.3"), point(day2, "2.2")}, db.Points)
+
+	bucket, ok := allocation.Find("arn:aws:s3:::logs-bucket")
+	require.True(t, ok)
+	assert.Equal(t, "0.5", bucket.Total)
+
+	idle, ok := allocation.Find("arn:aws:sqs:us-east-1:123:idle")
+	require.True(t, ok)
+	assert.Equal(t, "0", idle.Total)
+	assert.Empty(t, idle.Points)
+
+	assert.Equal(t, "13", allocation.Unallocated.Total)
+	assert.Equal(t, []string{"i-0abc"}, allocation.Unallocated.ResourceIds)
+	assert.Equal(t, []CostSeriesDatapoint{point(day1, "13")}, allocation.Unallocated.Points)
+}
+
+func TestAllocateCosts_NotGroupedByResource(t *testing.T) {
+	result := NewCostResult()
+	result.AddDatapoint("UnblendedCost", CostSeriesGroupKeys{{TagKey: UniversalTagEnv, Value: "prod"}}, CostSeriesDatapoint{Value: "1"})
+
+	_, err := AllocateCosts(*result, "UnblendedCost", nil)
+	assert.ErrorContains(t, err, "is not grouped by "+UniversalDimensionResourceId)
+}
+
+func TestAllocateCosts_ShortIds(t *testing.T) {
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	point := CostSeriesDatapoint{Start: day1, End: day1.AddDate(0, 0, 1), Unit: "USD", Value: "1"}
+	byResource := func(resourceId string) CostSeriesGroupKeys {
+		return CostSeriesGroupKeys{{Name: UniversalDimensionResourceId, Value: resourceId}}
+	}
+
+	result := NewCostResult()
+	result.AddDatapoint("UnblendedCost", byResource("i-0abc"), point)
+	result.AddDatapoint("UnblendedCost", byResource("web"), point)
+	result.AddDatapoint("UnblendedCost", byResource("shared"), point)
+
+	resources := []ScanResource{
+		{UniqueId: "arn:aws:ec2:us-east-1:123:instance/i-0abc", Name: "api"},
+		// Names are not unique identifiers, so a resource is never matched by name
+		{UniqueId: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Web/sites/web", Name: "web"},
+		// A short id shared by multiple resources is ambiguous
+		{UniqueId: "arn:aws:sqs:us-east-1:123:shared", Name: "shared"},
+		{UniqueId: "arn:aws:sns:us-east-1:123:shared", Name: "shared"},
+	}
+
+	allocation, err := AllocateCosts(*result, "UnblendedCost", resources)
+	require.NoError(t, err)
+
+	instance, ok := allocation.Find("arn:aws:ec2:us-east-1:123:instance/i-0abc")
+	require.True(t, ok)
+	assert.Equal(t, "1", instance.Total)
+	assert.Equal(t, []string{"shared", "web"}, allocation.Unallocated.ResourceIds)
+}
+
+func TestAllocateCosts_MixedUnits(t *testing.T) {
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	byEnv := func(env string) CostSeriesGroupKeys {
+		return CostSeriesGroupKeys{{Name: UniversalDimensionResourceId, Value: "arn:aws:s3:::logs"}, {TagKey: UniversalTagEnv, Value: env}}
+	}
+	result := NewCostResult()
+	result.AddDatapoint("UnblendedCost", byEnv("prod"), CostSeriesDatapoint{Start: day1, End: day1.AddDate(0, 0, 1), Unit: "USD", Value: "1"})
+	result.AddDatapoint("UnblendedCost", byEnv("dev"), CostSeriesDatapoint{Start: day1, End: day1.AddDate(0, 0, 1), Unit: "EUR", Value: "1"})
+
+	_, err := AllocateCosts(*result, "UnblendedCost", []ScanResource{{UniqueId: "arn:aws:s3:::logs"}})
+	assert.ErrorContains(t, err, "cannot add cost in")
+}
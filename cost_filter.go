@@ -0,0 +1,124 @@
+package infra_sdk
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type CostFilterMatchOption string
+
+const (
+	// CostFilterMatchEquals matches costs where the value equals one of the values
+	CostFilterMatchEquals CostFilterMatchOption = "equals"
+	// CostFilterMatchAbsent matches costs that do not have the tag or dimension
+	CostFilterMatchAbsent CostFilterMatchOption = "absent"
+	// CostFilterMatchStartsWith matches costs where the value starts with one of the values
+	CostFilterMatchStartsWith CostFilterMatchOption = "starts-with"
+	// CostFilterMatchCaseInsensitive modifies the other match options to ignore case
+	CostFilterMatchCaseInsensitive CostFilterMatchOption = "case-insensitive"
+)
+
+var (
+	validCostFilterMatchOptions = []CostFilterMatchOption{
+		CostFilterMatchEquals,
+		CostFilterMatchAbsent,
+		CostFilterMatchStartsWith,
+		CostFilterMatchCaseInsensitive,
+	}
+)
+
+// CostFilter is a boolean expression over tags and dimensions that filters costs
+// Exactly one of And, Or, Not, Tag, or Dimension must be set
+//
+// For example, to exclude credits and refunds from the "dev" env:
+//
+//	CostFilter{And: []CostFilter{
+//	  {Tag: &CostFilterCondition{Key: UniversalTagEnv, Values: []string{"dev"}}},
+//	  {Not: &CostFilter{Dimension: &CostFilterCondition{Key: UniversalDimensionChargeType, Values: []string{"Credit", "Refund"}}}},
+//	}}
+type CostFilter struct {
+	And       []CostFilter         `json:"and,omitempty"`
+	Or        []CostFilter         `json:"or,omitempty"`
+	Not       *CostFilter          `json:"not,omitempty"`
+	Tag       *CostFilterCondition `json:"tag,omitempty"`
+	Dimension *CostFilterCondition `json:"dimension,omitempty"`
+}
+
+// CostFilterCondition matches the values of a tag or dimension
+// Key is a universal tag (e.g. UniversalTagEnv) or universal dimension (e.g. UniversalDimensionService)
+// Values are compared to the values reported by the cloud provider
+// If MatchOptions is empty, CostFilterMatchEquals is used
+type CostFilterCondition struct {
+	Key          string                  `json:"key"`
+	Values       []string                `json:"values"`
+	MatchOptions []CostFilterMatchOption `json:"matchOptions,omitempty"`
+}
+
+// Validate checks that every node in the expression sets exactly one operand with valid match options
+func (f CostFilter) Validate() error {
+	operands := 0
+	for _, set := range []bool{len(f.And) > 0, len(f.Or) > 0, f.Not != nil, f.Tag != nil, f.Dimension != nil} {
+		if set {
+			operands++
+		}
+	}
+	if operands != 1 {
+		return errors.New("cost filter must set exactly one of and, or, not, tag, or dimension")
+	}
+
+	for _, cur := range slices.Concat(f.And, f.Or) {
+		if err := cur.Validate(); err != nil {
+			return err
+		}
+	}
+	if f.Not != nil {
+		return f.Not.Validate()
+	}
+	if f.Tag != nil {
+		return f.Tag.validate()
+	}
+	if f.Dimension != nil {
+		return f.Dimension.validate()
+	}
+	return nil
+}
+
+func (c CostFilterCondition) validate() error {
+	if c.Key == "" {
+		return errors.New("cost filter condition is missing a key")
+	}
+	for _, option := range c.MatchOptions {
+		if !slices.Contains(validCostFilterMatchOptions, option) {
+			return fmt.Errorf("cost filter condition %q has an unsupported match option %q", c.Key, option)
+		}
+	}
+	if len(c.Values) == 0 && !slices.Contains(c.MatchOptions, CostFilterMatchAbsent) {
+		return fmt.Errorf("cost filter condition %q requires at least one value", c.Key)
+	}
+	return nil
+}
+
+// FilterExpression combines FilterTags and Filter into a single expression
+// Each FilterTag becomes a tag condition that is AND-ed with Filter
+// This returns nil if the query has no filters
+func (q CostQuery) FilterExpression() *CostFilter {
+	var filters []CostFilter
+	for _, filterTag := range q.FilterTags {
+		filters = append(filters, CostFilter{
+			Tag: &CostFilterCondition{Key: filterTag.Key, Values: filterTag.Values},
+		})
+	}
+	if q.Filter != nil {
+		filters = append(filters, *q.Filter)
+	}
+
+	switch len(filters) {
+	case 0:
+		return nil
+	case 1:
+		return &filters[0]
+	default:
+		return &CostFilter{And: filters}
+	}
+}
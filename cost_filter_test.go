@@ -0,0 +1,87 @@
+package infra_sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostFilter_Validate(t *testing.T) {
+	tag := &CostFilterCondition{Key: UniversalTagEnv, Values: []string{"dev"}}
+
+	tests := []struct {
+		name   string
+		filter CostFilter
+		errMsg string
+	}{
+		{
+			name: "nested expression",
+			filter: CostFilter{And: []CostFilter{
+				{Tag: tag},
+				{Not: &CostFilter{Dimension: &CostFilterCondition{Key: UniversalDimensionChargeType, Values: []string{"Credit", "Refund"}}}},
+				{Or: []CostFilter{
+					{Dimension: &CostFilterCondition{Key: UniversalDimensionRegion, Values: []string{"us-"}, MatchOptions: []CostFilterMatchOption{CostFilterMatchStartsWith}}},
+					{Tag: &CostFilterCondition{Key: UniversalTagBlock, MatchOptions: []CostFilterMatchOption{CostFilterMatchAbsent}}},
+				}},
+			}},
+		},
+		{
+			name:   "empty",
+			filter: CostFilter{},
+			errMsg: "exactly one of",
+		},
+		{
+			name:   "multiple operands",
+			filter: CostFilter{Tag: tag, Not: &CostFilter{Tag: tag}},
+			errMsg: "exactly one of",
+		},
+		{
+			name:   "invalid nested filter",
+			filter: CostFilter{Or: []CostFilter{{Tag: tag}, {}}},
+			errMsg: "exactly one of",
+		},
+		{
+			name:   "missing key",
+			filter: CostFilter{Tag: &CostFilterCondition{Values: []string{"dev"}}},
+			errMsg: "missing a key",
+		},
+		{
+			name:   "missing values",
+			filter: CostFilter{Dimension: &CostFilterCondition{Key: UniversalDimensionService}},
+			errMsg: "requires at least one value",
+		},
+		{
+			name:   "unsupported match option",
+			filter: CostFilter{Tag: &CostFilterCondition{Key: UniversalTagEnv, Values: []string{"dev"}, MatchOptions: []CostFilterMatchOption{"contains"}}},
+			errMsg: `unsupported match option "contains"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestCostQuery_FilterExpression(t *testing.T) {
+	notCredits := CostFilter{Not: &CostFilter{Dimension: &CostFilterCondition{Key: UniversalDimensionChargeType, Values: []string{"Credit"}}}}
+
+	assert.Nil(t, CostQuery{}.FilterExpression())
+
+	assert.Equal(t, &notCredits, CostQuery{Filter: &notCredits}.FilterExpression())
+
+	got := CostQuery{
+		FilterTags: []CostFilterTag{{Key: UniversalTagEnv, Values: []string{"dev"}}},
+		Filter:     &notCredits,
+	}.FilterExpression()
+	assert.Equal(t, &CostFilter{And: []CostFilter{
+		{Tag: &CostFilterCondition{Key: UniversalTagEnv, Values: []string{"dev"}}},
+		notCredits,
+	}}, got)
+}
@@ -0,0 +1,75 @@
+package infra_sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type CostForecaster interface {
+	GetForecast(ctx context.Context, query CostForecastQuery) (*CostForecast, error)
+}
+
+// CostForecastQuery requests a forecast for the window [Start, End)
+// Filters, group by, and metrics are interpreted the same as a CostQuery
+type CostForecastQuery struct {
+	CostQuery
+	// PredictionIntervalLevel is the confidence level (e.g. 80 for 80%) of the prediction interval bounds
+	// If 0, DefaultPredictionIntervalLevel is used
+	PredictionIntervalLevel int `json:"predictionIntervalLevel"`
+}
+
+const (
+	DefaultPredictionIntervalLevel = 80
+)
+
+// IntervalLevel returns PredictionIntervalLevel or DefaultPredictionIntervalLevel if not set
+func (q CostForecastQuery) IntervalLevel() int {
+	if q.PredictionIntervalLevel == 0 {
+		return DefaultPredictionIntervalLevel
+	}
+	return q.PredictionIntervalLevel
+}
+
+type CostForecast struct {
+	Series map[string]CostForecastSeries `json:"series"`
+}
+
+func NewCostForecast() *CostForecast {
+	return &CostForecast{
+		Series: map[string]CostForecastSeries{},
+	}
+}
+
+// AddDatapoint adds a forecast datapoint to the series identified by metricName and groupKeys
+// Series are keyed the same as CostResult so that forecasts line up with actual costs
+func (f *CostForecast) AddDatapoint(metricName string, groupKeys CostSeriesGroupKeys, datapoint CostForecastDatapoint) {
+	seriesKey := fmt.Sprintf("%s:%s", groupKeys.UniqueIdentifier(), metricName)
+	cur, ok := f.Series[seriesKey]
+	if !ok {
+		cur = CostForecastSeries{
+			MetricName: metricName,
+			GroupKeys:  groupKeys,
+			Points:     []CostForecastDatapoint{},
+		}
+	}
+	cur.Points = append(cur.Points, datapoint)
+	f.Series[seriesKey] = cur
+}
+
+type CostForecastSeries struct {
+	MetricName string                  `json:"metricName"`
+	GroupKeys  CostSeriesGroupKeys     `json:"groupKeys"`
+	Points     []CostForecastDatapoint `json:"points"`
+}
+
+// CostForecastDatapoint is the forecasted cost for the period between Start and End
+// Value is the mean forecast; LowerBound and UpperBound are the prediction interval
+type CostForecastDatapoint struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Unit       string    `json:"unit"`
+	Value      string    `json:"value"`
+	LowerBound string    `json:"lowerBound"`
+	UpperBound string    `json:"upperBound"`
+}
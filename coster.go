@@ -26,6 +26,26 @@ type CostQuery struct {
 	Granularity CostGranularity      `json:"granularity"`
 	FilterTags  []CostFilterTag      `json:"filterTags"`
 	GroupBy     CostGroupIdentifiers `json:"groupBy"`
+	// Filter is an expression over tags and dimensions that is combined with FilterTags
+	Filter *CostFilter `json:"filter,omitempty"`
+	// Metrics is a list of universal metrics (e.g. UniversalMetricAmortizedCost)
+	// Each metric is reported as a separate CostSeries; if empty, UniversalMetricUnblendedCost is used
+	Metrics []string `json:"metrics"`
+}
+
+// UniqueMetrics returns the requested metrics without duplicates
+// If no metrics are requested, UniversalMetricUnblendedCost is returned
+func (q CostQuery) UniqueMetrics() []string {
+	result := make([]string, 0, len(q.Metrics))
+	for _, metric := range q.Metrics {
+		if metric != "" && !slices.Contains(result, metric) {
+			result = append(result, metric)
+		}
+	}
+	if len(result) == 0 {
+		result = append(result, UniversalMetricUnblendedCost)
+	}
+	return result
 }
 
 type CostFilterTag struct {
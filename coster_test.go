@@ -0,0 +1,31 @@
+package infra_sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostQuery_UniqueMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics []string
+		want    []string
+	}{
+		{
+			name: "defaults to unblended cost",
+			want: []string{UniversalMetricUnblendedCost},
+		},
+		{
+			name:    "removes duplicates and preserves order",
+			metrics: []string{UniversalMetricAmortizedCost, "", UniversalMetricUnblendedCost, UniversalMetricAmortizedCost},
+			want:    []string{UniversalMetricAmortizedCost, UniversalMetricUnblendedCost},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CostQuery{Metrics: tt.metrics}.UniqueMetrics())
+		})
+	}
+}
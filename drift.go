@@ -0,0 +1,264 @@
+package infra_sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var (
+	// driftIdentifierAttributes are the state attributes that may contain the same identifier as ScanResource.UniqueId
+	// AWS resources are identified by ARN (or id for a handful of resources like VPCs)
+	// GCP and Azure resources are identified by their resource path in id or self_link
+	driftIdentifierAttributes = []string{"arn", "id", "self_link"}
+
+	// uniqueIdPrefixes are prefixes on ScanResource.UniqueId that do not appear in the terraform state
+	// aws: Route53 returns hosted zone ids as "/hostedzone/Z123", aws_route53_zone stores "Z123" as id
+	uniqueIdPrefixes = []string{"/hostedzone/"}
+)
+
+// DriftReport describes the differences between a StateFile and a live scan of the cloud account
+type DriftReport struct {
+	// Unmanaged are resources that exist in the cloud, but not in the state file
+	Unmanaged []ScanResource `json:"unmanaged"`
+	// Missing are resources that exist in the state file, but not in the cloud
+	Missing []DriftStateInstance `json:"missing"`
+	// Drifted are resources that exist in both, but have different attribute values
+	Drifted []DriftedResource `json:"drifted"`
+	// InSync are resources that exist in both and have no attribute differences
+	InSync []DriftedResource `json:"inSync"`
+}
+
+func (r DriftReport) HasDrift() bool {
+	return len(r.Unmanaged) > 0 || len(r.Missing) > 0 || len(r.Drifted) > 0
+}
+
+// DriftStateInstance identifies a single resource instance in a state file
+type DriftStateInstance struct {
+	Address  string `json:"address"`
+	Type     string `json:"type"`
+	Provider string `json:"provider"`
+	UniqueId string `json:"uniqueId"`
+}
+
+type DriftedResource struct {
+	State      DriftStateInstance `json:"state"`
+	Resource   ScanResource       `json:"resource"`
+	Attributes []DriftAttribute   `json:"attributes"`
+}
+
+// DriftAttribute is an attribute that has a different value in the state file than in the cloud
+type DriftAttribute struct {
+	Name       string `json:"name"`
+	StateValue any    `json:"stateValue"`
+	LiveValue  any    `json:"liveValue"`
+}
+
+// DriftDetector compares a StateFile against a live scan
+// State instances are matched to scanned resources when the arn, id, or self_link attribute matches ScanResource.UniqueId
+// Attributes are compared only when the scanned resource and the state instance both have an attribute with the same name
+// ScanResource.Tags are compared against tags_all/tags (or effective_labels/labels) in the state
+type DriftDetector struct {
+	// ResourceTypes limits which state resource types are reported as missing
+	// Scanners only cover a subset of resource types, so a resource type that is never scanned is always missing
+	// If empty, only the types produced by the scan are considered (see ResourceType)
+	ResourceTypes []string
+	// ResourceType resolves the terraform resource type of a scanned resource (e.g. aws_account.TerraformResourceType)
+	// When ResourceTypes is empty, the scan produces the types of its resources and of the state instances they match
+	// Without ResourceType, a type is only considered if at least one of its state instances matched a scanned resource
+	ResourceType func(resource ScanResource) string
+	// IgnoreAttributes are attribute names that are never compared
+	IgnoreAttributes []string
+}
+
+// DetectDrift compares a StateFile against a live scan using the default DriftDetector
+func DetectDrift(stateFile StateFile, resources []ScanResource) (DriftReport, error) {
+	return DriftDetector{}.Detect(stateFile, resources)
+}
+
+func (d DriftDetector) Detect(stateFile StateFile, resources []ScanResource) (DriftReport, error) {
+	report := DriftReport{
+		Unmanaged: []ScanResource{},
+		Missing:   []DriftStateInstance{},
+		Drifted:   []DriftedResource{},
+		InSync:    []DriftedResource{},
+	}
+
+	scanned := map[string]int{}
+	for i, resource := range resources {
+		scanned[normalizeUniqueId(resource.UniqueId)] = i
+	}
+	matched := make([]bool, len(resources))
+	// scannedTypes are the state resource types that the scan produces
+	scannedTypes := map[string]bool{}
+	unmatched := make([]DriftStateInstance, 0)
+
+	for _, resource := range stateFile.Resources {
+		if resource.Mode != "managed" {
+			continue
+		}
+		for _, instance := range resource.Instances {
+			if instance.Deposed != "" {
+				continue
+			}
+			attrs, err := instance.Attributes()
+			if err != nil {
+				return report, fmt.Errorf("error reading attributes for %s: %w", resource.InstanceAddress(instance), err)
+			}
+
+			stateInstance := DriftStateInstance{
+				Address:  resource.InstanceAddress(instance),
+				Type:     resource.Type,
+				Provider: resource.Provider,
+			}
+			idx, found := -1, false
+			for _, attrName := range driftIdentifierAttributes {
+				id, _ := attrs[attrName].(string)
+				if id == "" {
+					continue
+				}
+				if stateInstance.UniqueId == "" {
+					stateInstance.UniqueId = id
+				}
+				if idx, found = scanned[normalizeUniqueId(id)]; found {
+					stateInstance.UniqueId = id
+					break
+				}
+			}
+
+			if !found {
+				if stateInstance.UniqueId != "" {
+					unmatched = append(unmatched, stateInstance)
+				}
+				continue
+			}
+
+			matched[idx] = true
+			scannedTypes[resource.Type] = true
+			drifted := DriftedResource{
+				State:      stateInstance,
+				Resource:   resources[idx],
+				Attributes: d.compareAttributes(attrs, resources[idx].Attributes),
+			}
+			if tagDrift, ok := d.compareTags(attrs, resources[idx].Tags); ok {
+				drifted.Attributes = append(drifted.Attributes, tagDrift)
+			}
+			if len(drifted.Attributes) > 0 {
+				report.Drifted = append(report.Drifted, drifted)
+			} else {
+				report.InSync = append(report.InSync, drifted)
+			}
+		}
+	}
+
+	for i, resource := range resources {
+		if !matched[i] {
+			report.Unmanaged = append(report.Unmanaged, resource)
+		}
+		if d.ResourceType != nil {
+			if resourceType := d.ResourceType(resource); resourceType != "" {
+				scannedTypes[resourceType] = true
+			}
+		}
+	}
+
+	for _, stateInstance := range unmatched {
+		if d.isTracked(stateInstance.Type, scannedTypes) {
+			report.Missing = append(report.Missing, stateInstance)
+		}
+	}
+
+	return report, nil
+}
+
+func (d DriftDetector) isTracked(resourceType string, scannedTypes map[string]bool) bool {
+	if len(d.ResourceTypes) > 0 {
+		return slices.Contains(d.ResourceTypes, resourceType)
+	}
+	return scannedTypes[resourceType]
+}
+
+func (d DriftDetector) compareAttributes(stateAttrs, liveAttrs map[string]any) []DriftAttribute {
+	result := make([]DriftAttribute, 0)
+	for name, liveValue := range liveAttrs {
+		if slices.Contains(d.IgnoreAttributes, name) {
+			continue
+		}
+		stateValue, ok := stateAttrs[name]
+		if !ok || stateValue == nil {
+			continue
+		}
+		// Normalize the live value through json so that it has the same representation as the state value
+		normalized, err := normalizeDriftValue(liveValue)
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(stateValue, normalized) {
+			result = append(result, DriftAttribute{Name: name, StateValue: stateValue, LiveValue: normalized})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// compareTags compares ScanResource.Tags against the first tag attribute in the state (see ownershipTagAttributes)
+// tags_all and effective_labels include provider-level default tags, which matches what the cloud reports
+// Scanned tags use universal keys, so state tag keys are converted the same way before comparing
+func (d DriftDetector) compareTags(stateAttrs map[string]any, liveTags map[string]string) (DriftAttribute, bool) {
+	if liveTags == nil {
+		return DriftAttribute{}, false
+	}
+	for _, name := range ownershipTagAttributes {
+		stateTags, ok := stateAttrs[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		if slices.Contains(d.IgnoreAttributes, name) {
+			return DriftAttribute{}, false
+		}
+		stateValue := map[string]any{}
+		for key, value := range stateTags {
+			stateValue[universalOwnershipTag(key)] = value
+		}
+		liveValue := map[string]any{}
+		for key, value := range liveTags {
+			liveValue[universalOwnershipTag(key)] = value
+		}
+		if reflect.DeepEqual(stateValue, liveValue) {
+			return DriftAttribute{}, false
+		}
+		return DriftAttribute{Name: name, StateValue: stateValue, LiveValue: liveValue}, true
+	}
+	return DriftAttribute{}, false
+}
+
+func normalizeDriftValue(value any) (any, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var result any
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// normalizeUniqueId converts a cloud identifier into the form used to match ScanResource.UniqueId against state attributes
+// This is shared by drift detection, ownership, and cost allocation so that a resource matches the same way everywhere
+// - Azure resource ids are case-insensitive and are often returned with different casing
+// - Provider-specific prefixes that the cloud api includes, but the terraform provider omits, are removed (see uniqueIdPrefixes)
+func normalizeUniqueId(id string) string {
+	for _, prefix := range uniqueIdPrefixes {
+		if trimmed, ok := strings.CutPrefix(id, prefix); ok {
+			id = trimmed
+			break
+		}
+	}
+	return strings.ToLower(id)
+}
@@ -0,0 +1,170 @@
+package infra_sdk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftDetector_Detect(t *testing.T) {
+	stateFile := StateFile{
+		Version: 4,
+		Resources: []StateFileResource{
+			{
+				Mode:     "managed",
+				Type:     "aws_s3_bucket",
+				Name:     "logs",
+				Provider: `provider["registry.terraform.io/hashicorp/aws"]`,
+				Instances: []StateFileInstance{
+					{AttributesRaw: json.RawMessage(`{"arn":"arn:aws:s3:::logs","id":"logs","tags":{"Env":"dev"},"tags_all":{"Env":"dev","Team":"core"}}`)},
+				},
+			},
+			{
+				Mode:     "managed",
+				Type:     "aws_sqs_queue",
+				Name:     "jobs",
+				Provider: `provider["registry.terraform.io/hashicorp/aws"]`,
+				Instances: []StateFileInstance{
+					{IndexKey: "a", AttributesRaw: json.RawMessage(`{"arn":"arn:aws:sqs:us-east-1:123:jobs-a","visibility_timeout_seconds":30}`)},
+					{IndexKey: "b", AttributesRaw: json.RawMessage(`{"arn":"arn:aws:sqs:us-east-1:123:jobs-b"}`)},
+				},
+			},
+			{
+				Mode: "managed",
+				Type: "aws_iam_role",
+				Name: "task",
+				Instances: []StateFileInstance{
+					{AttributesRaw: json.RawMessage(`{"arn":"arn:aws:iam::123:role/task"}`)},
+				},
+			},
+			{
+				Mode: "data",
+				Type: "aws_caller_identity",
+				Name: "current",
+				Instances: []StateFileInstance{
+					{AttributesRaw: json.RawMessage(`{"id":"123"}`)},
+				},
+			},
+		},
+	}
+	resources := []ScanResource{
+		{UniqueId: "arn:aws:s3:::logs", Name: "logs", Tags: map[string]string{UniversalTagEnv: "prod", "Team": "core"}},
+		{UniqueId: "arn:aws:sqs:us-east-1:123:jobs-a", Name: "jobs-a", Attributes: map[string]any{"visibility_timeout_seconds": int32(30)}, Tags: map[string]string{}},
+		{UniqueId: "arn:aws:sqs:us-east-1:123:other", Name: "other"},
+	}
+
+	detector := DriftDetector{ResourceTypes: []string{"aws_s3_bucket", "aws_sqs_queue"}}
+	report, err := detector.Detect(stateFile, resources)
+	require.NoError(t, err)
+
+	assert.True(t, report.HasDrift())
+	require.Len(t, report.Unmanaged, 1)
+	assert.Equal(t, "arn:aws:sqs:us-east-1:123:other", report.Unmanaged[0].UniqueId)
+
+	assert.Equal(t, []DriftStateInstance{
+		{
+			Address:  `aws_sqs_queue.jobs["b"]`,
+			Type:     "aws_sqs_queue",
+			Provider: `provider["registry.terraform.io/hashicorp/aws"]`,
+			UniqueId: "arn:aws:sqs:us-east-1:123:jobs-b",
+		},
+	}, report.Missing)
+
+	require.Len(t, report.Drifted, 1)
+	assert.Equal(t, "aws_s3_bucket.logs", report.Drifted[0].State.Address)
+	assert.Equal(t, []DriftAttribute{
+		{
+			Name:       "tags_all",
+			StateValue: map[string]any{UniversalTagEnv: "dev", "Team": "core"},
+			LiveValue:  map[string]any{UniversalTagEnv: "prod", "Team": "core"},
+		},
+	}, report.Drifted[0].Attributes)
+
+	require.Len(t, report.InSync, 1)
+	assert.Equal(t, `aws_sqs_queue.jobs["a"]`, report.InSync[0].State.Address)
+}
+
+func TestDriftDetector_Detect_DefaultResourceTypes(t *testing.T) {
+	stateFile := StateFile{
+		Version: 4,
+		Resources: []StateFileResource{
+			{
+				Mode: "managed", Type: "aws_sqs_queue", Name: "jobs",
+				Instances: []StateFileInstance{
+					{IndexKey: "a", AttributesRaw: json.RawMessage(`{"arn":"arn:aws:sqs:us-east-1:123:jobs-a"}`)},
+					{IndexKey: "b", AttributesRaw: json.RawMessage(`{"arn":"arn:aws:sqs:us-east-1:123:jobs-b"}`)},
+				},
+			},
+			{
+				Mode: "managed", Type: "aws_sns_topic", Name: "events",
+				Instances: []StateFileInstance{{AttributesRaw: json.RawMessage(`{"arn":"arn:aws:sns:us-east-1:123:events"}`)}},
+			},
+			{
+				Mode: "managed", Type: "aws_iam_role", Name: "task",
+				Instances: []StateFileInstance{{AttributesRaw: json.RawMessage(`{"arn":"arn:aws:iam::123:role/task"}`)}},
+			},
+			{
+				Mode: "managed", Type: "aws_security_group", Name: "app",
+				Instances: []StateFileInstance{{AttributesRaw: json.RawMessage(`{"id":"sg-123"}`)}},
+			},
+		},
+	}
+	resources := []ScanResource{
+		{UniqueId: "arn:aws:sqs:us-east-1:123:jobs-a", Name: "jobs-a", ServiceName: "SQS", ServiceResourceName: "Queue"},
+		{UniqueId: "arn:aws:sns:us-east-1:123:other", Name: "other", ServiceName: "SNS", ServiceResourceName: "Topic"},
+	}
+	missingAddresses := func(report DriftReport) []string {
+		result := make([]string, 0)
+		for _, missing := range report.Missing {
+			result = append(result, missing.Address)
+		}
+		return result
+	}
+
+	t.Run("types of matched instances", func(t *testing.T) {
+		report, err := DetectDrift(stateFile, resources)
+		require.NoError(t, err)
+		assert.Equal(t, []string{`aws_sqs_queue.jobs["b"]`}, missingAddresses(report))
+	})
+
+	t.Run("types of scanned resources", func(t *testing.T) {
+		detector := DriftDetector{
+			ResourceType: func(resource ScanResource) string {
+				if resource.ServiceName == "SNS" {
+					return "aws_sns_topic"
+				}
+				return ""
+			},
+		}
+		report, err := detector.Detect(stateFile, resources)
+		require.NoError(t, err)
+		assert.Equal(t, []string{`aws_sqs_queue.jobs["b"]`, "aws_sns_topic.events"}, missingAddresses(report))
+	})
+}
+
+func TestDriftDetector_Detect_Route53(t *testing.T) {
+	// Route53 reports hosted zone ids with a "/hostedzone/" prefix that the terraform provider omits
+	stateFile := StateFile{
+		Version: 4,
+		Resources: []StateFileResource{
+			{
+				Mode: "managed", Type: "aws_route53_zone", Name: "primary",
+				Instances: []StateFileInstance{{AttributesRaw: json.RawMessage(`{"arn":"arn:aws:route53:::hostedzone/Z123","id":"Z123"}`)}},
+			},
+		},
+	}
+	resources := []ScanResource{
+		{UniqueId: "/hostedzone/Z123", Name: "example.com.", ServiceName: "Route53", ServiceResourceName: "Hosted Zone"},
+	}
+
+	detector := DriftDetector{ResourceType: func(resource ScanResource) string { return "aws_route53_zone" }}
+	report, err := detector.Detect(stateFile, resources)
+	require.NoError(t, err)
+	assert.Empty(t, report.Unmanaged)
+	assert.Empty(t, report.Missing)
+	require.Len(t, report.InSync, 1)
+	assert.Equal(t, "aws_route53_zone.primary", report.InSync[0].State.Address)
+	assert.Equal(t, "Z123", report.InSync[0].State.UniqueId)
+}
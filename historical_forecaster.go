@@ -0,0 +1,276 @@
+package infra_sdk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type ForecastMethod string
+
+const (
+	// ForecastMethodLinear projects the linear trend of the history
+	ForecastMethodLinear ForecastMethod = "linear"
+	// ForecastMethodSeasonal projects the linear trend with a repeating seasonal pattern
+	// The season is 24 hours, 7 days, or 12 months depending on granularity
+	// If the history covers less than 2 seasons, ForecastMethodLinear is used
+	ForecastMethodSeasonal ForecastMethod = "seasonal"
+)
+
+var (
+	_ CostForecaster = HistoricalForecaster{}
+)
+
+// HistoricalForecaster forecasts costs by projecting historical costs
+// This is used for clouds that do not have a native forecast API
+type HistoricalForecaster struct {
+	// Coster retrieves historical costs
+	Coster Coster
+	// HistoryPeriods is the number of periods before the forecast window that are used for the projection
+	// If 0, the last 7 days (hourly), 90 days (daily), or 12 months (monthly) are used
+	HistoryPeriods int
+	// Method is the projection method; defaults to ForecastMethodSeasonal
+	Method ForecastMethod
+}
+
+func (f HistoricalForecaster) GetForecast(ctx context.Context, query CostForecastQuery) (*CostForecast, error) {
+	if f.Coster == nil {
+		return nil, fmt.Errorf("historical forecaster requires a Coster")
+	}
+	granularity := query.Granularity
+	if granularity == "" {
+		granularity = CostGranularityDaily
+	}
+
+	historyStart := addCostPeriods(query.Start, granularity, -f.historyPeriods(granularity))
+	historyQuery := query.CostQuery
+	historyQuery.Start = historyStart
+	historyQuery.End = query.Start
+	historyQuery.Granularity = granularity
+	history, err := f.Coster.GetCosts(ctx, historyQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving cost history: %w", err)
+	}
+	if history == nil {
+		return nil, nil
+	}
+	return f.Project(*history, historyStart, query)
+}
+
+// Project forecasts each series in history over the forecast window in query
+// history must contain datapoints with the same granularity as query between historyStart and query.Start
+// Periods without a datapoint are treated as zero cost
+func (f HistoricalForecaster) Project(history CostResult, historyStart time.Time, query CostForecastQuery) (*CostForecast, error) {
+	granularity := query.Granularity
+	if granularity == "" {
+		granularity = CostGranularityDaily
+	}
+	level := query.IntervalLevel()
+	if level <= 0 || level >= 100 {
+		return nil, fmt.Errorf("prediction interval level must be between 0 and 100, got %d", level)
+	}
+	if !query.End.After(query.Start) {
+		return nil, fmt.Errorf("forecast end must be after start")
+	}
+
+	historyStarts := costPeriodStarts(historyStart, query.Start, granularity)
+	if len(historyStarts) == 0 {
+		return nil, fmt.Errorf("forecast requires history before %s", query.Start.Format(time.RFC3339))
+	}
+	forecastStarts := costPeriodStarts(query.Start, query.End, granularity)
+	// z is the number of standard deviations from the mean that contains the prediction interval
+	z := math.Sqrt2 * math.Erfinv(float64(level)/100)
+
+	seriesKeys := make([]string, 0, len(history.Series))
+	for key := range history.Series {
+		seriesKeys = append(seriesKeys, key)
+	}
+	sort.Strings(seriesKeys)
+
+	result := NewCostForecast()
+	for _, key := range seriesKeys {
+		series := history.Series[key]
+		values := make([]float64, len(historyStarts))
+		unit := ""
+		for _, point := range series.Points {
+			idx := sort.Search(len(historyStarts), func(i int) bool { return historyStarts[i].After(point.Start) }) - 1
+			if idx < 0 || !point.Start.Before(query.Start) {
+				continue
+			}
+			value, err := strconv.ParseFloat(point.Value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q in series %q: %w", point.Value, key, err)
+			}
+			values[idx] += value
+			unit = point.Unit
+		}
+
+		projection := fitCostProjection(values, f.method(), costSeasonLength(granularity))
+		// Costs cannot be negative, so the lower bound of the prediction interval is clamped at 0
+		margin := z * projection.stddev
+		for i, start := range forecastStarts {
+			mean := projection.predict(len(values) + i)
+			result.AddDatapoint(series.MetricName, series.GroupKeys, CostForecastDatapoint{
+				Start:      start,
+				End:        addCostPeriods(start, granularity, 1),
+				Unit:       unit,
+				Value:      formatForecastValue(mean),
+				LowerBound: formatForecastValue(math.Max(mean-margin, 0)),
+				UpperBound: formatForecastValue(mean + margin),
+			})
+		}
+	}
+	return result, nil
+}
+
+func (f HistoricalForecaster) historyPeriods(granularity CostGranularity) int {
+	if f.HistoryPeriods > 0 {
+		return f.HistoryPeriods
+	}
+	switch granularity {
+	case CostGranularityHourly:
+		return 7 * 24
+	case CostGranularityMonthly:
+		return 12
+	default:
+		return 90
+	}
+}
+
+func (f HistoricalForecaster) method() ForecastMethod {
+	if f.Method == "" {
+		return ForecastMethodSeasonal
+	}
+	return f.Method
+}
+
+// costProjection is a linear trend with an optional intercept for each phase of the season
+// stddev is the standard deviation of the history from the projection
+type costProjection struct {
+	intercept float64
+	slope     float64
+	seasonal  []float64
+	stddev    float64
+}
+
+func (p costProjection) predict(period int) float64 {
+	value := p.intercept + p.slope*float64(period)
+	if len(p.seasonal) > 0 {
+		value += p.seasonal[period%len(p.seasonal)]
+	}
+	return value
+}
+
+func fitCostProjection(values []float64, method ForecastMethod, seasonLength int) costProjection {
+	p := costProjection{}
+	params := 2
+	if method == ForecastMethodSeasonal && seasonLength > 1 && len(values) >= 2*seasonLength {
+		p.slope, p.seasonal = seasonalRegression(values, seasonLength)
+		params = seasonLength + 1
+	} else {
+		p.intercept, p.slope = linearRegression(values)
+	}
+
+	var sumSquares float64
+	for i, value := range values {
+		residual := value - p.predict(i)
+		sumSquares += residual * residual
+	}
+	p.stddev = math.Sqrt(sumSquares / float64(max(len(values)-params, 1)))
+	return p
+}
+
+// seasonalRegression fits a least squares line with a separate intercept for each phase of the season
+// The shared slope is fit on values relative to the mean of their phase, so the seasonal pattern does not skew the trend
+func seasonalRegression(values []float64, seasonLength int) (slope float64, intercepts []float64) {
+	sumX, sumY, counts := make([]float64, seasonLength), make([]float64, seasonLength), make([]float64, seasonLength)
+	for i, value := range values {
+		sumX[i%seasonLength] += float64(i)
+		sumY[i%seasonLength] += value
+		counts[i%seasonLength]++
+	}
+
+	var num, den float64
+	for i, value := range values {
+		phase := i % seasonLength
+		dx := float64(i) - sumX[phase]/counts[phase]
+		num += dx * (value - sumY[phase]/counts[phase])
+		den += dx * dx
+	}
+	if den != 0 {
+		slope = num / den
+	}
+
+	intercepts = make([]float64, seasonLength)
+	for phase := range intercepts {
+		intercepts[phase] = (sumY[phase] - slope*sumX[phase]) / counts[phase]
+	}
+	return slope, intercepts
+}
+
+// linearRegression fits a least squares line through values indexed by period
+func linearRegression(values []float64) (intercept float64, slope float64) {
+	n := float64(len(values))
+	if len(values) < 2 {
+		if len(values) == 1 {
+			return values[0], 0
+		}
+		return 0, 0
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i, value := range values {
+		x := float64(i)
+		sumX += x
+		sumY += value
+		sumXY += x * value
+		sumXX += x * x
+	}
+	slope = (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	intercept = (sumY - slope*sumX) / n
+	return intercept, slope
+}
+
+func costSeasonLength(granularity CostGranularity) int {
+	switch granularity {
+	case CostGranularityHourly:
+		return 24
+	case CostGranularityMonthly:
+		return 12
+	default:
+		return 7
+	}
+}
+
+// costPeriodStarts returns the start of each period in [start, end)
+func costPeriodStarts(start, end time.Time, granularity CostGranularity) []time.Time {
+	var starts []time.Time
+	for cur := start; cur.Before(end); cur = addCostPeriods(cur, granularity, 1) {
+		starts = append(starts, cur)
+	}
+	return starts
+}
+
+func addCostPeriods(t time.Time, granularity CostGranularity, n int) time.Time {
+	switch granularity {
+	case CostGranularityHourly:
+		return t.Add(time.Duration(n) * time.Hour)
+	case CostGranularityMonthly:
+		return t.AddDate(0, n, 0)
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
+
+// formatForecastValue rounds to 6 decimal places to avoid reporting floating point noise
+func formatForecastValue(value float64) string {
+	rounded := math.Round(value*1e6) / 1e6
+	if rounded == 0 {
+		// normalize negative zero
+		rounded = 0
+	}
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
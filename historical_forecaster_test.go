@@ -0,0 +1,208 @@
+package infra_sdk
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingCoster struct {
+	result *CostResult
+	query  CostQuery
+}
+
+func (r *recordingCoster) GetCosts(ctx context.Context, query CostQuery) (*CostResult, error) {
+	r.query = query
+	return r.result, nil
+}
+
+func dailyHistory(start time.Time, groupKeys CostSeriesGroupKeys, values ...float64) *CostResult {
+	result := NewCostResult()
+	for i, value := range values {
+		day := start.AddDate(0, 0, i)
+		result.AddDatapoint(UniversalMetricUnblendedCost, groupKeys, CostSeriesDatapoint{
+			Start: day,
+			End:   day.AddDate(0, 0, 1),
+			Unit:  "USD",
+			Value: strconv.FormatFloat(value, 'f', -1, 64),
+		})
+	}
+	return result
+}
+
+func forecastValues(points []CostForecastDatapoint) []string {
+	values := make([]string, 0, len(points))
+	for _, point := range points {
+		values = append(values, point.Value)
+	}
+	return values
+}
+
+func TestHistoricalForecaster_Project(t *testing.T) {
+	historyStart := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("linear", func(t *testing.T) {
+		history := dailyHistory(historyStart, nil, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19)
+		forecastStart := historyStart.AddDate(0, 0, 10)
+		forecaster := HistoricalForecaster{Method: ForecastMethodLinear}
+
+		forecast, err := forecaster.Project(*history, historyStart, CostForecastQuery{
+			CostQuery: CostQuery{Start: forecastStart, End: forecastStart.AddDate(0, 0, 3), Granularity: CostGranularityDaily},
+		})
+		require.NoError(t, err)
+
+		series, ok := forecast.Series[":UnblendedCost"]
+		require.True(t, ok)
+		assert.Equal(t, []CostForecastDatapoint{
+			{Start: forecastStart, End: forecastStart.AddDate(0, 0, 1), Unit: "USD", Value: "20", LowerBound: "20", UpperBound: "20"},
+			{Start: forecastStart.AddDate(0, 0, 1), End: forecastStart.AddDate(0, 0, 2), Unit: "USD", Value: "21", LowerBound: "21", UpperBound: "21"},
+			{Start: forecastStart.AddDate(0, 0, 2), End: forecastStart.AddDate(0, 0, 3), Unit: "USD", Value: "22", LowerBound: "22", UpperBound: "22"},
+		}, series.Points)
+	})
+
+	t.Run("seasonal", func(t *testing.T) {
+		week := []float64{1, 1, 1, 1, 1, 5, 5}
+		history := dailyHistory(historyStart, nil, append(append([]float64{}, week...), week...)...)
+		forecastStart := historyStart.AddDate(0, 0, 14)
+
+		forecast, err := HistoricalForecaster{}.Project(*history, historyStart, CostForecastQuery{
+			CostQuery: CostQuery{Start: forecastStart, End: forecastStart.AddDate(0, 0, 7)},
+		})
+		require.NoError(t, err)
+
+		series := forecast.Series[":UnblendedCost"]
+		assert.Equal(t, []string{"1", "1", "1", "1", "1", "5", "5"}, forecastValues(series.Points))
+	})
+
+	t.Run("seasonal with trend", func(t *testing.T) {
+		week := []float64{1, 1, 1, 1, 1, 5, 5}
+		values := make([]float64, 14)
+		for i := range values {
+			values[i] = week[i%7] + float64(i)
+		}
+		history := dailyHistory(historyStart, nil, values...)
+		forecastStart := historyStart.AddDate(0, 0, 14)
+
+		forecast, err := HistoricalForecaster{}.Project(*history, historyStart, CostForecastQuery{
+			CostQuery: CostQuery{Start: forecastStart, End: forecastStart.AddDate(0, 0, 7)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"15", "16", "17", "18", "19", "24", "25"}, forecastValues(forecast.Series[":UnblendedCost"].Points))
+	})
+
+	t.Run("seasonal without enough history falls back to linear", func(t *testing.T) {
+		history := dailyHistory(historyStart, nil, 2, 4, 6)
+		forecastStart := historyStart.AddDate(0, 0, 3)
+
+		forecast, err := HistoricalForecaster{Method: ForecastMethodSeasonal}.Project(*history, historyStart, CostForecastQuery{
+			CostQuery: CostQuery{Start: forecastStart, End: forecastStart.AddDate(0, 0, 2)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"8", "10"}, forecastValues(forecast.Series[":UnblendedCost"].Points))
+	})
+
+	t.Run("prediction interval widens with level", func(t *testing.T) {
+		history := dailyHistory(historyStart, nil, 10, 14, 9, 15, 11, 13, 10, 14)
+		forecastStart := historyStart.AddDate(0, 0, 8)
+		query := CostForecastQuery{CostQuery: CostQuery{Start: forecastStart, End: forecastStart.AddDate(0, 0, 1)}}
+
+		forecast80, err := HistoricalForecaster{Method: ForecastMethodLinear}.Project(*history, historyStart, query)
+		require.NoError(t, err)
+		query.PredictionIntervalLevel = 95
+		forecast95, err := HistoricalForecaster{Method: ForecastMethodLinear}.Project(*history, historyStart, query)
+		require.NoError(t, err)
+
+		point80, point95 := forecast80.Series[":UnblendedCost"].Points[0], forecast95.Series[":UnblendedCost"].Points[0]
+		assert.Equal(t, point80.Value, point95.Value)
+		parse := func(s string) float64 {
+			v, err := strconv.ParseFloat(s, 64)
+			require.NoError(t, err)
+			return v
+		}
+		assert.Less(t, parse(point80.LowerBound), parse(point80.Value))
+		assert.Greater(t, parse(point80.UpperBound), parse(point80.Value))
+		assert.Less(t, parse(point95.LowerBound), parse(point80.LowerBound))
+		assert.Greater(t, parse(point95.UpperBound), parse(point80.UpperBound))
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		history := dailyHistory(historyStart, nil, 1)
+		_, err := HistoricalForecaster{}.Project(*history, historyStart, CostForecastQuery{
+			CostQuery: CostQuery{Start: historyStart.AddDate(0, 0, 1), End: historyStart.AddDate(0, 0, 2)}, PredictionIntervalLevel: 100,
+		})
+		assert.ErrorContains(t, err, "prediction interval level")
+
+		_, err = HistoricalForecaster{}.Project(*history, historyStart, CostForecastQuery{
+			CostQuery: CostQuery{Start: historyStart, End: historyStart.AddDate(0, 0, 1)},
+		})
+		assert.ErrorContains(t, err, "requires history")
+	})
+}
+
+func TestHistoricalForecaster_GetForecast(t *testing.T) {
+	forecastStart := time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)
+	historyStart := forecastStart.AddDate(0, 0, -4)
+	groupKeys := CostSeriesGroupKeys{{TagKey: UniversalTagEnv, Value: "dev"}}
+
+	// the second day has no datapoint, so it is treated as zero cost
+	history := NewCostResult()
+	for i, value := range map[int]string{0: "3", 2: "3", 3: "3"} {
+		day := historyStart.AddDate(0, 0, i)
+		history.AddDatapoint(UniversalMetricUnblendedCost, groupKeys, CostSeriesDatapoint{Start: day, End: day.AddDate(0, 0, 1), Unit: "USD", Value: value})
+	}
+	coster := &recordingCoster{result: history}
+	forecaster := HistoricalForecaster{Coster: coster, HistoryPeriods: 4, Method: ForecastMethodLinear}
+
+	filter := &CostFilter{Tag: &CostFilterCondition{Key: UniversalTagEnv, Values: []string{"dev"}}}
+	forecast, err := forecaster.GetForecast(context.Background(), CostForecastQuery{
+		CostQuery: CostQuery{
+			Start:   forecastStart,
+			End:     forecastStart.AddDate(0, 0, 1),
+			Filter:  filter,
+			GroupBy: CostGroupIdentifiers{{TagKey: UniversalTagEnv}},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, historyStart, coster.query.Start)
+	assert.Equal(t, forecastStart, coster.query.End)
+	assert.Equal(t, CostGranularityDaily, coster.query.Granularity)
+	assert.Equal(t, filter, coster.query.Filter)
+	assert.Equal(t, CostGroupIdentifiers{{TagKey: UniversalTagEnv}}, coster.query.GroupBy)
+
+	series, ok := forecast.Series["nullstone.io/env$dev:UnblendedCost"]
+	require.True(t, ok)
+	require.Len(t, series.Points, 1)
+	// least squares through 3, 0, 3, 3 projects 3 for the fifth day
+	assert.Equal(t, "3", series.Points[0].Value)
+	assert.Equal(t, "USD", series.Points[0].Unit)
+}
+
+func TestHistoricalForecaster_GetForecast_RequiresCoster(t *testing.T) {
+	_, err := HistoricalForecaster{}.GetForecast(context.Background(), CostForecastQuery{})
+	assert.ErrorContains(t, err, "requires a Coster")
+}
+
+func TestHistoricalForecaster_Project_LowerBound(t *testing.T) {
+	forecastStart := time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)
+	historyStart := forecastStart.AddDate(0, 0, -4)
+
+	// a volatile, low cost history has a prediction interval that extends below 0
+	history := dailyHistory(historyStart, CostSeriesGroupKeys{}, 0, 4, 0, 4)
+	forecast, err := HistoricalForecaster{Method: ForecastMethodLinear}.Project(*history, historyStart, CostForecastQuery{
+		CostQuery:               CostQuery{Start: forecastStart, End: forecastStart.AddDate(0, 0, 1)},
+		PredictionIntervalLevel: 95,
+	})
+	require.NoError(t, err)
+
+	series, ok := forecast.Series[":UnblendedCost"]
+	require.True(t, ok)
+	require.Len(t, series.Points, 1)
+	assert.Equal(t, "0", series.Points[0].LowerBound)
+	value, _ := strconv.ParseFloat(series.Points[0].Value, 64)
+	assert.Greater(t, value, 0.0)
+}
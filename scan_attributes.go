@@ -0,0 +1,157 @@
+package infra_sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+)
+
+// ScanAttributes is the typed form of ScanResource.Attributes for a kind of resource
+// Implementations are structs whose json tags are the attribute names
+type ScanAttributes interface {
+	// ScanAttributesKind uniquely identifies the attribute schema (e.g. "aws/s3-bucket")
+	ScanAttributesKind() string
+}
+
+// WithAttributes sets Attributes and AttributesKind from typed attributes
+// Attributes are converted through json so that they have the same shape as the published schema
+func (r ScanResource) WithAttributes(attrs ScanAttributes) (ScanResource, error) {
+	r.AttributesKind = attrs.ScanAttributesKind()
+	r.Attributes = map[string]any{}
+	raw, err := json.Marshal(attrs)
+	if err != nil {
+		return r, fmt.Errorf("error encoding %q attributes: %w", r.AttributesKind, err)
+	}
+	if err := json.Unmarshal(raw, &r.Attributes); err != nil {
+		return r, fmt.Errorf("error encoding %q attributes: %w", r.AttributesKind, err)
+	}
+	return r, nil
+}
+
+// DecodeAttributes decodes Attributes into typed attributes
+// An error is returned if AttributesKind does not match the kind of attrs
+func (r ScanResource) DecodeAttributes(attrs ScanAttributes) error {
+	if kind := attrs.ScanAttributesKind(); r.AttributesKind != kind {
+		return fmt.Errorf("cannot decode %q attributes into %q", r.AttributesKind, kind)
+	}
+	raw, err := json.Marshal(r.Attributes)
+	if err != nil {
+		return fmt.Errorf("error encoding attributes: %w", err)
+	}
+	if err := json.Unmarshal(raw, attrs); err != nil {
+		return fmt.Errorf("error decoding attributes: %w", err)
+	}
+	return nil
+}
+
+// ScanAttributesSchema builds a JSON schema document that defines each kind of attributes under `$defs`
+func ScanAttributesSchema(id string, kinds ...ScanAttributes) map[string]any {
+	defs := map[string]any{}
+	refs := make([]any, 0, len(kinds))
+	for _, kind := range kinds {
+		name := kind.ScanAttributesKind()
+		// ScanAttributes are usually pointers, but the definition describes the struct
+		t := reflect.TypeOf(kind)
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		defs[name] = jsonSchemaOfType(t)
+		// Escape the kind as a JSON pointer token since kinds contain '/'
+		token := strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+		refs = append(refs, map[string]any{"$ref": "#/$defs/" + token})
+	}
+	return map[string]any{
+		"$schema": jsonSchemaDialect,
+		"$id":     id,
+		"$defs":   defs,
+		"anyOf":   refs,
+	}
+}
+
+// JSONSchemaOf generates a JSON schema that describes the json encoding of v
+// Fields without `omitempty` are required; pointers, slices, and maps are nullable
+func JSONSchemaOf(v any) map[string]any {
+	return jsonSchemaOfType(reflect.TypeOf(v))
+}
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	jsonRawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func jsonSchemaOfType(t reflect.Type) map[string]any {
+	if t == nil {
+		return map[string]any{}
+	}
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case jsonRawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullableSchema(jsonSchemaOfType(t.Elem()))
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return nullableSchema(map[string]any{"type": "array", "items": jsonSchemaOfType(t.Elem())})
+	case reflect.Map:
+		return nullableSchema(map[string]any{"type": "object", "additionalProperties": jsonSchemaOfType(t.Elem())})
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	// interfaces can hold any value
+	return map[string]any{}
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = jsonSchemaOfType(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func nullableSchema(schema map[string]any) map[string]any {
+	typ, ok := schema["type"].(string)
+	if !ok {
+		return schema
+	}
+	schema["type"] = []string{typ, "null"}
+	return schema
+}
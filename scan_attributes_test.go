@@ -0,0 +1,101 @@
+package infra_sdk
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEndpoint struct {
+	Address string `json:"address"`
+	Port    int32  `json:"port"`
+}
+
+type testAttributes struct {
+	Arn       string            `json:"arn"`
+	Enabled   bool              `json:"enabled"`
+	Size      float64           `json:"size"`
+	Created   *time.Time        `json:"created"`
+	Endpoints []testEndpoint    `json:"endpoints"`
+	Tags      map[string]string `json:"tags"`
+	Note      string            `json:"note,omitempty"`
+	internal  string
+}
+
+func (a *testAttributes) ScanAttributesKind() string { return "test/resource" }
+
+func TestJSONSchemaOf(t *testing.T) {
+	got := JSONSchemaOf(testAttributes{})
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"arn":     map[string]any{"type": "string"},
+			"enabled": map[string]any{"type": "boolean"},
+			"size":    map[string]any{"type": "number"},
+			"created": map[string]any{"type": []string{"string", "null"}, "format": "date-time"},
+			"endpoints": map[string]any{
+				"type": []string{"array", "null"},
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"address": map[string]any{"type": "string"},
+						"port":    map[string]any{"type": "integer"},
+					},
+					"required":             []string{"address", "port"},
+					"additionalProperties": false,
+				},
+			},
+			"tags": map[string]any{"type": []string{"object", "null"}, "additionalProperties": map[string]any{"type": "string"}},
+			"note": map[string]any{"type": "string"},
+		},
+		"required":             []string{"arn", "enabled", "size", "created", "endpoints", "tags"},
+		"additionalProperties": false,
+	}, got)
+}
+
+func TestScanResource_WithAttributes(t *testing.T) {
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	attrs := &testAttributes{
+		Arn:       "arn:aws:rds:us-east-1:123:db:main",
+		Enabled:   true,
+		Created:   &created,
+		Endpoints: []testEndpoint{{Address: "main.example.com", Port: 5432}},
+		Tags:      map[string]string{"Stack": "core"},
+	}
+
+	resource, err := ScanResource{UniqueId: attrs.Arn}.WithAttributes(attrs)
+	require.NoError(t, err)
+	assert.Equal(t, "test/resource", resource.AttributesKind)
+	assert.Equal(t, map[string]any{
+		"arn":       "arn:aws:rds:us-east-1:123:db:main",
+		"enabled":   true,
+		"size":      float64(0),
+		"created":   "2025-03-01T12:00:00Z",
+		"endpoints": []any{map[string]any{"address": "main.example.com", "port": float64(5432)}},
+		"tags":      map[string]any{"Stack": "core"},
+	}, resource.Attributes)
+
+	var decoded testAttributes
+	require.NoError(t, resource.DecodeAttributes(&decoded))
+	assert.Equal(t, *attrs, decoded)
+
+	resource.AttributesKind = "test/other"
+	assert.ErrorContains(t, resource.DecodeAttributes(&decoded), `cannot decode "test/other" attributes into "test/resource"`)
+
+	// NaN cannot be encoded as json
+	_, err = ScanResource{}.WithAttributes(&testAttributes{Size: math.NaN()})
+	assert.ErrorContains(t, err, `error encoding "test/resource" attributes`)
+}
+
+func TestScanAttributesSchema(t *testing.T) {
+	got := ScanAttributesSchema("https://example.com/schema.json", &testAttributes{})
+	assert.Equal(t, jsonSchemaDialect, got["$schema"])
+	assert.Equal(t, "https://example.com/schema.json", got["$id"])
+	defs := got["$defs"].(map[string]any)
+	require.Contains(t, defs, "test/resource")
+	assert.Equal(t, "object", defs["test/resource"].(map[string]any)["type"])
+	assert.Equal(t, []any{map[string]any{"$ref": "#/$defs/test~1resource"}}, got["anyOf"])
+}
@@ -11,7 +11,27 @@ type ScanResource struct {
 	Taxonomy            ResourceTaxonomy `json:"taxonomy"`
 	ServiceName         string           `json:"serviceName"`
 	ServiceResourceName string           `json:"serviceResourceName"`
+	Region              string           `json:"region"`
 	Attributes          map[string]any   `json:"attributes"`
+	// AttributesKind identifies the schema of Attributes (see ScanAttributes)
+	AttributesKind string `json:"attributesKind,omitempty"`
+	// Tags are the tags on the resource; nullstone tags are converted to universal tags
+	Tags map[string]string `json:"tags"`
+	// Ownership is the nullstone stack/env/block parsed from Tags
+	Ownership Ownership `json:"ownership"`
+}
+
+// WithTags sets Tags and Ownership from the provider's tags
+// toUniversal converts a provider tag key into a universal tag (e.g. aws_account.AwsTag.ToUniversal)
+func (r ScanResource) WithTags(tags map[string]string, toUniversal func(key string) string) ScanResource {
+	r.Tags = map[string]string{}
+	r.Ownership = Ownership{}
+	for key, value := range tags {
+		universal := toUniversal(key)
+		r.Tags[universal] = value
+		r.Ownership.set(universal, value)
+	}
+	return r
 }
 
 type ResourceTaxonomy struct {
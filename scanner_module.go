@@ -0,0 +1,227 @@
+package infra_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// terraformProviders maps a ResourceTaxonomy.Provider to its terraform provider source
+	terraformProviders = map[string]string{
+		"aws":   "registry.terraform.io/hashicorp/aws",
+		"gcp":   "registry.terraform.io/hashicorp/google",
+		"azure": "registry.terraform.io/hashicorp/azurerm",
+	}
+)
+
+var (
+	_ Module = ScannerModule{}
+)
+
+// ScannerModule is a Module that refreshes state from the live cloud resources discovered by a Scanner
+// This allows adopted infrastructure to be tracked without running terraform
+//
+// Scanned resources are merged into the existing state by unique id (see normalizeUniqueId)
+// - Instances that match a scanned resource are left untouched (address, attributes, dependencies, private data)
+// - Instances of covered resource types (see ResourceTypes) that were not scanned are removed
+// - Resources of other types, data resources, outputs, and check results are preserved
+// - Scanned resources that are not in state are added with only their identifying attributes (id and arn); a `terraform refresh` fills in the rest
+type ScannerModule struct {
+	Scanner Scanner
+	// ResourceIdentity resolves the terraform resource type and id for a scanned resource (e.g. aws_account.TerraformResourceIdentity)
+	// Scanned resources without a resource type are skipped
+	ResourceIdentity func(resource ScanResource) (resourceType string, id string)
+	// ResourceTypes are the resource types that the scanner covers completely
+	// Instances of these types that are not found by the scan are removed from state
+	// If empty, only the types produced by the scan are covered
+	ResourceTypes []string
+	// ProviderAlias resolves the provider alias for a scanned resource that is added to state (e.g. aws_account.TerraformProviderAlias)
+	// Resources without an alias are managed by the default provider configuration
+	ProviderAlias func(resource ScanResource) string
+}
+
+// Refresh requires a stateFileBroker that implements StateFileLoader so that existing state is preserved
+func (m ScannerModule) Refresh(ctx context.Context, stateFileBroker StateFileBroker) (result StateFile, err error) {
+	if m.ResourceIdentity == nil {
+		return result, fmt.Errorf("scanner module requires ResourceIdentity")
+	}
+	loader, ok := stateFileBroker.(StateFileLoader)
+	if !ok {
+		return result, fmt.Errorf("state file broker %T does not support loading state", stateFileBroker)
+	}
+	if err := stateFileBroker.Initialize(ctx); err != nil {
+		return result, fmt.Errorf("error initializing state: %w", err)
+	}
+	if err := stateFileBroker.Lock(ctx); err != nil {
+		return result, fmt.Errorf("error locking state: %w", err)
+	}
+	defer func() {
+		if unlockErr := stateFileBroker.Unlock(ctx); unlockErr != nil {
+			err = errors.Join(err, fmt.Errorf("error unlocking state: %w", unlockErr))
+		}
+	}()
+
+	current, err := loader.Load(ctx)
+	if err != nil {
+		return result, fmt.Errorf("error loading state: %w", err)
+	}
+	resources, err := m.Scanner.Scan(ctx)
+	if err != nil {
+		return result, fmt.Errorf("error scanning resources: %w", err)
+	}
+
+	next := NewStateFile()
+	if current != nil {
+		next = *current
+	}
+	if next.Resources, err = m.mergeResources(next.Resources, resources); err != nil {
+		return result, err
+	}
+
+	if err := stateFileBroker.Save(ctx, next); err != nil {
+		return result, fmt.Errorf("error saving state: %w", err)
+	}
+	// The broker assigns the final serial, so we return what was persisted
+	saved, err := loader.Load(ctx)
+	if err != nil {
+		return result, fmt.Errorf("error loading state: %w", err)
+	}
+	if saved == nil {
+		return next, nil
+	}
+	return *saved, nil
+}
+
+// scannedIdentity is a scanned resource along with its terraform identity
+type scannedIdentity struct {
+	resource     ScanResource
+	resourceType string
+	id           string
+	matched      bool
+}
+
+// mergeResources merges scanned resources into the existing state resources
+// existing is not modified
+func (m ScannerModule) mergeResources(existing []StateFileResource, resources []ScanResource) ([]StateFileResource, error) {
+	scanned := make([]*scannedIdentity, 0, len(resources))
+	// byId indexes scanned resources by their unique id and their terraform id, scoped to the resource type
+	// Some unique ids are only names (e.g. classic load balancers), so they are not unique across resource types
+	byId := map[string]*scannedIdentity{}
+	coveredTypes := map[string]bool{}
+	for _, resourceType := range m.ResourceTypes {
+		coveredTypes[resourceType] = true
+	}
+	for _, resource := range resources {
+		resourceType, id := m.ResourceIdentity(resource)
+		if resourceType == "" {
+			continue
+		}
+		cur := &scannedIdentity{resource: resource, resourceType: resourceType, id: id}
+		scanned = append(scanned, cur)
+		byId[resourceType+"/"+normalizeUniqueId(resource.UniqueId)] = cur
+		byId[resourceType+"/"+normalizeUniqueId(id)] = cur
+		if len(m.ResourceTypes) == 0 {
+			coveredTypes[resourceType] = true
+		}
+	}
+
+	result := make([]StateFileResource, 0, len(existing)+len(scanned))
+	usedAddresses := map[string]bool{}
+	for _, resource := range existing {
+		if resource.Mode != "managed" {
+			result = append(result, resource)
+			continue
+		}
+		instances := make([]StateFileInstance, 0, len(resource.Instances))
+		for _, instance := range resource.Instances {
+			attrs, err := instance.Attributes()
+			if err != nil {
+				return nil, fmt.Errorf("error reading attributes for %s: %w", resource.InstanceAddress(instance), err)
+			}
+			found, hasId := false, false
+			for _, attrName := range driftIdentifierAttributes {
+				id, _ := attrs[attrName].(string)
+				if id == "" {
+					continue
+				}
+				hasId = true
+				if match, ok := byId[resource.Type+"/"+normalizeUniqueId(id)]; ok {
+					match.matched, found = true, true
+					break
+				}
+			}
+			// Only drop instances that the scan confirms are gone
+			if found || !hasId || !coveredTypes[resource.Type] {
+				instances = append(instances, instance)
+			}
+		}
+		if len(instances) == 0 && len(resource.Instances) > 0 {
+			continue
+		}
+		resource.Instances = instances
+		result = append(result, resource)
+		if resource.Module == "" {
+			usedAddresses[resource.Type+"."+resource.Name] = true
+		}
+	}
+
+	added := make([]StateFileResource, 0)
+	for _, cur := range scanned {
+		if cur.matched {
+			continue
+		}
+		baseName := TerraformIdentifier(cur.resource.Name)
+		name := baseName
+		for i := 2; usedAddresses[cur.resourceType+"."+name]; i++ {
+			name = fmt.Sprintf("%s_%d", baseName, i)
+		}
+		usedAddresses[cur.resourceType+"."+name] = true
+
+		attrs := map[string]any{"id": cur.id}
+		if strings.HasPrefix(cur.resource.UniqueId, "arn:") {
+			attrs["arn"] = cur.resource.UniqueId
+		}
+		raw, err := json.Marshal(attrs)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding attributes for %s: %w", cur.resource.UniqueId, err)
+		}
+
+		var alias string
+		if m.ProviderAlias != nil {
+			alias = m.ProviderAlias(cur.resource)
+		}
+		added = append(added, StateFileResource{
+			Mode:     "managed",
+			Type:     cur.resourceType,
+			Name:     name,
+			Provider: terraformProviderAddress(cur.resource.Taxonomy.Provider, alias),
+			Instances: []StateFileInstance{
+				{AttributesRaw: raw},
+			},
+		})
+	}
+	sort.SliceStable(added, func(i, j int) bool {
+		if added[i].Type != added[j].Type {
+			return added[i].Type < added[j].Type
+		}
+		return added[i].Name < added[j].Name
+	})
+	return append(result, added...), nil
+}
+
+// terraformProviderAddress returns the provider address as it is recorded in a state file
+// e.g. provider["registry.terraform.io/hashicorp/aws"] or provider["registry.terraform.io/hashicorp/aws"].us-west-2
+func terraformProviderAddress(provider, alias string) string {
+	source, ok := terraformProviders[provider]
+	if !ok {
+		source = fmt.Sprintf("registry.terraform.io/hashicorp/%s", provider)
+	}
+	if alias != "" {
+		return fmt.Sprintf("provider[%q].%s", source, alias)
+	}
+	return fmt.Sprintf("provider[%q]", source)
+}
@@ -0,0 +1,178 @@
+package infra_sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	infra_sdk "github.com/nullstone-io/infra-sdk"
+	"github.com/nullstone-io/infra-sdk/builtin/local"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubScanner struct {
+	resources []infra_sdk.ScanResource
+	err       error
+}
+
+func (s stubScanner) Scan(ctx context.Context) ([]infra_sdk.ScanResource, error) {
+	return s.resources, s.err
+}
+
+func stubResourceIdentity(resource infra_sdk.ScanResource) (string, string) {
+	switch resource.ServiceName {
+	case "S3":
+		return "aws_s3_bucket", resource.Name
+	case "RDS":
+		return "aws_db_instance", resource.Name
+	}
+	return "", ""
+}
+
+func TestScannerModule_Refresh(t *testing.T) {
+	logs := infra_sdk.StateFileResource{
+		Mode: "managed", Type: "aws_s3_bucket", Name: "app_logs", Provider: `provider["registry.terraform.io/hashicorp/aws"].us-west-2`,
+		Instances: []infra_sdk.StateFileInstance{{
+			SchemaVersion: 1,
+			AttributesRaw: json.RawMessage(`{"id":"logs","arn":"arn:aws:s3:::logs","force_destroy":true}`),
+			PrivateRaw:    []byte("private"),
+			Dependencies:  []string{"aws_kms_key.logs"},
+		}},
+	}
+	role := infra_sdk.StateFileResource{
+		Mode: "managed", Type: "aws_iam_role", Name: "task",
+		Instances: []infra_sdk.StateFileInstance{{AttributesRaw: json.RawMessage(`{"id":"task","arn":"arn:aws:iam::123:role/task"}`)}},
+	}
+	broker := &local.MemoryStateFileBroker{
+		StateFile: &infra_sdk.StateFile{
+			Version: 4,
+			Serial:  3,
+			Lineage: "abc",
+			Outputs: map[string]infra_sdk.StateFileOutput{"x": {Value: json.RawMessage(`"y"`)}},
+			Resources: []infra_sdk.StateFileResource{
+				logs,
+				role,
+				{
+					Mode: "managed", Type: "aws_s3_bucket", Name: "assets",
+					Instances: []infra_sdk.StateFileInstance{
+						{IndexKey: "a", AttributesRaw: json.RawMessage(`{"id":"assets-a","arn":"arn:aws:s3:::assets-a"}`)},
+						{IndexKey: "b", AttributesRaw: json.RawMessage(`{"id":"assets-b","arn":"arn:aws:s3:::assets-b"}`)},
+					},
+				},
+				{
+					Mode: "managed", Type: "aws_s3_bucket", Name: "gone",
+					Instances: []infra_sdk.StateFileInstance{{AttributesRaw: json.RawMessage(`{"id":"gone","arn":"arn:aws:s3:::gone"}`)}},
+				},
+				{Mode: "data", Type: "aws_caller_identity", Name: "current", Instances: []infra_sdk.StateFileInstance{{AttributesRaw: json.RawMessage(`{"id":"123"}`)}}},
+			},
+			CheckResults: []infra_sdk.StateFileCheckResult{{ObjectKind: "resource", ConfigAddr: "aws_s3_bucket.logs", Status: "pass"}},
+		},
+	}
+	module := infra_sdk.ScannerModule{
+		Scanner: stubScanner{resources: []infra_sdk.ScanResource{
+			{UniqueId: "arn:aws:s3:::logs", Name: "logs", ServiceName: "S3", ServiceResourceName: "Bucket", Region: "us-west-2", Taxonomy: infra_sdk.ResourceTaxonomy{Provider: "aws"}},
+			{UniqueId: "arn:aws:s3:::assets-a", Name: "assets-a", ServiceName: "S3", ServiceResourceName: "Bucket", Region: "us-east-1", Taxonomy: infra_sdk.ResourceTaxonomy{Provider: "aws"}},
+			{UniqueId: "arn:aws:s3:::app_logs", Name: "app_logs", ServiceName: "S3", ServiceResourceName: "Bucket", Region: "us-east-1", Taxonomy: infra_sdk.ResourceTaxonomy{Provider: "aws"}},
+			{UniqueId: "arn:aws:rds:us-east-2:123:db:main", Name: "main", ServiceName: "RDS", ServiceResourceName: "Instance", Region: "us-east-2", Taxonomy: infra_sdk.ResourceTaxonomy{Provider: "aws"}},
+			{UniqueId: "arn:aws:example:us-east-1:123:widget/w", Name: "w", ServiceName: "Example", ServiceResourceName: "Widget", Taxonomy: infra_sdk.ResourceTaxonomy{Provider: "aws"}},
+		}},
+		ResourceIdentity: stubResourceIdentity,
+		ProviderAlias: func(resource infra_sdk.ScanResource) string {
+			if resource.Region == "us-east-1" {
+				return ""
+			}
+			return resource.Region
+		},
+	}
+
+	result, err := module.Refresh(context.Background(), broker)
+	require.NoError(t, err)
+
+	methods := make([]string, 0, len(broker.Calls))
+	for _, call := range broker.Calls {
+		methods = append(methods, call.Method)
+	}
+	assert.Equal(t, []string{local.MemoryCallInitialize, local.MemoryCallLock, local.MemoryCallLoad, local.MemoryCallSave, local.MemoryCallLoad, local.MemoryCallUnlock}, methods)
+	assert.False(t, broker.IsLocked())
+	assert.Equal(t, uint64(4), result.Serial)
+	assert.Equal(t, "abc", result.Lineage)
+	assert.Contains(t, result.Outputs, "x")
+	assert.Equal(t, broker.StateFile.CheckResults, result.CheckResults)
+
+	addresses := make([]string, 0)
+	for _, resource := range result.Resources {
+		for _, instance := range resource.Instances {
+			addresses = append(addresses, resource.InstanceAddress(instance))
+		}
+	}
+	// Matched and unscanned resources keep their address, unscanned instances of scanned types are removed,
+	// data resources are preserved, unknown kinds are skipped, and new resources get an unused address
+	assert.Equal(t, []string{
+		"aws_s3_bucket.app_logs",
+		"aws_iam_role.task",
+		`aws_s3_bucket.assets["a"]`,
+		"data.aws_caller_identity.current",
+		"aws_db_instance.main",
+		"aws_s3_bucket.app_logs_2",
+	}, addresses)
+
+	// Matched resources are not modified
+	assert.Equal(t, logs, result.Resources[0])
+	assert.Equal(t, role, result.Resources[1])
+
+	added := result.Resources[len(result.Resources)-2:]
+	assert.Equal(t, `provider["registry.terraform.io/hashicorp/aws"].us-east-2`, added[0].Provider)
+	assert.JSONEq(t, `{"id":"main","arn":"arn:aws:rds:us-east-2:123:db:main"}`, string(added[0].Instances[0].AttributesRaw))
+	assert.Equal(t, `provider["registry.terraform.io/hashicorp/aws"]`, added[1].Provider)
+	assert.JSONEq(t, `{"id":"app_logs","arn":"arn:aws:s3:::app_logs"}`, string(added[1].Instances[0].AttributesRaw))
+}
+
+func TestScannerModule_Refresh_ResourceTypes(t *testing.T) {
+	broker := &local.MemoryStateFileBroker{
+		StateFile: &infra_sdk.StateFile{
+			Version: 4,
+			Lineage: "abc",
+			Resources: []infra_sdk.StateFileResource{
+				{
+					Mode: "managed", Type: "aws_db_instance", Name: "gone",
+					Instances: []infra_sdk.StateFileInstance{{AttributesRaw: json.RawMessage(`{"id":"gone"}`)}},
+				},
+				{
+					Mode: "managed", Type: "aws_s3_bucket", Name: "kept",
+					Instances: []infra_sdk.StateFileInstance{{AttributesRaw: json.RawMessage(`{"id":"kept"}`)}},
+				},
+			},
+		},
+	}
+	// The scan found no database instances, but the scanner covers them, so the database is confirmed gone
+	module := infra_sdk.ScannerModule{
+		Scanner:          stubScanner{},
+		ResourceIdentity: stubResourceIdentity,
+		ResourceTypes:    []string{"aws_db_instance"},
+	}
+
+	result, err := module.Refresh(context.Background(), broker)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+	assert.Equal(t, "aws_s3_bucket.kept", result.Resources[0].FullyQualifiedName())
+}
+
+func TestScannerModule_Refresh_UnlocksOnError(t *testing.T) {
+	broker := &local.MemoryStateFileBroker{}
+	module := infra_sdk.ScannerModule{Scanner: stubScanner{err: assert.AnError}, ResourceIdentity: stubResourceIdentity}
+
+	_, err := module.Refresh(context.Background(), broker)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.False(t, broker.IsLocked())
+	assert.Equal(t, local.MemoryCallUnlock, broker.Calls[len(broker.Calls)-1].Method)
+}
+
+func TestScannerModule_Refresh_RequiresLoader(t *testing.T) {
+	// Embedding only the StateFileBroker interface hides Load
+	broker := struct{ infra_sdk.StateFileBroker }{&local.MemoryStateFileBroker{}}
+	module := infra_sdk.ScannerModule{Scanner: stubScanner{}, ResourceIdentity: stubResourceIdentity}
+
+	_, err := module.Refresh(context.Background(), broker)
+	assert.ErrorContains(t, err, "does not support loading state")
+}
@@ -0,0 +1,39 @@
+package infra_sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanResource_WithTags(t *testing.T) {
+	toUniversal := func(key string) string {
+		switch key {
+		case "Stack":
+			return UniversalTagStack
+		case "Env":
+			return UniversalTagEnv
+		case "Block":
+			return UniversalTagBlock
+		}
+		return key
+	}
+
+	got := ScanResource{UniqueId: "arn:aws:sqs:us-east-1:123:jobs"}.WithTags(map[string]string{
+		"Stack": "core",
+		"Env":   "prod",
+		"Block": "jobs",
+		"Name":  "jobs",
+	}, toUniversal)
+	assert.Equal(t, map[string]string{
+		UniversalTagStack: "core",
+		UniversalTagEnv:   "prod",
+		UniversalTagBlock: "jobs",
+		"Name":            "jobs",
+	}, got.Tags)
+	assert.Equal(t, Ownership{Stack: "core", Env: "prod", Block: "jobs"}, got.Ownership)
+
+	untagged := ScanResource{}.WithTags(nil, toUniversal)
+	assert.Equal(t, map[string]string{}, untagged.Tags)
+	assert.True(t, untagged.Ownership.IsZero())
+}
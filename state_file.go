@@ -33,7 +33,7 @@ type StateFileResource struct {
 
 func (r StateFileResource) FullyQualifiedName() string {
 	fullName := fmt.Sprintf("%s.%s", r.Type, r.Name)
-	if r.Module == "data" {
+	if r.Mode == "data" {
 		fullName = "data." + fullName
 	}
 	if r.Module != "" {
@@ -42,6 +42,20 @@ func (r StateFileResource) FullyQualifiedName() string {
 	return fullName
 }
 
+// InstanceAddress returns the address of a single instance of this resource
+// e.g. `module.network.aws_subnet.private[0]` or `aws_s3_bucket.this["logs"]`
+func (r StateFileResource) InstanceAddress(instance StateFileInstance) string {
+	switch key := instance.IndexKey.(type) {
+	case string:
+		return fmt.Sprintf("%s[%q]", r.FullyQualifiedName(), key)
+	case float64:
+		return fmt.Sprintf("%s[%d]", r.FullyQualifiedName(), int64(key))
+	case int:
+		return fmt.Sprintf("%s[%d]", r.FullyQualifiedName(), key)
+	}
+	return r.FullyQualifiedName()
+}
+
 type StateFileInstance struct {
 	IndexKey interface{} `json:"index_key,omitempty"`
 	Status   string      `json:"status,omitempty"`
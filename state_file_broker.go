@@ -2,6 +2,13 @@ package infra_sdk
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type StateFileBroker interface {
@@ -10,3 +17,108 @@ type StateFileBroker interface {
 	Initialize(ctx context.Context) error
 	Save(ctx context.Context, stateFile StateFile) error
 }
+
+// StateFileLoader is implemented by a StateFileBroker that can retrieve the current state file
+// This is optional so that existing StateFileBroker implementations continue to satisfy the interface
+type StateFileLoader interface {
+	// Load retrieves the current state file
+	// If no state file exists, nil is returned without an error
+	Load(ctx context.Context) (*StateFile, error)
+}
+
+const (
+	// StateFileVersion is the state file format version written by StateFileBroker implementations
+	StateFileVersion = 4
+
+	// StateLockOperationRefresh is the default lock operation
+	// This matches the operation that terraform records when running `terraform refresh`
+	StateLockOperationRefresh = "OperationTypeRefresh"
+)
+
+var (
+	ErrStateNotLocked       = errors.New("state must be locked before it is saved")
+	ErrStateLineageConflict = errors.New("state lineage does not match the existing state")
+	ErrStateSerialConflict  = errors.New("state serial is lower than the existing state")
+)
+
+// StateLockInfo is the metadata stored with a state lock
+// The json format matches terraform's lock info so that terraform reports who holds a lock acquired by this SDK
+type StateLockInfo struct {
+	ID        string    `json:"ID"`
+	Operation string    `json:"Operation"`
+	Info      string    `json:"Info"`
+	Who       string    `json:"Who"`
+	Version   string    `json:"Version"`
+	Created   time.Time `json:"Created"`
+	Path      string    `json:"Path"`
+}
+
+// NewStateLockInfo creates lock metadata for the current user/host
+// If operation is empty, StateLockOperationRefresh is used
+func NewStateLockInfo(operation, path string) StateLockInfo {
+	if operation == "" {
+		operation = StateLockOperationRefresh
+	}
+	who := "unknown"
+	if u, err := user.Current(); err == nil {
+		who = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		who = fmt.Sprintf("%s@%s", who, host)
+	}
+	return StateLockInfo{
+		ID:        uuid.NewString(),
+		Operation: operation,
+		Who:       who,
+		Version:   "infra-sdk",
+		Created:   time.Now().UTC(),
+		Path:      path,
+	}
+}
+
+// StateLockedError is returned when a lock is already held by someone else
+type StateLockedError struct {
+	Info StateLockInfo
+}
+
+func (e StateLockedError) Error() string {
+	return fmt.Sprintf("state is locked by %s (id=%s, operation=%s, created=%s)", e.Info.Who, e.Info.ID, e.Info.Operation, e.Info.Created.Format(time.RFC3339))
+}
+
+// NewStateFile creates an empty state file with a new lineage
+func NewStateFile() StateFile {
+	return StateFile{
+		Version:      StateFileVersion,
+		Serial:       0,
+		Lineage:      uuid.NewString(),
+		Outputs:      map[string]StateFileOutput{},
+		Resources:    []StateFileResource{},
+		CheckResults: nil,
+	}
+}
+
+// PrepareStateFileSave validates next against the current state and returns the state file to persist
+// A save is refused if next has a foreign lineage or a lower serial than current
+// The returned state file has current's lineage (if next has none) and an incremented serial
+func PrepareStateFileSave(current *StateFile, next StateFile) (StateFile, error) {
+	if next.Version == 0 {
+		next.Version = StateFileVersion
+	}
+	if current == nil {
+		if next.Lineage == "" {
+			next.Lineage = uuid.NewString()
+		}
+		return next, nil
+	}
+
+	if next.Lineage == "" {
+		next.Lineage = current.Lineage
+	} else if current.Lineage != "" && next.Lineage != current.Lineage {
+		return next, fmt.Errorf("%w: existing=%s, new=%s", ErrStateLineageConflict, current.Lineage, next.Lineage)
+	}
+	if next.Serial < current.Serial {
+		return next, fmt.Errorf("%w: existing=%d, new=%d", ErrStateSerialConflict, current.Serial, next.Serial)
+	}
+	next.Serial = current.Serial + 1
+	return next, nil
+}
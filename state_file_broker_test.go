@@ -0,0 +1,56 @@
+package infra_sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareStateFileSave(t *testing.T) {
+	current := &StateFile{Version: 4, Serial: 5, Lineage: "abc"}
+
+	tests := []struct {
+		name    string
+		current *StateFile
+		next    StateFile
+		wantErr error
+		want    StateFile
+	}{
+		{
+			name:    "increments serial and preserves lineage",
+			current: current,
+			next:    StateFile{Serial: 5},
+			want:    StateFile{Version: 4, Serial: 6, Lineage: "abc"},
+		},
+		{
+			name:    "lower serial",
+			current: current,
+			next:    StateFile{Serial: 4, Lineage: "abc"},
+			wantErr: ErrStateSerialConflict,
+		},
+		{
+			name:    "foreign lineage",
+			current: current,
+			next:    StateFile{Serial: 5, Lineage: "xyz"},
+			wantErr: ErrStateLineageConflict,
+		},
+		{
+			name: "no existing state",
+			next: StateFile{Serial: 3, Lineage: "xyz"},
+			want: StateFile{Version: 4, Serial: 3, Lineage: "xyz"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PrepareStateFileSave(tt.current, tt.next)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
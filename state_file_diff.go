@@ -0,0 +1,265 @@
+package infra_sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	simpleAttributeName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+)
+
+// StateFileDiff describes what changed between two state files
+type StateFileDiff struct {
+	FromSerial uint64 `json:"fromSerial"`
+	ToSerial   uint64 `json:"toSerial"`
+	// AddedResources are the addresses of resource instances that only exist in the newer state
+	AddedResources []string `json:"addedResources"`
+	// RemovedResources are the addresses of resource instances that only exist in the older state
+	RemovedResources []string                `json:"removedResources"`
+	ChangedInstances []StateFileInstanceDiff `json:"changedInstances"`
+	ChangedOutputs   []StateFileOutputChange `json:"changedOutputs"`
+	ChangedChecks    []StateFileCheckChange  `json:"changedChecks"`
+}
+
+func (d StateFileDiff) IsEmpty() bool {
+	return len(d.AddedResources) == 0 && len(d.RemovedResources) == 0 && len(d.ChangedInstances) == 0 &&
+		len(d.ChangedOutputs) == 0 && len(d.ChangedChecks) == 0
+}
+
+type StateFileInstanceDiff struct {
+	Address string                     `json:"address"`
+	Changes []StateFileAttributeChange `json:"changes"`
+}
+
+// StateFileAttributeChange is a single changed value within an instance's attributes
+// Path is a JSON path relative to the attributes (e.g. `tags["nullstone.io/env"]` or `ingress[0].cidr_blocks`)
+// The values of attributes listed in `sensitive_attributes` are omitted
+type StateFileAttributeChange struct {
+	Path      string `json:"path"`
+	Before    any    `json:"before"`
+	After     any    `json:"after"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+// StateFileOutputChange is an output that was added, removed, or changed
+// The values of sensitive outputs are omitted
+type StateFileOutputChange struct {
+	Name      string `json:"name"`
+	Before    any    `json:"before"`
+	After     any    `json:"after"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+// StateFileCheckChange is a check (or checkable object) whose status changed
+// An empty status indicates the check did not exist in that state
+type StateFileCheckChange struct {
+	Address string `json:"address"`
+	Before  string `json:"before"`
+	After   string `json:"after"`
+}
+
+// DiffStateFiles compares two state files and reports what changed from before to after
+func DiffStateFiles(before, after StateFile) (StateFileDiff, error) {
+	diff := StateFileDiff{
+		FromSerial:       before.Serial,
+		ToSerial:         after.Serial,
+		AddedResources:   []string{},
+		RemovedResources: []string{},
+		ChangedInstances: []StateFileInstanceDiff{},
+		ChangedOutputs:   []StateFileOutputChange{},
+		ChangedChecks:    []StateFileCheckChange{},
+	}
+
+	beforeInstances, afterInstances := indexInstances(before), indexInstances(after)
+	for _, address := range slices.Sorted(maps.Keys(afterInstances)) {
+		if _, ok := beforeInstances[address]; !ok {
+			diff.AddedResources = append(diff.AddedResources, address)
+		}
+	}
+	for _, address := range slices.Sorted(maps.Keys(beforeInstances)) {
+		afterInstance, ok := afterInstances[address]
+		if !ok {
+			diff.RemovedResources = append(diff.RemovedResources, address)
+			continue
+		}
+		beforeAttrs, err := beforeInstances[address].Attributes()
+		if err != nil {
+			return diff, fmt.Errorf("error reading attributes for %s: %w", address, err)
+		}
+		afterAttrs, err := afterInstance.Attributes()
+		if err != nil {
+			return diff, fmt.Errorf("error reading attributes for %s: %w", address, err)
+		}
+		sensitivePaths, err := diffSensitivePaths(beforeInstances[address], afterInstance)
+		if err != nil {
+			return diff, fmt.Errorf("error reading sensitive attributes for %s: %w", address, err)
+		}
+		changes := make([]StateFileAttributeChange, 0)
+		diffValues("", beforeAttrs, afterAttrs, &changes)
+		for i, change := range changes {
+			if isSensitiveAttributePath(change.Path, sensitivePaths) {
+				changes[i] = StateFileAttributeChange{Path: change.Path, Sensitive: true}
+			}
+		}
+		if len(changes) > 0 {
+			diff.ChangedInstances = append(diff.ChangedInstances, StateFileInstanceDiff{Address: address, Changes: changes})
+		}
+	}
+
+	outputNames := map[string]bool{}
+	for name := range before.Outputs {
+		outputNames[name] = true
+	}
+	for name := range after.Outputs {
+		outputNames[name] = true
+	}
+	for _, name := range slices.Sorted(maps.Keys(outputNames)) {
+		beforeOutput, inBefore := before.Outputs[name]
+		afterOutput, inAfter := after.Outputs[name]
+		var beforeValue, afterValue any
+		if inBefore {
+			_ = json.Unmarshal(beforeOutput.Value, &beforeValue)
+		}
+		if inAfter {
+			_ = json.Unmarshal(afterOutput.Value, &afterValue)
+		}
+		if inBefore == inAfter && reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+		change := StateFileOutputChange{Name: name, Before: beforeValue, After: afterValue}
+		if beforeOutput.Sensitive || afterOutput.Sensitive {
+			change = StateFileOutputChange{Name: name, Sensitive: true}
+		}
+		diff.ChangedOutputs = append(diff.ChangedOutputs, change)
+	}
+
+	beforeChecks, afterChecks := indexCheckStatuses(before), indexCheckStatuses(after)
+	checkAddresses := maps.Clone(beforeChecks)
+	maps.Copy(checkAddresses, afterChecks)
+	for _, address := range slices.Sorted(maps.Keys(checkAddresses)) {
+		if beforeChecks[address] != afterChecks[address] {
+			diff.ChangedChecks = append(diff.ChangedChecks, StateFileCheckChange{
+				Address: address,
+				Before:  beforeChecks[address],
+				After:   afterChecks[address],
+			})
+		}
+	}
+
+	return diff, nil
+}
+
+// indexInstances maps every current (non-deposed) instance by its address
+func indexInstances(stateFile StateFile) map[string]StateFileInstance {
+	result := map[string]StateFileInstance{}
+	for _, resource := range stateFile.Resources {
+		for _, instance := range resource.Instances {
+			if instance.Deposed == "" {
+				result[resource.InstanceAddress(instance)] = instance
+			}
+		}
+	}
+	return result
+}
+
+// indexCheckStatuses maps the status of every check and checkable object by address
+func indexCheckStatuses(stateFile StateFile) map[string]string {
+	result := map[string]string{}
+	for _, check := range stateFile.CheckResults {
+		result[check.ConfigAddr] = check.Status
+		for _, obj := range check.Objects {
+			result[obj.ObjectAddr] = obj.Status
+		}
+	}
+	return result
+}
+
+// diffValues recursively compares two decoded json values and records each changed leaf
+// Objects are compared by key and arrays by index; any other change is recorded at path
+func diffValues(path string, before, after any, changes *[]StateFileAttributeChange) {
+	switch b := before.(type) {
+	case map[string]any:
+		if a, ok := after.(map[string]any); ok {
+			keys := maps.Clone(b)
+			maps.Copy(keys, a)
+			names := slices.Sorted(maps.Keys(keys))
+			for _, key := range names {
+				diffValues(joinAttributePath(path, key), b[key], a[key], changes)
+			}
+			return
+		}
+	case []any:
+		if a, ok := after.([]any); ok {
+			for i := 0; i < max(len(a), len(b)); i++ {
+				var bv, av any
+				if i < len(b) {
+					bv = b[i]
+				}
+				if i < len(a) {
+					av = a[i]
+				}
+				diffValues(fmt.Sprintf("%s[%d]", path, i), bv, av, changes)
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, StateFileAttributeChange{Path: path, Before: before, After: after})
+	}
+}
+
+// diffSensitivePaths returns the sensitive attribute paths of either instance in the same format as StateFileAttributeChange.Path
+func diffSensitivePaths(before, after StateFileInstance) ([]string, error) {
+	var result []string
+	for _, instance := range []StateFileInstance{before, after} {
+		paths, err := instance.SensitivePaths()
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			result = append(result, diffAttributePath(path))
+		}
+	}
+	return result, nil
+}
+
+func diffAttributePath(path []StateFileAttributePathStep) string {
+	result := ""
+	for _, step := range path {
+		switch key := decodeIndexStep(step.Value).(type) {
+		case string:
+			result = joinAttributePath(result, key)
+		case json.Number:
+			result = fmt.Sprintf("%s[%s]", result, key.String())
+		default:
+			result = fmt.Sprintf("%s[%s]", result, string(step.Value))
+		}
+	}
+	return result
+}
+
+// isSensitiveAttributePath returns true if path is one of sensitivePaths or nested inside one of them
+func isSensitiveAttributePath(path string, sensitivePaths []string) bool {
+	for _, sensitive := range sensitivePaths {
+		if path == sensitive || strings.HasPrefix(path, sensitive+".") || strings.HasPrefix(path, sensitive+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+func joinAttributePath(path, key string) string {
+	if !simpleAttributeName.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
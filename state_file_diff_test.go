@@ -0,0 +1,136 @@
+package infra_sdk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffStateFiles(t *testing.T) {
+	before := StateFile{
+		Serial: 1,
+		Outputs: map[string]StateFileOutput{
+			"url":      {Value: json.RawMessage(`"http://a"`)},
+			"password": {Value: json.RawMessage(`"old"`), Sensitive: true},
+			"same":     {Value: json.RawMessage(`1`)},
+		},
+		Resources: []StateFileResource{
+			{
+				Mode: "managed", Type: "aws_s3_bucket", Name: "logs",
+				Instances: []StateFileInstance{
+					{AttributesRaw: json.RawMessage(`{"id":"logs","tags":{"nullstone.io/env":"dev"},"rules":[{"days":30}]}`)},
+				},
+			},
+			{
+				Mode: "managed", Type: "aws_sqs_queue", Name: "jobs",
+				Instances: []StateFileInstance{{AttributesRaw: json.RawMessage(`{"id":"jobs"}`)}},
+			},
+		},
+		CheckResults: []StateFileCheckResult{
+			{ObjectKind: "resource", ConfigAddr: "aws_s3_bucket.logs", Status: "pass"},
+		},
+	}
+	after := StateFile{
+		Serial: 2,
+		Outputs: map[string]StateFileOutput{
+			"url":      {Value: json.RawMessage(`"http://b"`)},
+			"password": {Value: json.RawMessage(`"new"`), Sensitive: true},
+			"same":     {Value: json.RawMessage(`1`)},
+		},
+		Resources: []StateFileResource{
+			{
+				Mode: "managed", Type: "aws_s3_bucket", Name: "logs",
+				Instances: []StateFileInstance{
+					{AttributesRaw: json.RawMessage(`{"id":"logs","tags":{"nullstone.io/env":"prod"},"rules":[{"days":60}]}`)},
+				},
+			},
+			{
+				Mode: "managed", Type: "aws_sns_topic", Name: "events",
+				Instances: []StateFileInstance{{IndexKey: "a", AttributesRaw: json.RawMessage(`{"id":"events"}`)}},
+			},
+		},
+		CheckResults: []StateFileCheckResult{
+			{ObjectKind: "resource", ConfigAddr: "aws_s3_bucket.logs", Status: "fail"},
+		},
+	}
+
+	diff, err := DiffStateFiles(before, after)
+	require.NoError(t, err)
+
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, uint64(1), diff.FromSerial)
+	assert.Equal(t, uint64(2), diff.ToSerial)
+	assert.Equal(t, []string{`aws_sns_topic.events["a"]`}, diff.AddedResources)
+	assert.Equal(t, []string{"aws_sqs_queue.jobs"}, diff.RemovedResources)
+	assert.Equal(t, []StateFileInstanceDiff{
+		{
+			Address: "aws_s3_bucket.logs",
+			Changes: []StateFileAttributeChange{
+				{Path: "rules[0].days", Before: float64(30), After: float64(60)},
+				{Path: `tags["nullstone.io/env"]`, Before: "dev", After: "prod"},
+			},
+		},
+	}, diff.ChangedInstances)
+	assert.Equal(t, []StateFileOutputChange{
+		{Name: "password", Sensitive: true},
+		{Name: "url", Before: "http://a", After: "http://b"},
+	}, diff.ChangedOutputs)
+	assert.Equal(t, []StateFileCheckChange{
+		{Address: "aws_s3_bucket.logs", Before: "pass", After: "fail"},
+	}, diff.ChangedChecks)
+
+	same, err := DiffStateFiles(before, before)
+	require.NoError(t, err)
+	assert.True(t, same.IsEmpty())
+}
+
+func TestDiffStateFiles_SensitiveAttributes(t *testing.T) {
+	sensitivePaths := json.RawMessage(`[
+		[{"type":"get_attr","value":"password"}],
+		[{"type":"get_attr","value":"env"},{"type":"index","value":{"value":0,"type":"number"}}],
+		[{"type":"get_attr","value":"tags"},{"type":"index","value":{"value":"nullstone.io/token","type":"string"}}]
+	]`)
+	before := StateFile{
+		Resources: []StateFileResource{
+			{
+				Mode: "managed", Type: "aws_db_instance", Name: "main",
+				Instances: []StateFileInstance{
+					{
+						AttributesRaw:           json.RawMessage(`{"id":"main","password":"old","port":5432,"env":[{"value":"a"},{"value":"b"}],"tags":{"nullstone.io/token":"abc"}}`),
+						AttributeSensitivePaths: sensitivePaths,
+					},
+				},
+			},
+		},
+	}
+	after := StateFile{
+		Resources: []StateFileResource{
+			{
+				Mode: "managed", Type: "aws_db_instance", Name: "main",
+				Instances: []StateFileInstance{
+					{
+						AttributesRaw:           json.RawMessage(`{"id":"main","password":"new","port":5433,"env":[{"value":"c"},{"value":"d"}],"tags":{"nullstone.io/token":"xyz"}}`),
+						AttributeSensitivePaths: sensitivePaths,
+					},
+				},
+			},
+		},
+	}
+
+	diff, err := DiffStateFiles(before, after)
+	require.NoError(t, err)
+	assert.Equal(t, []StateFileInstanceDiff{
+		{
+			Address: "aws_db_instance.main",
+			Changes: []StateFileAttributeChange{
+				{Path: "env[0].value", Sensitive: true},
+				{Path: "env[1].value", Before: "b", After: "d"},
+				{Path: "password", Sensitive: true},
+				{Path: "port", Before: float64(5432), After: float64(5433)},
+				{Path: `tags["nullstone.io/token"]`, Sensitive: true},
+			},
+		},
+	}, diff.ChangedInstances)
+}
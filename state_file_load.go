@@ -0,0 +1,413 @@
+package infra_sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// v3ResourceKey matches the key of a resource in a v3 state module (e.g. `data.aws_ami.ubuntu` or `aws_instance.web.1`)
+	v3ResourceKey = regexp.MustCompile(`^(data\.)?([^.]+)\.([^.]+)(?:\.(\d+))?$`)
+	// v3ProviderAddr matches a v3 provider reference (e.g. `provider.aws`, `provider.aws.west`, or `module.a.provider.aws`)
+	v3ProviderAddr = regexp.MustCompile(`(?:^|\.)provider\.([^.]+)(?:\.([^.]+))?$`)
+)
+
+// StateFileValidationError describes a single problem with a state file
+// Path is the location of the problem in the json document (e.g. `resources[2].instances[0].index_key`)
+type StateFileValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e StateFileValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// LoadStateFile decodes and validates a state file
+// Older state formats (version 3) are upgraded to version 4
+func LoadStateFile(raw []byte) (StateFile, error) {
+	var header struct {
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return StateFile{}, fmt.Errorf("invalid state file: %w", err)
+	}
+	if header.Version == nil {
+		return StateFile{}, StateFileValidationError{Path: "version", Message: "missing state file version"}
+	}
+
+	var stateFile StateFile
+	switch *header.Version {
+	case 3:
+		upgraded, err := UpgradeStateFileV3(raw)
+		if err != nil {
+			return StateFile{}, err
+		}
+		stateFile = upgraded
+	case StateFileVersion:
+		if err := json.Unmarshal(raw, &stateFile); err != nil {
+			return StateFile{}, fmt.Errorf("invalid state file: %w", err)
+		}
+	default:
+		return StateFile{}, StateFileValidationError{Path: "version", Message: fmt.Sprintf("unsupported state file version %d", *header.Version)}
+	}
+
+	if err := stateFile.Validate(); err != nil {
+		return StateFile{}, err
+	}
+	return stateFile, nil
+}
+
+// Validate checks that the state file is well-formed
+// Every problem is reported as a StateFileValidationError joined into a single error
+func (s StateFile) Validate() error {
+	var errs []error
+	addErr := func(path, format string, args ...any) {
+		errs = append(errs, StateFileValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Version != StateFileVersion {
+		addErr("version", "expected version %d, got %d", StateFileVersion, s.Version)
+	}
+	if s.Lineage == "" {
+		addErr("lineage", "lineage is required")
+	}
+
+	addresses := map[string]int{}
+	for i, resource := range s.Resources {
+		path := fmt.Sprintf("resources[%d]", i)
+		if resource.Mode != "managed" && resource.Mode != "data" {
+			addErr(path+".mode", "invalid mode %q", resource.Mode)
+		}
+		if resource.Type == "" {
+			addErr(path+".type", "type is required")
+		}
+		if resource.Name == "" {
+			addErr(path+".name", "name is required")
+		}
+		if resource.Type == "" || resource.Name == "" {
+			continue
+		}
+
+		address := resource.FullyQualifiedName()
+		if addr, err := ParseStateAddress(address); err != nil || addr.Module != resource.Module {
+			addErr(path+".module", "invalid module path %q", resource.Module)
+		}
+		if prev, ok := addresses[address]; ok {
+			addErr(path, "duplicate resource address %s (also at resources[%d])", address, prev)
+		} else {
+			addresses[address] = i
+		}
+
+		keyKind := ""
+		keys := map[string]bool{}
+		for j, instance := range resource.Instances {
+			instancePath := fmt.Sprintf("%s.instances[%d]", path, j)
+			kind, err := indexKeyKind(instance.IndexKey)
+			if err != nil {
+				addErr(instancePath+".index_key", "%s", err)
+				continue
+			}
+			if j == 0 {
+				keyKind = kind
+			} else if kind != keyKind {
+				addErr(instancePath+".index_key", "index key type %q does not match other instances (%q)", kind, keyKind)
+			}
+			key := fmt.Sprintf("%s/%s", resource.InstanceAddress(instance), instance.Deposed)
+			if keys[key] {
+				addErr(instancePath, "duplicate instance %s", resource.InstanceAddress(instance))
+			}
+			keys[key] = true
+		}
+	}
+
+	for i, resource := range s.Resources {
+		for j, instance := range resource.Instances {
+			for k, dep := range instance.Dependencies {
+				depPath := fmt.Sprintf("resources[%d].instances[%d].dependencies[%d]", i, j, k)
+				addr, err := ParseStateAddress(dep)
+				if err != nil {
+					addErr(depPath, "invalid dependency address %q", dep)
+					continue
+				}
+				if _, ok := addresses[addr.ResourceAddress()]; !ok {
+					addErr(depPath, "dependency %s does not exist", dep)
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// indexKeyKind returns "" (no key), "string" (for_each), or "int" (count)
+func indexKeyKind(key any) (string, error) {
+	switch k := key.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return "string", nil
+	case float64:
+		if k < 0 || k != math.Trunc(k) {
+			return "", fmt.Errorf("invalid count index %v", k)
+		}
+		return "int", nil
+	case int:
+		if k < 0 {
+			return "", fmt.Errorf("invalid count index %v", k)
+		}
+		return "int", nil
+	}
+	return "", fmt.Errorf("invalid index key %v (%T)", key, key)
+}
+
+type stateFileV3 struct {
+	Version          int             `json:"version"`
+	TerraformVersion string          `json:"terraform_version"`
+	Serial           uint64          `json:"serial"`
+	Lineage          string          `json:"lineage"`
+	Modules          []stateModuleV3 `json:"modules"`
+}
+
+type stateModuleV3 struct {
+	Path      []string                   `json:"path"`
+	Outputs   map[string]stateOutputV3   `json:"outputs"`
+	Resources map[string]stateResourceV3 `json:"resources"`
+}
+
+type stateOutputV3 struct {
+	Sensitive bool            `json:"sensitive"`
+	Type      string          `json:"type"`
+	Value     json.RawMessage `json:"value"`
+}
+
+type stateResourceV3 struct {
+	Type      string             `json:"type"`
+	DependsOn []string           `json:"depends_on"`
+	Primary   *stateInstanceV3   `json:"primary"`
+	Deposed   []*stateInstanceV3 `json:"deposed"`
+	Provider  string             `json:"provider"`
+}
+
+type stateInstanceV3 struct {
+	ID         string            `json:"id"`
+	Attributes map[string]string `json:"attributes"`
+	Meta       map[string]any    `json:"meta"`
+	Tainted    bool              `json:"tainted"`
+}
+
+// UpgradeStateFileV3 converts a version 3 state file (terraform < 0.12) into the version 4 structure
+// Instance attributes are preserved as flat attributes since v3 does not contain schema information
+func UpgradeStateFileV3(raw []byte) (StateFile, error) {
+	var v3 stateFileV3
+	if err := json.Unmarshal(raw, &v3); err != nil {
+		return StateFile{}, fmt.Errorf("invalid version 3 state file: %w", err)
+	}
+	if v3.Version != 3 {
+		return StateFile{}, StateFileValidationError{Path: "version", Message: fmt.Sprintf("expected version 3, got %d", v3.Version)}
+	}
+
+	result := StateFile{
+		Version:          StateFileVersion,
+		TerraformVersion: v3.TerraformVersion,
+		Serial:           v3.Serial,
+		Lineage:          v3.Lineage,
+		Outputs:          map[string]StateFileOutput{},
+		Resources:        []StateFileResource{},
+	}
+
+	resourceIdx := map[string]int{}
+	for m, module := range v3.Modules {
+		modulePath, err := upgradeModulePathV3(module.Path)
+		if err != nil {
+			return StateFile{}, StateFileValidationError{Path: fmt.Sprintf("modules[%d].path", m), Message: err.Error()}
+		}
+
+		// Only root module outputs are retained in version 4
+		if modulePath == "" {
+			for name, output := range module.Outputs {
+				typ, err := json.Marshal(inferCtyType(output.Value))
+				if err != nil {
+					return StateFile{}, err
+				}
+				result.Outputs[name] = StateFileOutput{Value: output.Value, Type: typ, Sensitive: output.Sensitive}
+			}
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(module.Resources)) {
+			rs := module.Resources[key]
+			path := fmt.Sprintf("modules[%d].resources[%q]", m, key)
+			match := v3ResourceKey.FindStringSubmatch(key)
+			if match == nil {
+				return StateFile{}, StateFileValidationError{Path: path, Message: "invalid resource key"}
+			}
+			mode := "managed"
+			if match[1] != "" {
+				mode = "data"
+			}
+			resource := StateFileResource{
+				Module:   modulePath,
+				Mode:     mode,
+				Type:     match[2],
+				Name:     match[3],
+				Provider: upgradeProviderV3(rs.Provider, match[2]),
+			}
+			address := resource.FullyQualifiedName()
+			idx, ok := resourceIdx[address]
+			if !ok {
+				result.Resources = append(result.Resources, resource)
+				idx = len(result.Resources) - 1
+				resourceIdx[address] = idx
+			}
+
+			var indexKey any
+			if match[4] != "" {
+				n, _ := strconv.Atoi(match[4])
+				indexKey = float64(n)
+			}
+			dependencies := upgradeDependenciesV3(rs.DependsOn, modulePath)
+			if rs.Primary != nil {
+				instance := upgradeInstanceV3(rs.Primary, indexKey, "", dependencies)
+				result.Resources[idx].Instances = append(result.Resources[idx].Instances, instance)
+			}
+			for d, deposed := range rs.Deposed {
+				instance := upgradeInstanceV3(deposed, indexKey, fmt.Sprintf("%08x", d+1), dependencies)
+				result.Resources[idx].Instances = append(result.Resources[idx].Instances, instance)
+			}
+		}
+	}
+
+	// v3 stores resources in a map, so instances are sorted by their count index
+	for i := range result.Resources {
+		sort.SliceStable(result.Resources[i].Instances, func(a, b int) bool {
+			ka, _ := result.Resources[i].Instances[a].IndexKey.(float64)
+			kb, _ := result.Resources[i].Instances[b].IndexKey.(float64)
+			return ka < kb
+		})
+	}
+	return result, nil
+}
+
+func upgradeInstanceV3(is *stateInstanceV3, indexKey any, deposed string, dependencies []string) StateFileInstance {
+	instance := StateFileInstance{
+		IndexKey:       indexKey,
+		Deposed:        deposed,
+		AttributesFlat: is.Attributes,
+		Dependencies:   dependencies,
+	}
+	if instance.AttributesFlat == nil {
+		instance.AttributesFlat = map[string]string{}
+	}
+	if _, ok := instance.AttributesFlat["id"]; !ok && is.ID != "" {
+		instance.AttributesFlat["id"] = is.ID
+	}
+	if is.Tainted {
+		instance.Status = "tainted"
+	}
+	if raw, ok := is.Meta["schema_version"]; ok {
+		if s, ok := raw.(string); ok {
+			instance.SchemaVersion, _ = strconv.ParseUint(s, 10, 64)
+		}
+	}
+	return instance
+}
+
+// upgradeModulePathV3 converts ["root", "a", "b"] into `module.a.module.b`
+func upgradeModulePathV3(path []string) (string, error) {
+	if len(path) == 0 || path[0] != "root" {
+		return "", fmt.Errorf("module path must start with \"root\"")
+	}
+	parts := make([]string, 0, len(path)-1)
+	for _, name := range path[1:] {
+		parts = append(parts, "module."+name)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// upgradeProviderV3 converts `provider.aws` (or `provider.aws.alias`) into `provider["registry.terraform.io/hashicorp/aws"]`
+// If no provider is recorded, it is inferred from the resource type
+// Providers configured in a module (e.g. `module.a.provider.aws`) keep their module prefix
+func upgradeProviderV3(provider, resourceType string) string {
+	name, alias := "", ""
+	if match := v3ProviderAddr.FindStringSubmatch(provider); match != nil {
+		name, alias = match[1], match[2]
+	} else {
+		name, _, _ = strings.Cut(resourceType, "_")
+	}
+	result := fmt.Sprintf("provider[%q]", "registry.terraform.io/hashicorp/"+name)
+	if alias != "" {
+		result += "." + alias
+	}
+	if strings.HasPrefix(provider, "module.") {
+		result = strings.TrimSuffix(provider, strings.TrimPrefix(v3ProviderAddr.FindString(provider), ".")) + result
+	}
+	return result
+}
+
+// upgradeDependenciesV3 converts module-relative `depends_on` entries into absolute resource addresses
+// e.g. `aws_instance.web.*` in module a => `module.a.aws_instance.web`
+// Dependencies on modules (e.g. `module.network`) are dropped since version 4 only records resource dependencies
+func upgradeDependenciesV3(dependsOn []string, modulePath string) []string {
+	var result []string
+	for _, dep := range dependsOn {
+		dep = strings.TrimSuffix(dep, ".*")
+		match := v3ResourceKey.FindStringSubmatch(dep)
+		if match == nil || strings.HasPrefix(dep, "module.") {
+			continue
+		}
+		address := StateFileResource{Module: modulePath, Mode: "managed", Type: match[2], Name: match[3]}
+		if match[1] != "" {
+			address.Mode = "data"
+		}
+		fqn := address.FullyQualifiedName()
+		if !slices.Contains(result, fqn) {
+			result = append(result, fqn)
+		}
+	}
+	return result
+}
+
+// inferCtyType infers the json encoding of a cty type from a json value
+// Version 3 only records "string", "list", or "map" for outputs
+func inferCtyType(raw json.RawMessage) any {
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "dynamic"
+	}
+	return inferCtyTypeFromValue(value)
+}
+
+func inferCtyTypeFromValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case []any:
+		elems := make([]any, 0, len(v))
+		for _, elem := range v {
+			elems = append(elems, inferCtyTypeFromValue(elem))
+		}
+		return []any{"tuple", elems}
+	case map[string]any:
+		attrs := map[string]any{}
+		for k, elem := range v {
+			attrs[k] = inferCtyTypeFromValue(elem)
+		}
+		return []any{"object", attrs}
+	}
+	return "dynamic"
+}
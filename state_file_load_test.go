@@ -0,0 +1,116 @@
+package infra_sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadStateFile_V3(t *testing.T) {
+	raw := []byte(`{
+		"version": 3,
+		"terraform_version": "0.11.14",
+		"serial": 7,
+		"lineage": "abc",
+		"modules": [
+			{
+				"path": ["root"],
+				"outputs": {
+					"ids": {"sensitive": false, "type": "list", "value": ["a", "b"]}
+				},
+				"resources": {
+					"aws_instance.web.1": {"type": "aws_instance", "depends_on": ["aws_security_group.web"], "primary": {"id": "i-2", "attributes": {"id": "i-2"}, "meta": {"schema_version": "1"}}, "provider": "provider.aws"},
+					"aws_instance.web.0": {"type": "aws_instance", "depends_on": ["aws_security_group.web"], "primary": {"id": "i-1", "attributes": {"id": "i-1"}, "tainted": true}, "provider": "provider.aws"},
+					"aws_security_group.web": {"type": "aws_security_group", "primary": {"id": "sg-1", "attributes": {"id": "sg-1"}}, "provider": "provider.aws"},
+					"data.aws_ami.ubuntu": {"type": "aws_ami", "primary": {"id": "ami-1", "attributes": {"id": "ami-1"}}, "provider": "provider.aws.west"}
+				}
+			},
+			{
+				"path": ["root", "network"],
+				"resources": {
+					"aws_vpc.main": {"type": "aws_vpc", "primary": {"id": "vpc-1", "attributes": {}}}
+				}
+			}
+		]
+	}`)
+
+	stateFile, err := LoadStateFile(raw)
+	require.NoError(t, err)
+	assert.Equal(t, 4, stateFile.Version)
+	assert.Equal(t, uint64(7), stateFile.Serial)
+	assert.Equal(t, "abc", stateFile.Lineage)
+	assert.JSONEq(t, `["tuple",["string","string"]]`, string(stateFile.Outputs["ids"].Type))
+
+	web, instance, err := stateFile.FindInstance("aws_instance.web[0]")
+	require.NoError(t, err)
+	require.NotNil(t, instance)
+	assert.Equal(t, `provider["registry.terraform.io/hashicorp/aws"]`, web.Provider)
+	assert.Equal(t, "tainted", instance.Status)
+	assert.Equal(t, []string{"aws_security_group.web"}, instance.Dependencies)
+	require.Len(t, web.Instances, 2)
+	assert.Equal(t, uint64(1), web.Instances[1].SchemaVersion)
+
+	ami, err := stateFile.FindResource("data.aws_ami.ubuntu")
+	require.NoError(t, err)
+	require.NotNil(t, ami)
+	assert.Equal(t, `provider["registry.terraform.io/hashicorp/aws"].west`, ami.Provider)
+
+	_, vpc, err := stateFile.FindInstance("module.network.aws_vpc.main")
+	require.NoError(t, err)
+	require.NotNil(t, vpc)
+	assert.Equal(t, "vpc-1", vpc.AttributesFlat["id"])
+}
+
+func TestStateFile_Validate(t *testing.T) {
+	stateFile := StateFile{
+		Version: 4,
+		Resources: []StateFileResource{
+			{Mode: "managed", Type: "aws_s3_bucket", Name: "logs", Instances: []StateFileInstance{
+				{IndexKey: float64(0)},
+				{IndexKey: "a"},
+				{IndexKey: float64(-1)},
+			}},
+			{Mode: "managed", Type: "aws_s3_bucket", Name: "logs"},
+			{Mode: "resource", Type: "aws_sqs_queue", Name: "jobs", Instances: []StateFileInstance{
+				{Dependencies: []string{"aws_sns_topic.missing"}},
+			}},
+		},
+	}
+
+	err := stateFile.Validate()
+	require.Error(t, err)
+
+	var paths []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var verr StateFileValidationError
+		require.True(t, errors.As(e, &verr))
+		paths = append(paths, verr.Path)
+	}
+	assert.Equal(t, []string{
+		"lineage",
+		"resources[0].instances[1].index_key",
+		"resources[0].instances[2].index_key",
+		"resources[1]",
+		"resources[2].mode",
+		"resources[2].instances[0].dependencies[0]",
+	}, paths)
+}
+
+func TestLoadStateFile_InvalidVersion(t *testing.T) {
+	_, err := LoadStateFile([]byte(`{"version": 2}`))
+	var verr StateFileValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "version", verr.Path)
+
+	_, err = LoadStateFile(json.RawMessage(`{"lineage": "abc"}`))
+	assert.ErrorAs(t, err, &verr)
+}
+
+func TestUpgradeProviderV3(t *testing.T) {
+	assert.Equal(t, `provider["registry.terraform.io/hashicorp/aws"]`, upgradeProviderV3("provider.aws", "aws_vpc"))
+	assert.Equal(t, `provider["registry.terraform.io/hashicorp/google"]`, upgradeProviderV3("", "google_compute_network"))
+	assert.Equal(t, `module.a.provider["registry.terraform.io/hashicorp/aws"].west`, upgradeProviderV3("module.a.provider.aws.west", "aws_vpc"))
+}
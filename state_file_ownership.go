@@ -0,0 +1,215 @@
+package infra_sdk
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	// ownershipTagAttributes are the state attributes that contain tags/labels, in order of precedence
+	// tags_all and effective_labels include provider-level default tags, which is where nullstone tags are usually set
+	ownershipTagAttributes = []string{"tags_all", "tags", "effective_labels", "labels"}
+
+	// workspaceOwnershipAttributes maps the attributes of the `ns_workspace` data source to universal tags
+	workspaceOwnershipAttributes = map[string]string{
+		"stack_name": UniversalTagStack,
+		"env_name":   UniversalTagEnv,
+		"block_name": UniversalTagBlock,
+	}
+)
+
+// Ownership identifies the nullstone stack, env, and block that owns a resource
+type Ownership struct {
+	Stack string `json:"stack"`
+	Env   string `json:"env"`
+	Block string `json:"block"`
+}
+
+func (o Ownership) IsZero() bool {
+	return o.Stack == "" && o.Env == "" && o.Block == ""
+}
+
+// Tags returns the ownership as universal tags, omitting empty values
+func (o Ownership) Tags() map[string]string {
+	result := map[string]string{}
+	for key, value := range map[string]string{UniversalTagStack: o.Stack, UniversalTagEnv: o.Env, UniversalTagBlock: o.Block} {
+		if value != "" {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// withDefaults fills any empty values from fallback
+func (o Ownership) withDefaults(fallback Ownership) Ownership {
+	if o.Stack == "" {
+		o.Stack = fallback.Stack
+	}
+	if o.Env == "" {
+		o.Env = fallback.Env
+	}
+	if o.Block == "" {
+		o.Block = fallback.Block
+	}
+	return o
+}
+
+func (o *Ownership) set(universalTag, value string) {
+	switch universalTag {
+	case UniversalTagStack:
+		o.Stack = value
+	case UniversalTagEnv:
+		o.Env = value
+	case UniversalTagBlock:
+		o.Block = value
+	}
+}
+
+// ManagedResource is a cloud resource managed by a state file
+type ManagedResource struct {
+	Address  string `json:"address"`
+	Type     string `json:"type"`
+	Provider string `json:"provider"`
+	// UniqueIds are the cloud identifiers of the resource (arn, id, self_link)
+	// A ScanResource is the same resource if its UniqueId matches one of these
+	UniqueIds []string `json:"uniqueIds"`
+	// Tags are the tags/labels on the resource; nullstone tags are converted to universal tags
+	Tags      map[string]string `json:"tags"`
+	Ownership Ownership         `json:"ownership"`
+}
+
+// StateOwnership is the set of cloud resources managed by a state file and the nullstone block that owns each
+type StateOwnership struct {
+	// Ownership is the owner of the state file as a whole
+	// This is resolved from the `ns_workspace` data source, overridden by the `stack_name`, `env_name`, `block_name` outputs
+	Ownership Ownership         `json:"ownership"`
+	Resources []ManagedResource `json:"resources"`
+}
+
+// ResolveOwnership extracts the cloud resources managed by a StateFile along with their nullstone ownership
+// Ownership tags on a resource take precedence over the ownership of the state file
+func ResolveOwnership(stateFile StateFile) (StateOwnership, error) {
+	stateOwner, err := resolveStateOwner(stateFile)
+	if err != nil {
+		return StateOwnership{}, err
+	}
+
+	result := StateOwnership{Ownership: stateOwner, Resources: []ManagedResource{}}
+	for _, resource := range stateFile.Resources {
+		if resource.Mode != "managed" {
+			continue
+		}
+		for _, instance := range resource.Instances {
+			if instance.Deposed != "" {
+				continue
+			}
+			address := resource.InstanceAddress(instance)
+			attrs, err := instance.Attributes()
+			if err != nil {
+				return result, fmt.Errorf("error reading attributes for %s: %w", address, err)
+			}
+
+			managed := ManagedResource{
+				Address:   address,
+				Type:      resource.Type,
+				Provider:  resource.Provider,
+				UniqueIds: []string{},
+				Tags:      resourceTags(attrs),
+			}
+			for _, attrName := range driftIdentifierAttributes {
+				if id, _ := attrs[attrName].(string); id != "" && !containsUniqueId(managed.UniqueIds, id) {
+					managed.UniqueIds = append(managed.UniqueIds, id)
+				}
+			}
+			if len(managed.UniqueIds) == 0 {
+				continue
+			}
+			for key, value := range managed.Tags {
+				managed.Ownership.set(key, value)
+			}
+			managed.Ownership = managed.Ownership.withDefaults(stateOwner)
+			result.Resources = append(result.Resources, managed)
+		}
+	}
+	return result, nil
+}
+
+// FindByUniqueId finds the managed resource with the cloud identifier (e.g. ScanResource.UniqueId)
+func (o StateOwnership) FindByUniqueId(id string) (ManagedResource, bool) {
+	for _, resource := range o.Resources {
+		if containsUniqueId(resource.UniqueIds, id) {
+			return resource, true
+		}
+	}
+	return ManagedResource{}, false
+}
+
+func resolveStateOwner(stateFile StateFile) (Ownership, error) {
+	var result Ownership
+	for _, resource := range stateFile.Resources {
+		if resource.Module != "" || resource.Mode != "data" || resource.Type != "ns_workspace" {
+			continue
+		}
+		for _, instance := range resource.Instances {
+			attrs, err := instance.Attributes()
+			if err != nil {
+				return result, fmt.Errorf("error reading attributes for %s: %w", resource.InstanceAddress(instance), err)
+			}
+			for attrName, tag := range workspaceOwnershipAttributes {
+				if value, _ := attrs[attrName].(string); value != "" {
+					result.set(tag, value)
+				}
+			}
+		}
+	}
+
+	for outputName, tag := range workspaceOwnershipAttributes {
+		output, ok := stateFile.Outputs[outputName]
+		if !ok {
+			continue
+		}
+		var value string
+		if err := output.DecodeInto(&value); err != nil {
+			return result, fmt.Errorf("error reading output %q: %w", outputName, err)
+		}
+		if value != "" {
+			result.set(tag, value)
+		}
+	}
+	return result, nil
+}
+
+// resourceTags merges the tag attributes of a resource instance
+func resourceTags(attrs map[string]any) map[string]string {
+	result := map[string]string{}
+	for i := len(ownershipTagAttributes) - 1; i >= 0; i-- {
+		tags, _ := attrs[ownershipTagAttributes[i]].(map[string]any)
+		for key, value := range tags {
+			if s, ok := value.(string); ok {
+				result[universalOwnershipTag(key)] = s
+			}
+		}
+	}
+	return result
+}
+
+// universalOwnershipTag converts a provider tag key for nullstone ownership into a universal tag
+// AWS and Azure use `Stack`, `Env`, `Block` while GCP uses lowercase labels
+func universalOwnershipTag(key string) string {
+	for _, tag := range []string{UniversalTagStack, UniversalTagEnv, UniversalTagBlock} {
+		if strings.EqualFold(key, tag) || strings.EqualFold(key, strings.TrimPrefix(tag, "nullstone.io/")) {
+			return tag
+		}
+	}
+	return key
+}
+
+func containsUniqueId(ids []string, id string) bool {
+	normalized := normalizeUniqueId(id)
+	for _, cur := range ids {
+		if normalizeUniqueId(cur) == normalized {
+			return true
+		}
+	}
+	return false
+}
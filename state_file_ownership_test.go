@@ -0,0 +1,89 @@
+package infra_sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveOwnership(t *testing.T) {
+	stateFile := StateFile{
+		Version: StateFileVersion,
+		Outputs: map[string]StateFileOutput{
+			"env_name": {Value: []byte(`"prod"`), Type: []byte(`"string"`)},
+		},
+		Resources: []StateFileResource{
+			{
+				Mode: "data", Type: "ns_workspace", Name: "this",
+				Instances: []StateFileInstance{{AttributesRaw: []byte(`{"stack_name":"core","env_name":"dev","block_name":"api"}`)}},
+			},
+			{
+				Mode: "managed", Type: "aws_s3_bucket", Name: "logs",
+				Instances: []StateFileInstance{{AttributesRaw: []byte(`{
+					"id": "logs",
+					"arn": "arn:aws:s3:::logs",
+					"tags": {"Name": "logs"},
+					"tags_all": {"Name": "logs", "Block": "logs-bucket"}
+				}`)}},
+			},
+			{
+				Mode: "managed", Type: "google_storage_bucket", Name: "assets",
+				Instances: []StateFileInstance{{AttributesRaw: []byte(`{
+					"id": "assets",
+					"self_link": "https://www.googleapis.com/storage/v1/b/assets",
+					"effective_labels": {"stack": "shared"}
+				}`)}},
+			},
+			{
+				Mode: "managed", Type: "random_password", Name: "db",
+				Instances: []StateFileInstance{{AttributesRaw: []byte(`{"result":"secret"}`)}},
+			},
+		},
+	}
+
+	got, err := ResolveOwnership(stateFile)
+	require.NoError(t, err)
+	assert.Equal(t, Ownership{Stack: "core", Env: "prod", Block: "api"}, got.Ownership)
+	require.Len(t, got.Resources, 2)
+
+	bucket, ok := got.FindByUniqueId("ARN:AWS:S3:::LOGS")
+	require.True(t, ok)
+	assert.Equal(t, "aws_s3_bucket.logs", bucket.Address)
+	assert.Equal(t, []string{"arn:aws:s3:::logs", "logs"}, bucket.UniqueIds)
+	assert.Equal(t, map[string]string{"Name": "logs", UniversalTagBlock: "logs-bucket"}, bucket.Tags)
+	assert.Equal(t, Ownership{Stack: "core", Env: "prod", Block: "logs-bucket"}, bucket.Ownership)
+
+	assets, ok := got.FindByUniqueId("https://www.googleapis.com/storage/v1/b/assets")
+	require.True(t, ok)
+	assert.Equal(t, Ownership{Stack: "shared", Env: "prod", Block: "api"}, assets.Ownership)
+	assert.Equal(t, map[string]string{UniversalTagStack: "shared", UniversalTagEnv: "prod", UniversalTagBlock: "api"}, assets.Ownership.Tags())
+
+	_, ok = got.FindByUniqueId("missing")
+	assert.False(t, ok)
+}
+
+func TestResolveOwnership_Route53(t *testing.T) {
+	stateFile := StateFile{
+		Version: StateFileVersion,
+		Resources: []StateFileResource{
+			{
+				Mode: "managed", Type: "aws_route53_zone", Name: "primary",
+				Instances: []StateFileInstance{{AttributesRaw: []byte(`{
+					"arn": "arn:aws:route53:::hostedzone/Z123",
+					"id": "Z123",
+					"tags_all": {"Stack": "core", "Env": "prod", "Block": "dns"}
+				}`)}},
+			},
+		},
+	}
+
+	got, err := ResolveOwnership(stateFile)
+	require.NoError(t, err)
+
+	// Route53 scanners report the zone id with a "/hostedzone/" prefix
+	zone, ok := got.FindByUniqueId("/hostedzone/Z123")
+	require.True(t, ok)
+	assert.Equal(t, "aws_route53_zone.primary", zone.Address)
+	assert.Equal(t, Ownership{Stack: "core", Env: "prod", Block: "dns"}, zone.Ownership)
+}
@@ -0,0 +1,448 @@
+package infra_sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// StateAddress is a parsed resource or resource instance address
+// e.g. `module.app["api"].aws_ecs_service.this[0]`
+type StateAddress struct {
+	// Module is the module path in the same format as StateFileResource.Module (e.g. `module.app["api"]`)
+	Module string
+	Mode   string
+	Type   string
+	Name   string
+	// IndexKey is nil, a string (for_each), or an int (count)
+	IndexKey any
+}
+
+// ResourceAddress returns the address without the instance key
+func (a StateAddress) ResourceAddress() string {
+	return StateFileResource{Module: a.Module, Mode: a.Mode, Type: a.Type, Name: a.Name}.FullyQualifiedName()
+}
+
+func (a StateAddress) String() string {
+	return StateFileResource{Module: a.Module, Mode: a.Mode, Type: a.Type, Name: a.Name}.InstanceAddress(StateFileInstance{IndexKey: a.IndexKey})
+}
+
+// ParseStateAddress parses a resource or resource instance address
+func ParseStateAddress(address string) (StateAddress, error) {
+	segments, err := splitAddress(address)
+	if err != nil {
+		return StateAddress{}, fmt.Errorf("invalid address %q: %w", address, err)
+	}
+
+	result := StateAddress{Mode: "managed"}
+	modules := make([]string, 0)
+	i := 0
+	for i < len(segments) && segments[i].name == "module" && segments[i].key == nil {
+		if i+1 >= len(segments) {
+			return StateAddress{}, fmt.Errorf("invalid address %q: missing module name", address)
+		}
+		modules = append(modules, "module."+segments[i+1].String())
+		i += 2
+	}
+	result.Module = strings.Join(modules, ".")
+
+	if i < len(segments) && segments[i].name == "data" && segments[i].key == nil {
+		result.Mode = "data"
+		i++
+	}
+	if len(segments)-i != 2 {
+		return StateAddress{}, fmt.Errorf("invalid address %q: expected <type>.<name>", address)
+	}
+	if segments[i].key != nil {
+		return StateAddress{}, fmt.Errorf("invalid address %q: resource type cannot have an index", address)
+	}
+	result.Type = segments[i].name
+	result.Name = segments[i+1].name
+	result.IndexKey = segments[i+1].key
+	return result, nil
+}
+
+// FindResource finds a resource by address (e.g. `module.network.aws_subnet.private`)
+// If the address contains an instance key, it is ignored
+func (s StateFile) FindResource(address string) (*StateFileResource, error) {
+	addr, err := ParseStateAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	for i, resource := range s.Resources {
+		if resource.Module == addr.Module && resource.Mode == addr.Mode && resource.Type == addr.Type && resource.Name == addr.Name {
+			return &s.Resources[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// FindInstance finds a resource instance by address (e.g. `aws_subnet.private[0]` or `aws_s3_bucket.this["logs"]`)
+// The current (non-deposed) instance is returned
+func (s StateFile) FindInstance(address string) (*StateFileResource, *StateFileInstance, error) {
+	addr, err := ParseStateAddress(address)
+	if err != nil {
+		return nil, nil, err
+	}
+	resource, err := s.FindResource(address)
+	if err != nil || resource == nil {
+		return nil, nil, err
+	}
+	for i, instance := range resource.Instances {
+		if instance.Deposed == "" && indexKeysEqual(instance.IndexKey, addr.IndexKey) {
+			return resource, &resource.Instances[i], nil
+		}
+	}
+	return resource, nil, nil
+}
+
+// ResourcesByType returns all resources of the specified type in every module
+func (s StateFile) ResourcesByType(resourceType string) []StateFileResource {
+	result := make([]StateFileResource, 0)
+	for _, resource := range s.Resources {
+		if resource.Type == resourceType {
+			result = append(result, resource)
+		}
+	}
+	return result
+}
+
+// ResourcesInModule returns the resources that are directly in module (e.g. `module.network`)
+// Use an empty string for the root module
+func (s StateFile) ResourcesInModule(module string) []StateFileResource {
+	result := make([]StateFileResource, 0)
+	for _, resource := range s.Resources {
+		if resource.Module == module {
+			result = append(result, resource)
+		}
+	}
+	return result
+}
+
+// Dependencies returns the resources that the instance at address depends on
+func (s StateFile) Dependencies(address string) ([]StateFileResource, error) {
+	_, instance, err := s.FindInstance(address)
+	if err != nil {
+		return nil, err
+	}
+	if instance == nil {
+		return nil, fmt.Errorf("resource instance %q does not exist", address)
+	}
+	result := make([]StateFileResource, 0, len(instance.Dependencies))
+	for _, dep := range instance.Dependencies {
+		resource, err := s.FindResource(dep)
+		if err != nil {
+			return nil, err
+		}
+		if resource != nil {
+			result = append(result, *resource)
+		}
+	}
+	return result, nil
+}
+
+// Dependents returns the resources with an instance that depends on the resource at address
+func (s StateFile) Dependents(address string) ([]StateFileResource, error) {
+	addr, err := ParseStateAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	target := addr.ResourceAddress()
+	result := make([]StateFileResource, 0)
+	for _, resource := range s.Resources {
+		for _, instance := range resource.Instances {
+			if slices.Contains(instance.Dependencies, target) {
+				result = append(result, resource)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// Attributes decodes the instance attributes into a map
+// Legacy state files that only contain flat attributes are returned as a map of strings
+func (i StateFileInstance) Attributes() (map[string]any, error) {
+	attrs := map[string]any{}
+	if len(i.AttributesRaw) > 0 {
+		if err := json.Unmarshal(i.AttributesRaw, &attrs); err != nil {
+			return nil, err
+		}
+		return attrs, nil
+	}
+	for k, v := range i.AttributesFlat {
+		attrs[k] = v
+	}
+	return attrs, nil
+}
+
+// DecodeAttributes decodes the instance attributes into v (typically a pointer to a struct with json tags)
+func (i StateFileInstance) DecodeAttributes(v any) error {
+	if len(i.AttributesRaw) == 0 {
+		return fmt.Errorf("instance has no attributes")
+	}
+	return json.Unmarshal(i.AttributesRaw, v)
+}
+
+// TypeName returns the output type in terraform syntax (e.g. `list(string)`)
+func (o StateFileOutput) TypeName() (string, error) {
+	var typ any
+	if err := json.Unmarshal(o.Type, &typ); err != nil {
+		return "", fmt.Errorf("invalid output type: %w", err)
+	}
+	return ctyTypeName(typ), nil
+}
+
+// Decode decodes the output value according to its type
+// Numbers are returned as json.Number to preserve precision; collections are returned as []any or map[string]any
+func (o StateFileOutput) Decode() (any, error) {
+	var typ any
+	if len(o.Type) > 0 {
+		if err := json.Unmarshal(o.Type, &typ); err != nil {
+			return nil, fmt.Errorf("invalid output type: %w", err)
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(o.Value))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid output value: %w", err)
+	}
+	if err := checkCtyValue(typ, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// DecodeInto decodes the output value into v
+func (o StateFileOutput) DecodeInto(v any) error {
+	return json.Unmarshal(o.Value, v)
+}
+
+// OutputValue decodes the named output according to its type
+func (s StateFile) OutputValue(name string) (any, error) {
+	output, ok := s.Outputs[name]
+	if !ok {
+		return nil, fmt.Errorf("output %q does not exist", name)
+	}
+	return output.Decode()
+}
+
+// ctyTypeName converts the json encoding of a cty type into terraform syntax
+// e.g. "string" => string, ["list","string"] => list(string), ["object",{"a":"number"}] => object({a=number})
+func ctyTypeName(typ any) string {
+	switch t := typ.(type) {
+	case string:
+		return t
+	case []any:
+		if len(t) != 2 {
+			return fmt.Sprint(t)
+		}
+		kind, _ := t[0].(string)
+		switch kind {
+		case "object":
+			attrs, _ := t[1].(map[string]any)
+			parts := make([]string, 0, len(attrs))
+			for _, k := range slices.Sorted(maps.Keys(attrs)) {
+				parts = append(parts, fmt.Sprintf("%s=%s", k, ctyTypeName(attrs[k])))
+			}
+			return fmt.Sprintf("object({%s})", strings.Join(parts, ","))
+		case "tuple":
+			elems, _ := t[1].([]any)
+			parts := make([]string, 0, len(elems))
+			for _, elem := range elems {
+				parts = append(parts, ctyTypeName(elem))
+			}
+			return fmt.Sprintf("tuple([%s])", strings.Join(parts, ","))
+		default:
+			return fmt.Sprintf("%s(%s)", kind, ctyTypeName(t[1]))
+		}
+	}
+	return "dynamic"
+}
+
+// checkCtyValue verifies that value conforms to the json encoding of a cty type
+func checkCtyValue(typ any, value any) error {
+	if value == nil || typ == nil {
+		return nil
+	}
+	mismatch := func() error {
+		return fmt.Errorf("output value does not match type %s", ctyTypeName(typ))
+	}
+	switch t := typ.(type) {
+	case string:
+		var ok bool
+		switch t {
+		case "string":
+			_, ok = value.(string)
+		case "number":
+			_, ok = value.(json.Number)
+		case "bool":
+			_, ok = value.(bool)
+		default:
+			ok = true
+		}
+		if !ok {
+			return mismatch()
+		}
+		return nil
+	case []any:
+		if len(t) != 2 {
+			return nil
+		}
+		kind, _ := t[0].(string)
+		switch kind {
+		case "list", "set":
+			elems, ok := value.([]any)
+			if !ok {
+				return mismatch()
+			}
+			for _, elem := range elems {
+				if err := checkCtyValue(t[1], elem); err != nil {
+					return err
+				}
+			}
+		case "tuple":
+			elems, ok := value.([]any)
+			elemTypes, _ := t[1].([]any)
+			if !ok || len(elems) != len(elemTypes) {
+				return mismatch()
+			}
+			for i, elem := range elems {
+				if err := checkCtyValue(elemTypes[i], elem); err != nil {
+					return err
+				}
+			}
+		case "map":
+			entries, ok := value.(map[string]any)
+			if !ok {
+				return mismatch()
+			}
+			for _, entry := range entries {
+				if err := checkCtyValue(t[1], entry); err != nil {
+					return err
+				}
+			}
+		case "object":
+			entries, ok := value.(map[string]any)
+			attrTypes, _ := t[1].(map[string]any)
+			if !ok {
+				return mismatch()
+			}
+			for k, attrType := range attrTypes {
+				if err := checkCtyValue(attrType, entries[k]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type addressSegment struct {
+	name string
+	key  any
+}
+
+func (s addressSegment) String() string {
+	switch key := s.key.(type) {
+	case string:
+		return fmt.Sprintf("%s[%q]", s.name, key)
+	case int:
+		return fmt.Sprintf("%s[%d]", s.name, key)
+	}
+	return s.name
+}
+
+// splitAddress splits an address on '.' while respecting index keys (which may contain '.')
+func splitAddress(address string) ([]addressSegment, error) {
+	segments := make([]addressSegment, 0)
+	rest := address
+	for rest != "" {
+		end := strings.IndexAny(rest, ".[")
+		if end == -1 {
+			end = len(rest)
+		}
+		seg := addressSegment{name: rest[:end]}
+		if seg.name == "" {
+			return nil, fmt.Errorf("empty segment")
+		}
+		rest = rest[end:]
+
+		if strings.HasPrefix(rest, "[") {
+			closeIdx := indexKeyEnd(rest)
+			if closeIdx == -1 {
+				return nil, fmt.Errorf("unterminated index")
+			}
+			raw := rest[1:closeIdx]
+			if strings.HasPrefix(raw, `"`) {
+				key, err := strconv.Unquote(raw)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %s", raw)
+				}
+				seg.key = key
+			} else {
+				key, err := strconv.Atoi(raw)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %s", raw)
+				}
+				seg.key = key
+			}
+			rest = rest[closeIdx+1:]
+		}
+		segments = append(segments, seg)
+
+		if rest != "" {
+			if !strings.HasPrefix(rest, ".") {
+				return nil, fmt.Errorf("unexpected %q", rest)
+			}
+			rest = rest[1:]
+			if rest == "" {
+				return nil, fmt.Errorf("trailing '.'")
+			}
+		}
+	}
+	return segments, nil
+}
+
+// indexKeyEnd finds the closing bracket of an index, skipping over quoted strings
+func indexKeyEnd(s string) int {
+	inQuote := false
+	for i := 1; i < len(s); i++ {
+		switch {
+		case inQuote && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			inQuote = !inQuote
+		case !inQuote && s[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// indexKeysEqual compares an index key from a state file (string or float64) against an address key (string or int)
+func indexKeysEqual(stateKey, addrKey any) bool {
+	switch a := addrKey.(type) {
+	case nil:
+		return stateKey == nil
+	case string:
+		s, ok := stateKey.(string)
+		return ok && s == a
+	case int:
+		switch s := stateKey.(type) {
+		case float64:
+			return s == float64(a)
+		case int:
+			return s == a
+		case json.Number:
+			n, err := s.Int64()
+			return err == nil && n == int64(a)
+		}
+	}
+	return false
+}
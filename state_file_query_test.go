@@ -0,0 +1,121 @@
+package infra_sdk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStateAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    StateAddress
+		wantErr bool
+	}{
+		{
+			address: "aws_s3_bucket.logs",
+			want:    StateAddress{Mode: "managed", Type: "aws_s3_bucket", Name: "logs"},
+		},
+		{
+			address: `module.app["api.v1"].module.db.aws_db_instance.this[0]`,
+			want:    StateAddress{Module: `module.app["api.v1"].module.db`, Mode: "managed", Type: "aws_db_instance", Name: "this", IndexKey: 0},
+		},
+		{
+			address: `data.aws_caller_identity.current`,
+			want:    StateAddress{Mode: "data", Type: "aws_caller_identity", Name: "current"},
+		},
+		{address: "aws_s3_bucket", wantErr: true},
+		{address: `aws_s3_bucket.logs["a"`, wantErr: true},
+		{address: "module.app", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			got, err := ParseStateAddress(tt.address)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.address, got.String())
+		})
+	}
+}
+
+func TestStateFile_Query(t *testing.T) {
+	stateFile := StateFile{
+		Outputs: map[string]StateFileOutput{
+			"subnet_ids": {Value: json.RawMessage(`["a","b"]`), Type: json.RawMessage(`["list","string"]`)},
+			"config":     {Value: json.RawMessage(`{"port":8080}`), Type: json.RawMessage(`["object",{"port":"number"}]`)},
+			"invalid":    {Value: json.RawMessage(`"x"`), Type: json.RawMessage(`"number"`)},
+		},
+		Resources: []StateFileResource{
+			{
+				Module: `module.network`,
+				Mode:   "managed",
+				Type:   "aws_subnet",
+				Name:   "private",
+				Instances: []StateFileInstance{
+					{IndexKey: float64(0), AttributesRaw: json.RawMessage(`{"id":"subnet-0","cidr_block":"10.0.0.0/24"}`)},
+					{IndexKey: float64(1), AttributesRaw: json.RawMessage(`{"id":"subnet-1","cidr_block":"10.0.1.0/24"}`)},
+				},
+			},
+			{
+				Mode: "managed",
+				Type: "aws_s3_bucket",
+				Name: "this",
+				Instances: []StateFileInstance{
+					{IndexKey: "logs", AttributesFlat: map[string]string{"id": "logs"}, Dependencies: []string{"module.network.aws_subnet.private"}},
+				},
+			},
+		},
+	}
+
+	resource, instance, err := stateFile.FindInstance("module.network.aws_subnet.private[1]")
+	require.NoError(t, err)
+	require.NotNil(t, instance)
+	assert.Equal(t, "aws_subnet", resource.Type)
+	var subnet struct {
+		Id        string `json:"id"`
+		CidrBlock string `json:"cidr_block"`
+	}
+	require.NoError(t, instance.DecodeAttributes(&subnet))
+	assert.Equal(t, "10.0.1.0/24", subnet.CidrBlock)
+
+	_, instance, err = stateFile.FindInstance(`aws_s3_bucket.this["logs"]`)
+	require.NoError(t, err)
+	require.NotNil(t, instance)
+	attrs, err := instance.Attributes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": "logs"}, attrs)
+
+	deps, err := stateFile.Dependencies(`aws_s3_bucket.this["logs"]`)
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+	assert.Equal(t, "private", deps[0].Name)
+
+	dependents, err := stateFile.Dependents("module.network.aws_subnet.private")
+	require.NoError(t, err)
+	require.Len(t, dependents, 1)
+	assert.Equal(t, "aws_s3_bucket", dependents[0].Type)
+
+	assert.Len(t, stateFile.ResourcesInModule("module.network"), 1)
+	assert.Len(t, stateFile.ResourcesByType("aws_s3_bucket"), 1)
+
+	subnetIds, err := stateFile.OutputValue("subnet_ids")
+	require.NoError(t, err)
+	assert.Equal(t, []any{"a", "b"}, subnetIds)
+
+	config, err := stateFile.OutputValue("config")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"port": json.Number("8080")}, config)
+	typeName, err := stateFile.Outputs["config"].TypeName()
+	require.NoError(t, err)
+	assert.Equal(t, "object({port=number})", typeName)
+
+	_, err = stateFile.OutputValue("invalid")
+	assert.Error(t, err)
+}
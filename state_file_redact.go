@@ -0,0 +1,170 @@
+package infra_sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RedactedValue replaces sensitive values in a redacted state file
+const RedactedValue = "(sensitive value)"
+
+// StateFileAttributePathStep is a single step in a path in an instance's `sensitive_attributes`
+// e.g. {"type":"get_attr","value":"password"} or {"type":"index","value":{"value":0,"type":"number"}}
+type StateFileAttributePathStep struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// SensitivePaths decodes `sensitive_attributes` into a list of attribute paths
+func (i StateFileInstance) SensitivePaths() ([][]StateFileAttributePathStep, error) {
+	if len(i.AttributeSensitivePaths) == 0 {
+		return nil, nil
+	}
+	var paths [][]StateFileAttributePathStep
+	if err := json.Unmarshal(i.AttributeSensitivePaths, &paths); err != nil {
+		return nil, fmt.Errorf("invalid sensitive attributes: %w", err)
+	}
+	return paths, nil
+}
+
+// Redact returns a copy of the state file with sensitive values replaced by RedactedValue
+// This includes outputs marked as sensitive and every instance attribute listed in `sensitive_attributes`
+// The original state file is not modified
+func (s StateFile) Redact() (StateFile, error) {
+	var result StateFile
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return result, fmt.Errorf("error copying state file: %w", err)
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return result, fmt.Errorf("error copying state file: %w", err)
+	}
+
+	// The redacted value is a string, so the output type is rewritten to match for OutputValue/DecodeInto
+	redacted, _ := json.Marshal(RedactedValue)
+	for name, output := range result.Outputs {
+		if output.Sensitive {
+			output.Value = redacted
+			output.Type = json.RawMessage(`"string"`)
+			result.Outputs[name] = output
+		}
+	}
+
+	for i, resource := range result.Resources {
+		for j, instance := range resource.Instances {
+			paths, err := instance.SensitivePaths()
+			if err != nil {
+				return result, fmt.Errorf("error redacting %s: %w", resource.InstanceAddress(instance), err)
+			}
+			if len(paths) == 0 {
+				continue
+			}
+			if err := redactInstance(&result.Resources[i].Instances[j], paths); err != nil {
+				return result, fmt.Errorf("error redacting %s: %w", resource.InstanceAddress(instance), err)
+			}
+		}
+	}
+	return result, nil
+}
+
+func redactInstance(instance *StateFileInstance, paths [][]StateFileAttributePathStep) error {
+	if len(instance.AttributesRaw) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(instance.AttributesRaw))
+		dec.UseNumber()
+		var attrs any
+		if err := dec.Decode(&attrs); err != nil {
+			return fmt.Errorf("invalid attributes: %w", err)
+		}
+		for _, path := range paths {
+			attrs = redactPath(attrs, path)
+		}
+		raw, err := json.Marshal(attrs)
+		if err != nil {
+			return err
+		}
+		instance.AttributesRaw = raw
+	}
+
+	// Flat attributes use keys like "password" or "ingress.0.cidr_blocks.1"
+	for _, path := range paths {
+		key := flatAttributeKey(path)
+		for k := range instance.AttributesFlat {
+			if k == key || strings.HasPrefix(k, key+".") {
+				instance.AttributesFlat[k] = RedactedValue
+			}
+		}
+	}
+	return nil
+}
+
+// redactPath replaces the value at path with RedactedValue
+// If path does not exist in value, value is returned unchanged
+func redactPath(value any, path []StateFileAttributePathStep) any {
+	if len(path) == 0 {
+		return RedactedValue
+	}
+	step := path[0]
+	switch step.Type {
+	case "get_attr":
+		var name string
+		if err := json.Unmarshal(step.Value, &name); err != nil {
+			return value
+		}
+		if obj, ok := value.(map[string]any); ok {
+			if cur, exists := obj[name]; exists && cur != nil {
+				obj[name] = redactPath(cur, path[1:])
+			}
+		}
+	case "index":
+		key := decodeIndexStep(step.Value)
+		switch v := value.(type) {
+		case map[string]any:
+			if k, ok := key.(string); ok {
+				if cur, exists := v[k]; exists && cur != nil {
+					v[k] = redactPath(cur, path[1:])
+				}
+			}
+		case []any:
+			if n, ok := key.(json.Number); ok {
+				if idx, err := n.Int64(); err == nil && idx >= 0 && int(idx) < len(v) {
+					v[idx] = redactPath(v[idx], path[1:])
+				}
+			}
+		}
+	}
+	return value
+}
+
+// decodeIndexStep decodes the value of an index step, which is a cty value: {"value":<value>,"type":<type>}
+// A string key is returned as a string and a number key is returned as a json.Number
+func decodeIndexStep(raw json.RawMessage) any {
+	var typed struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &typed); err == nil && len(typed.Value) > 0 {
+		raw = typed.Value
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var key any
+	_ = dec.Decode(&key)
+	return key
+}
+
+func flatAttributeKey(path []StateFileAttributePathStep) string {
+	parts := make([]string, 0, len(path))
+	for _, step := range path {
+		switch key := decodeIndexStep(step.Value).(type) {
+		case string:
+			parts = append(parts, key)
+		case json.Number:
+			parts = append(parts, key.String())
+		default:
+			parts = append(parts, strconv.Quote(string(step.Value)))
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,88 @@
+package infra_sdk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateFile_Redact(t *testing.T) {
+	stateFile := StateFile{
+		Outputs: map[string]StateFileOutput{
+			"password": {Value: json.RawMessage(`"hunter2"`), Type: json.RawMessage(`"string"`), Sensitive: true},
+			"url":      {Value: json.RawMessage(`"http://example.com"`), Type: json.RawMessage(`"string"`)},
+		},
+		Resources: []StateFileResource{
+			{
+				Mode: "managed",
+				Type: "aws_db_instance",
+				Name: "main",
+				Instances: []StateFileInstance{
+					{
+						AttributesRaw: json.RawMessage(`{"id":"main","password":"hunter2","port":5432,"env":[{"name":"A","value":"secret"},{"name":"B","value":"public"}],"tags":{"token":"abc","Env":"dev"}}`),
+						AttributeSensitivePaths: json.RawMessage(`[
+							[{"type":"get_attr","value":"password"}],
+							[{"type":"get_attr","value":"env"},{"type":"index","value":{"value":0,"type":"number"}},{"type":"get_attr","value":"value"}],
+							[{"type":"get_attr","value":"tags"},{"type":"index","value":{"value":"token","type":"string"}}],
+							[{"type":"get_attr","value":"missing"}]
+						]`),
+					},
+				},
+			},
+			{
+				Mode: "managed",
+				Type: "legacy_resource",
+				Name: "flat",
+				Instances: []StateFileInstance{
+					{
+						AttributesFlat:          map[string]string{"id": "x", "secret.0": "a", "secret.1": "b"},
+						AttributeSensitivePaths: json.RawMessage(`[[{"type":"get_attr","value":"secret"}]]`),
+					},
+				},
+			},
+		},
+	}
+
+	redacted, err := stateFile.Redact()
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `"(sensitive value)"`, string(redacted.Outputs["password"].Value))
+	assert.JSONEq(t, `"http://example.com"`, string(redacted.Outputs["url"].Value))
+	assert.JSONEq(t, `{"id":"main","password":"(sensitive value)","port":5432,"env":[{"name":"A","value":"(sensitive value)"},{"name":"B","value":"public"}],"tags":{"token":"(sensitive value)","Env":"dev"}}`,
+		string(redacted.Resources[0].Instances[0].AttributesRaw))
+	assert.Equal(t, map[string]string{"id": "x", "secret.0": RedactedValue, "secret.1": RedactedValue}, redacted.Resources[1].Instances[0].AttributesFlat)
+
+	// The original is not modified
+	assert.JSONEq(t, `"hunter2"`, string(stateFile.Outputs["password"].Value))
+	assert.Equal(t, "a", stateFile.Resources[1].Instances[0].AttributesFlat["secret.0"])
+}
+
+func TestStateFile_Redact_Outputs(t *testing.T) {
+	stateFile := StateFile{
+		Outputs: map[string]StateFileOutput{
+			"port":   {Value: json.RawMessage(`5432`), Type: json.RawMessage(`"number"`), Sensitive: true},
+			"config": {Value: json.RawMessage(`{"user":"admin"}`), Type: json.RawMessage(`["object",{"user":"string"}]`), Sensitive: true},
+			"count":  {Value: json.RawMessage(`3`), Type: json.RawMessage(`"number"`)},
+		},
+	}
+
+	redacted, err := stateFile.Redact()
+	require.NoError(t, err)
+
+	for _, name := range []string{"port", "config"} {
+		value, err := redacted.OutputValue(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, RedactedValue, value, name)
+
+		var decoded string
+		require.NoError(t, redacted.Outputs[name].DecodeInto(&decoded), name)
+		assert.Equal(t, RedactedValue, decoded, name)
+	}
+
+	value, err := redacted.OutputValue("count")
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("3"), value)
+	assert.JSONEq(t, `"number"`, string(stateFile.Outputs["port"].Type))
+}
@@ -0,0 +1,363 @@
+package infra_sdk
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MoveResource moves a resource, resource instance, or module to a new address, similar to `terraform state mv`
+//   - `aws_s3_bucket.a` => `module.storage.aws_s3_bucket.b` moves every instance of a resource
+//   - `aws_s3_bucket.a[0]` => `aws_s3_bucket.a["logs"]` moves a single instance
+//   - `module.a` => `module.b` moves every resource in a module (including nested modules)
+//
+// Dependencies that reference a moved resource are updated and Serial is incremented
+func (s *StateFile) MoveResource(from, to string) error {
+	fromModule, fromIsModule := parseModuleAddress(from)
+	toModule, toIsModule := parseModuleAddress(to)
+	if fromIsModule || toIsModule {
+		if !fromIsModule || !toIsModule {
+			return fmt.Errorf("cannot move %s to %s: both addresses must be modules", from, to)
+		}
+		return s.moveModule(fromModule, toModule)
+	}
+
+	fromAddr, err := ParseStateAddress(from)
+	if err != nil {
+		return err
+	}
+	toAddr, err := ParseStateAddress(to)
+	if err != nil {
+		return err
+	}
+	if fromAddr.Mode != toAddr.Mode || fromAddr.Type != toAddr.Type {
+		return fmt.Errorf("cannot move %s to %s: resource types do not match", from, to)
+	}
+
+	srcIdx := s.resourceIndex(fromAddr)
+	if srcIdx == -1 {
+		return fmt.Errorf("resource %s does not exist", fromAddr.ResourceAddress())
+	}
+
+	// Without index keys on either side, a resource using count/for_each moves as a whole
+	if fromAddr.IndexKey == nil && toAddr.IndexKey == nil && !hasInstanceKey(s.Resources[srcIdx], nil) {
+		return s.moveWholeResource(srcIdx, fromAddr, toAddr)
+	}
+	return s.moveInstance(srcIdx, fromAddr, toAddr)
+}
+
+// RemoveResource removes a resource, resource instance, or module from the state, similar to `terraform state rm`
+// The real infrastructure is not affected; dependencies on removed resources are dropped and Serial is incremented
+func (s *StateFile) RemoveResource(address string) error {
+	removed := make([]string, 0)
+	if module, ok := parseModuleAddress(address); ok {
+		remaining := make([]StateFileResource, 0, len(s.Resources))
+		for _, resource := range s.Resources {
+			if isInModule(resource.Module, module) {
+				removed = append(removed, resource.FullyQualifiedName())
+			} else {
+				remaining = append(remaining, resource)
+			}
+		}
+		if len(removed) == 0 {
+			return fmt.Errorf("module %s does not exist", address)
+		}
+		s.Resources = remaining
+	} else {
+		addr, err := ParseStateAddress(address)
+		if err != nil {
+			return err
+		}
+		idx := s.resourceIndex(addr)
+		if idx == -1 {
+			return fmt.Errorf("resource %s does not exist", addr.ResourceAddress())
+		}
+		resource := &s.Resources[idx]
+		if addr.IndexKey == nil && !hasInstanceKey(*resource, nil) {
+			// Removing a resource without an index removes every instance
+			resource.Instances = nil
+		} else {
+			instances := slices.DeleteFunc(slices.Clone(resource.Instances), func(instance StateFileInstance) bool {
+				return indexKeysEqual(instance.IndexKey, addr.IndexKey)
+			})
+			if len(instances) == len(resource.Instances) {
+				return fmt.Errorf("resource instance %s does not exist", address)
+			}
+			resource.Instances = instances
+		}
+		if len(resource.Instances) == 0 {
+			removed = append(removed, resource.FullyQualifiedName())
+			s.Resources = slices.Delete(s.Resources, idx, idx+1)
+		}
+	}
+
+	for i := range s.Resources {
+		for j := range s.Resources[i].Instances {
+			instance := &s.Resources[i].Instances[j]
+			instance.Dependencies = slices.DeleteFunc(instance.Dependencies, func(dep string) bool {
+				return slices.Contains(removed, dep)
+			})
+		}
+	}
+	s.Serial++
+	return nil
+}
+
+// ImportInstance adds an instance of an existing object to the state at address, similar to `terraform import`
+// provider is the provider address (e.g. `provider["registry.terraform.io/hashicorp/aws"]`) used if the resource does not exist
+// Serial is incremented
+func (s *StateFile) ImportInstance(address, provider string, instance StateFileInstance) error {
+	addr, err := ParseStateAddress(address)
+	if err != nil {
+		return err
+	}
+	if addr.Mode != "managed" {
+		return fmt.Errorf("cannot import %s: only managed resources can be imported", address)
+	}
+	instance.IndexKey = stateIndexKey(addr.IndexKey)
+	instance.Deposed = ""
+
+	idx := s.resourceIndex(addr)
+	if idx == -1 {
+		s.Resources = append(s.Resources, StateFileResource{
+			Module:   addr.Module,
+			Mode:     addr.Mode,
+			Type:     addr.Type,
+			Name:     addr.Name,
+			Provider: provider,
+		})
+		idx = len(s.Resources) - 1
+	}
+	resource := &s.Resources[idx]
+	if hasInstanceKey(*resource, addr.IndexKey) {
+		return fmt.Errorf("resource instance %s already exists", address)
+	}
+	if len(resource.Instances) > 0 {
+		existing, _ := indexKeyKind(resource.Instances[0].IndexKey)
+		kind, _ := indexKeyKind(instance.IndexKey)
+		if existing != kind {
+			return fmt.Errorf("cannot import %s: index key does not match the existing instances of %s", address, resource.FullyQualifiedName())
+		}
+	}
+	resource.Instances = append(resource.Instances, instance)
+	s.Serial++
+	return nil
+}
+
+func (s *StateFile) moveWholeResource(srcIdx int, fromAddr, toAddr StateAddress) error {
+	if s.resourceIndex(toAddr) != -1 {
+		return fmt.Errorf("cannot move %s: %s already exists", fromAddr.ResourceAddress(), toAddr.ResourceAddress())
+	}
+	oldAddress := s.Resources[srcIdx].FullyQualifiedName()
+	s.Resources[srcIdx].Module = toAddr.Module
+	s.Resources[srcIdx].Name = toAddr.Name
+	s.renameDependencies(func(dep string) string {
+		if dep == oldAddress {
+			return s.Resources[srcIdx].FullyQualifiedName()
+		}
+		return dep
+	})
+	s.Serial++
+	return nil
+}
+
+func (s *StateFile) moveInstance(srcIdx int, fromAddr, toAddr StateAddress) error {
+	src := &s.Resources[srcIdx]
+	instIdx := slices.IndexFunc(src.Instances, func(instance StateFileInstance) bool {
+		return instance.Deposed == "" && indexKeysEqual(instance.IndexKey, fromAddr.IndexKey)
+	})
+	if instIdx == -1 {
+		return fmt.Errorf("resource instance %s does not exist", fromAddr)
+	}
+
+	dstIdx := s.resourceIndex(toAddr)
+	if dstIdx != -1 && hasInstanceKey(s.Resources[dstIdx], toAddr.IndexKey) {
+		return fmt.Errorf("cannot move %s: %s already exists", fromAddr, toAddr)
+	}
+	if dstIdx == srcIdx {
+		return s.rekeyInstance(srcIdx, fromAddr, toAddr)
+	}
+
+	// Move the instance and its deposed objects
+	var moved []StateFileInstance
+	remaining := make([]StateFileInstance, 0, len(src.Instances))
+	for _, instance := range src.Instances {
+		if indexKeysEqual(instance.IndexKey, fromAddr.IndexKey) {
+			instance.IndexKey = stateIndexKey(toAddr.IndexKey)
+			moved = append(moved, instance)
+		} else {
+			remaining = append(remaining, instance)
+		}
+	}
+
+	oldAddress := src.FullyQualifiedName()
+	if dstIdx == -1 {
+		if len(remaining) == 0 {
+			// The whole resource is moving, so we can rename it in place
+			src.Module, src.Name, src.Instances = toAddr.Module, toAddr.Name, moved
+			src.Each = eachForIndexKey(toAddr.IndexKey)
+			s.renameDependencies(func(dep string) string {
+				if dep == oldAddress {
+					return toAddr.ResourceAddress()
+				}
+				return dep
+			})
+			s.Serial++
+			return nil
+		}
+		s.Resources = append(s.Resources, StateFileResource{
+			Module:   toAddr.Module,
+			Mode:     toAddr.Mode,
+			Type:     toAddr.Type,
+			Name:     toAddr.Name,
+			Each:     eachForIndexKey(toAddr.IndexKey),
+			Provider: src.Provider,
+		})
+		dstIdx = len(s.Resources) - 1
+		src = &s.Resources[srcIdx]
+	}
+	if len(s.Resources[dstIdx].Instances) > 0 {
+		existing, _ := indexKeyKind(s.Resources[dstIdx].Instances[0].IndexKey)
+		kind, _ := indexKeyKind(stateIndexKey(toAddr.IndexKey))
+		if existing != kind {
+			return fmt.Errorf("cannot move %s: index key does not match the existing instances of %s", fromAddr, toAddr.ResourceAddress())
+		}
+	}
+	s.Resources[dstIdx].Instances = append(s.Resources[dstIdx].Instances, moved...)
+	src.Instances = remaining
+
+	if len(remaining) == 0 {
+		s.Resources = slices.Delete(s.Resources, srcIdx, srcIdx+1)
+		s.renameDependencies(func(dep string) string {
+			if dep == oldAddress {
+				return toAddr.ResourceAddress()
+			}
+			return dep
+		})
+	}
+	s.Serial++
+	return nil
+}
+
+// rekeyInstance changes the index key of an instance (and its deposed objects) within the same resource
+// The key kind may only change if every instance of the resource is moved (e.g. when adding count or for_each)
+func (s *StateFile) rekeyInstance(idx int, fromAddr, toAddr StateAddress) error {
+	resource := &s.Resources[idx]
+	kind, _ := indexKeyKind(stateIndexKey(toAddr.IndexKey))
+	for _, instance := range resource.Instances {
+		if indexKeysEqual(instance.IndexKey, fromAddr.IndexKey) {
+			continue
+		}
+		if existing, _ := indexKeyKind(instance.IndexKey); existing != kind {
+			return fmt.Errorf("cannot move %s: index key does not match the existing instances of %s", fromAddr, toAddr.ResourceAddress())
+		}
+	}
+
+	all := true
+	for i := range resource.Instances {
+		if indexKeysEqual(resource.Instances[i].IndexKey, fromAddr.IndexKey) {
+			resource.Instances[i].IndexKey = stateIndexKey(toAddr.IndexKey)
+		} else {
+			all = false
+		}
+	}
+	if all {
+		resource.Each = eachForIndexKey(toAddr.IndexKey)
+	}
+	s.Serial++
+	return nil
+}
+
+func (s *StateFile) moveModule(from, to string) error {
+	for _, resource := range s.Resources {
+		if isInModule(resource.Module, to) {
+			return fmt.Errorf("cannot move %s: %s already exists", from, to)
+		}
+	}
+	rename := func(address string) string {
+		if address == from {
+			return to
+		}
+		if strings.HasPrefix(address, from+".") {
+			return to + address[len(from):]
+		}
+		return address
+	}
+
+	found := false
+	for i := range s.Resources {
+		if isInModule(s.Resources[i].Module, from) {
+			s.Resources[i].Module = rename(s.Resources[i].Module)
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("module %s does not exist", from)
+	}
+	s.renameDependencies(rename)
+	s.Serial++
+	return nil
+}
+
+func (s *StateFile) renameDependencies(rename func(dep string) string) {
+	for i := range s.Resources {
+		for j := range s.Resources[i].Instances {
+			deps := s.Resources[i].Instances[j].Dependencies
+			for k, dep := range deps {
+				deps[k] = rename(dep)
+			}
+		}
+	}
+}
+
+func (s *StateFile) resourceIndex(addr StateAddress) int {
+	return slices.IndexFunc(s.Resources, func(resource StateFileResource) bool {
+		return resource.Module == addr.Module && resource.Mode == addr.Mode && resource.Type == addr.Type && resource.Name == addr.Name
+	})
+}
+
+func hasInstanceKey(resource StateFileResource, key any) bool {
+	return slices.ContainsFunc(resource.Instances, func(instance StateFileInstance) bool {
+		return instance.Deposed == "" && indexKeysEqual(instance.IndexKey, key)
+	})
+}
+
+// parseModuleAddress detects a module address (e.g. `module.a["x"].module.b`) and returns it in canonical form
+func parseModuleAddress(address string) (string, bool) {
+	segments, err := splitAddress(address)
+	if err != nil || len(segments) == 0 || len(segments)%2 != 0 {
+		return "", false
+	}
+	parts := make([]string, 0, len(segments)/2)
+	for i := 0; i < len(segments); i += 2 {
+		if segments[i].name != "module" || segments[i].key != nil {
+			return "", false
+		}
+		parts = append(parts, "module."+segments[i+1].String())
+	}
+	return strings.Join(parts, "."), true
+}
+
+// isInModule returns true if resourceModule is module or one of its descendants
+func isInModule(resourceModule, module string) bool {
+	return resourceModule == module || strings.HasPrefix(resourceModule, module+".")
+}
+
+// stateIndexKey converts an address index key into the representation used in a decoded state file
+func stateIndexKey(key any) any {
+	if n, ok := key.(int); ok {
+		return float64(n)
+	}
+	return key
+}
+
+// eachForIndexKey returns the "each" mode of a resource whose instances use key
+func eachForIndexKey(key any) string {
+	switch kind, _ := indexKeyKind(stateIndexKey(key)); kind {
+	case "int":
+		return "list"
+	case "string":
+		return "map"
+	}
+	return ""
+}
@@ -0,0 +1,234 @@
+package infra_sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const surgeryProvider = `provider["registry.terraform.io/hashicorp/aws"]`
+
+func newSurgeryStateFile() *StateFile {
+	return &StateFile{
+		Version: StateFileVersion,
+		Serial:  3,
+		Lineage: "lineage",
+		Resources: []StateFileResource{
+			{
+				Mode: "managed", Type: "aws_s3_bucket", Name: "logs", Provider: surgeryProvider,
+				Instances: []StateFileInstance{{AttributesRaw: []byte(`{"id":"logs"}`)}},
+			},
+			{
+				Mode: "managed", Type: "aws_sqs_queue", Name: "jobs", Each: "list", Provider: surgeryProvider,
+				Instances: []StateFileInstance{
+					{IndexKey: float64(0), AttributesRaw: []byte(`{"id":"jobs-0"}`)},
+					{IndexKey: float64(1), AttributesRaw: []byte(`{"id":"jobs-1"}`)},
+				},
+			},
+			{
+				Module: "module.app", Mode: "managed", Type: "aws_iam_role", Name: "this", Provider: surgeryProvider,
+				Instances: []StateFileInstance{{
+					AttributesRaw: []byte(`{"id":"role"}`),
+					Dependencies:  []string{"aws_s3_bucket.logs", "aws_sqs_queue.jobs", "module.app.module.db.aws_db_instance.this"},
+				}},
+			},
+			{
+				Module: "module.app.module.db", Mode: "managed", Type: "aws_db_instance", Name: "this", Provider: surgeryProvider,
+				Instances: []StateFileInstance{{AttributesRaw: []byte(`{"id":"db"}`)}},
+			},
+		},
+	}
+}
+
+func TestStateFile_MoveResource(t *testing.T) {
+	t.Run("resource into module", func(t *testing.T) {
+		s := newSurgeryStateFile()
+		require.NoError(t, s.MoveResource("aws_s3_bucket.logs", "module.storage.aws_s3_bucket.logs"))
+		assert.Nil(t, findResource(t, s, "aws_s3_bucket.logs"))
+		assert.NotNil(t, findResource(t, s, "module.storage.aws_s3_bucket.logs"))
+		assert.Equal(t, uint64(4), s.Serial)
+		role := findInstance(t, s, "module.app.aws_iam_role.this")
+		require.NotNil(t, role)
+		assert.Contains(t, role.Dependencies, "module.storage.aws_s3_bucket.logs")
+		assert.NoError(t, s.Validate())
+	})
+
+	t.Run("counted resource as a whole", func(t *testing.T) {
+		s := newSurgeryStateFile()
+		require.NoError(t, s.MoveResource("aws_sqs_queue.jobs", "aws_sqs_queue.tasks"))
+		tasks := findResource(t, s, "aws_sqs_queue.tasks")
+		require.NotNil(t, tasks)
+		assert.Len(t, tasks.Instances, 2)
+		assert.Contains(t, findInstance(t, s, "module.app.aws_iam_role.this").Dependencies, "aws_sqs_queue.tasks")
+	})
+
+	t.Run("single instance", func(t *testing.T) {
+		s := newSurgeryStateFile()
+		require.NoError(t, s.MoveResource("aws_sqs_queue.jobs[1]", `aws_sqs_queue.priority["high"]`))
+		assert.Len(t, findResource(t, s, "aws_sqs_queue.jobs").Instances, 1)
+		moved := findInstance(t, s, `aws_sqs_queue.priority["high"]`)
+		require.NotNil(t, moved)
+		assert.JSONEq(t, `{"id":"jobs-1"}`, string(moved.AttributesRaw))
+		assert.Equal(t, surgeryProvider, findResource(t, s, "aws_sqs_queue.priority").Provider)
+		assert.Equal(t, "map", findResource(t, s, "aws_sqs_queue.priority").Each)
+		// The source resource still exists, so dependencies are unchanged
+		assert.Contains(t, findInstance(t, s, "module.app.aws_iam_role.this").Dependencies, "aws_sqs_queue.jobs")
+		assert.NoError(t, s.Validate())
+	})
+
+	t.Run("last instance out of module", func(t *testing.T) {
+		s := newSurgeryStateFile()
+		require.NoError(t, s.MoveResource("module.app.module.db.aws_db_instance.this", "aws_db_instance.main[0]"))
+		assert.Nil(t, findResource(t, s, "module.app.module.db.aws_db_instance.this"))
+		assert.NotNil(t, findInstance(t, s, "aws_db_instance.main[0]"))
+		assert.Contains(t, findInstance(t, s, "module.app.aws_iam_role.this").Dependencies, "aws_db_instance.main")
+		assert.NoError(t, s.Validate())
+	})
+
+	t.Run("module", func(t *testing.T) {
+		s := newSurgeryStateFile()
+		require.NoError(t, s.MoveResource("module.app", `module.service["api"]`))
+		assert.NotNil(t, findResource(t, s, `module.service["api"].aws_iam_role.this`))
+		assert.NotNil(t, findResource(t, s, `module.service["api"].module.db.aws_db_instance.this`))
+		role := findInstance(t, s, `module.service["api"].aws_iam_role.this`)
+		assert.Contains(t, role.Dependencies, `module.service["api"].module.db.aws_db_instance.this`)
+		assert.Equal(t, uint64(4), s.Serial)
+		assert.NoError(t, s.Validate())
+	})
+
+	t.Run("renumber instance within resource", func(t *testing.T) {
+		s := newSurgeryStateFile()
+		require.NoError(t, s.MoveResource("aws_sqs_queue.jobs[1]", "aws_sqs_queue.jobs[5]"))
+		jobs := findResource(t, s, "aws_sqs_queue.jobs")
+		require.NotNil(t, jobs)
+		assert.Len(t, jobs.Instances, 2)
+		assert.JSONEq(t, `{"id":"jobs-0"}`, string(findInstance(t, s, "aws_sqs_queue.jobs[0]").AttributesRaw))
+		assert.JSONEq(t, `{"id":"jobs-1"}`, string(findInstance(t, s, "aws_sqs_queue.jobs[5]").AttributesRaw))
+		assert.Nil(t, findInstance(t, s, "aws_sqs_queue.jobs[1]"))
+		assert.Equal(t, uint64(4), s.Serial)
+		assert.NoError(t, s.Validate())
+	})
+
+	t.Run("renumber last instance within resource", func(t *testing.T) {
+		s := newSurgeryStateFile()
+		require.NoError(t, s.RemoveResource("aws_sqs_queue.jobs[1]"))
+		require.NoError(t, s.MoveResource("aws_sqs_queue.jobs[0]", "aws_sqs_queue.jobs[1]"))
+		jobs := findResource(t, s, "aws_sqs_queue.jobs")
+		require.NotNil(t, jobs)
+		require.Len(t, jobs.Instances, 1)
+		assert.JSONEq(t, `{"id":"jobs-0"}`, string(findInstance(t, s, "aws_sqs_queue.jobs[1]").AttributesRaw))
+		assert.NoError(t, s.Validate())
+	})
+
+	t.Run("add for_each to resource", func(t *testing.T) {
+		s := newSurgeryStateFile()
+		require.NoError(t, s.MoveResource("aws_s3_bucket.logs", `aws_s3_bucket.logs["x"]`))
+		logs := findResource(t, s, "aws_s3_bucket.logs")
+		require.NotNil(t, logs)
+		require.Len(t, logs.Instances, 1)
+		assert.Equal(t, "map", logs.Each)
+		assert.JSONEq(t, `{"id":"logs"}`, string(findInstance(t, s, `aws_s3_bucket.logs["x"]`).AttributesRaw))
+		// The resource address is unchanged, so dependencies are unchanged
+		assert.Contains(t, findInstance(t, s, "module.app.aws_iam_role.this").Dependencies, "aws_s3_bucket.logs")
+		assert.NoError(t, s.Validate())
+	})
+
+	t.Run("change count to for_each", func(t *testing.T) {
+		s := newSurgeryStateFile()
+		require.NoError(t, s.RemoveResource("aws_sqs_queue.jobs[1]"))
+		require.NoError(t, s.MoveResource("aws_sqs_queue.jobs[0]", `aws_sqs_queue.jobs["default"]`))
+		jobs := findResource(t, s, "aws_sqs_queue.jobs")
+		require.NotNil(t, jobs)
+		assert.Equal(t, "map", jobs.Each)
+		assert.NotNil(t, findInstance(t, s, `aws_sqs_queue.jobs["default"]`))
+	})
+
+	errTests := []struct {
+		name     string
+		from, to string
+		errMsg   string
+	}{
+		{name: "missing", from: "aws_s3_bucket.missing", to: "aws_s3_bucket.other", errMsg: "does not exist"},
+		{name: "type mismatch", from: "aws_s3_bucket.logs", to: "aws_sqs_queue.logs", errMsg: "resource types do not match"},
+		{name: "destination exists", from: "aws_sqs_queue.jobs[0]", to: "aws_sqs_queue.jobs[1]", errMsg: "already exists"},
+		{name: "index kind mismatch", from: "aws_sqs_queue.jobs[0]", to: "aws_sqs_queue.jobs[\"a\"]", errMsg: "index key does not match"},
+		{name: "module to resource", from: "module.app", to: "aws_s3_bucket.app", errMsg: "both addresses must be modules"},
+		{name: "module exists", from: "module.app.module.db", to: "module.app", errMsg: "already exists"},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSurgeryStateFile()
+			err := s.MoveResource(tt.from, tt.to)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestStateFile_RemoveResource(t *testing.T) {
+	t.Run("instance", func(t *testing.T) {
+		s := newSurgeryStateFile()
+		require.NoError(t, s.RemoveResource("aws_sqs_queue.jobs[0]"))
+		assert.Len(t, findResource(t, s, "aws_sqs_queue.jobs").Instances, 1)
+		assert.Contains(t, findInstance(t, s, "module.app.aws_iam_role.this").Dependencies, "aws_sqs_queue.jobs")
+		assert.Equal(t, uint64(4), s.Serial)
+	})
+
+	t.Run("resource", func(t *testing.T) {
+		s := newSurgeryStateFile()
+		require.NoError(t, s.RemoveResource("aws_sqs_queue.jobs"))
+		assert.Nil(t, findResource(t, s, "aws_sqs_queue.jobs"))
+		assert.NotContains(t, findInstance(t, s, "module.app.aws_iam_role.this").Dependencies, "aws_sqs_queue.jobs")
+		assert.NoError(t, s.Validate())
+	})
+
+	t.Run("module", func(t *testing.T) {
+		s := newSurgeryStateFile()
+		require.NoError(t, s.RemoveResource("module.app.module.db"))
+		assert.Nil(t, findResource(t, s, "module.app.module.db.aws_db_instance.this"))
+		assert.Equal(t, []string{"aws_s3_bucket.logs", "aws_sqs_queue.jobs"}, findInstance(t, s, "module.app.aws_iam_role.this").Dependencies)
+		assert.NoError(t, s.Validate())
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		s := newSurgeryStateFile()
+		assert.Error(t, s.RemoveResource("aws_sqs_queue.jobs[5]"))
+		assert.Error(t, s.RemoveResource("module.missing"))
+		assert.Equal(t, uint64(3), s.Serial)
+	})
+}
+
+func TestStateFile_ImportInstance(t *testing.T) {
+	s := newSurgeryStateFile()
+	require.NoError(t, s.ImportInstance("module.storage.aws_s3_bucket.assets", surgeryProvider, StateFileInstance{
+		SchemaVersion: 0,
+		AttributesRaw: []byte(`{"id":"assets"}`),
+	}))
+	require.NoError(t, s.ImportInstance("aws_sqs_queue.jobs[2]", surgeryProvider, StateFileInstance{
+		AttributesRaw: []byte(`{"id":"jobs-2"}`),
+	}))
+	assert.Equal(t, uint64(5), s.Serial)
+
+	assets := findResource(t, s, "module.storage.aws_s3_bucket.assets")
+	require.NotNil(t, assets)
+	assert.Equal(t, surgeryProvider, assets.Provider)
+	assert.Len(t, findResource(t, s, "aws_sqs_queue.jobs").Instances, 3)
+	assert.NoError(t, s.Validate())
+
+	assert.ErrorContains(t, s.ImportInstance("aws_s3_bucket.logs", surgeryProvider, StateFileInstance{}), "already exists")
+	assert.ErrorContains(t, s.ImportInstance("aws_sqs_queue.jobs[\"x\"]", surgeryProvider, StateFileInstance{}), "index key does not match")
+	assert.ErrorContains(t, s.ImportInstance("data.aws_caller_identity.this", surgeryProvider, StateFileInstance{}), "only managed resources")
+}
+
+func findResource(t *testing.T, s *StateFile, address string) *StateFileResource {
+	resource, err := s.FindResource(address)
+	require.NoError(t, err)
+	return resource
+}
+
+func findInstance(t *testing.T, s *StateFile, address string) *StateFileInstance {
+	_, instance, err := s.FindInstance(address)
+	require.NoError(t, err)
+	return instance
+}
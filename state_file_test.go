@@ -0,0 +1,37 @@
+package infra_sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateFileResource_FullyQualifiedName(t *testing.T) {
+	tests := []struct {
+		resource StateFileResource
+		want     string
+	}{
+		{
+			resource: StateFileResource{Mode: "managed", Type: "aws_s3_bucket", Name: "logs"},
+			want:     "aws_s3_bucket.logs",
+		},
+		{
+			resource: StateFileResource{Mode: "data", Type: "aws_caller_identity", Name: "current"},
+			want:     "data.aws_caller_identity.current",
+		},
+		{
+			resource: StateFileResource{Module: "module.network", Mode: "data", Type: "aws_vpc", Name: "this"},
+			want:     "module.network.data.aws_vpc.this",
+		},
+		{
+			resource: StateFileResource{Module: "module.network", Mode: "managed", Type: "aws_subnet", Name: "private"},
+			want:     "module.network.aws_subnet.private",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			assert.Equal(t, test.want, test.resource.FullyQualifiedName())
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package infra_sdk
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	invalidIdentifierChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+)
+
+// TerraformImport describes how to adopt a scanned resource into terraform
+// It renders as an `import {}` block and a skeleton `resource` block
+type TerraformImport struct {
+	// ResourceType is the provider resource type (e.g. `aws_s3_bucket`)
+	ResourceType string `json:"resourceType"`
+	// ResourceName is the local name of the resource in terraform
+	ResourceName string `json:"resourceName"`
+	// Id is the import id in the format that the provider expects for ResourceType
+	Id string `json:"id"`
+	// Arguments are emitted in the skeleton resource block
+	// These are typically the arguments that identify the resource (e.g. `bucket` for `aws_s3_bucket`)
+	Arguments []TerraformImportArgument `json:"arguments"`
+	// Provider is the provider configuration that manages the resource (e.g. `aws.us-west-2`)
+	// The default provider configuration is used if this is empty
+	Provider string `json:"provider,omitempty"`
+	// Comment is emitted above the import block
+	Comment string `json:"comment,omitempty"`
+}
+
+type TerraformImportArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (i TerraformImport) Address() string {
+	return fmt.Sprintf("%s.%s", i.ResourceType, i.ResourceName)
+}
+
+// Render emits the import block followed by a skeleton resource block
+func (i TerraformImport) Render() string {
+	sb := strings.Builder{}
+	if i.Comment != "" {
+		for _, line := range strings.Split(i.Comment, "\n") {
+			sb.WriteString(fmt.Sprintf("# %s\n", line))
+		}
+	}
+	// Attributes are aligned the same way as `terraform fmt`
+	importWidth := len("id")
+	if i.Provider != "" {
+		importWidth = len("provider")
+	}
+	sb.WriteString("import {\n")
+	sb.WriteString(fmt.Sprintf("  %-*s = %s\n", importWidth, "to", i.Address()))
+	sb.WriteString(fmt.Sprintf("  %-*s = %s\n", importWidth, "id", hclString(i.Id)))
+	if i.Provider != "" {
+		sb.WriteString(fmt.Sprintf("  provider = %s\n", i.Provider))
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString(fmt.Sprintf("resource %q %q {\n", i.ResourceType, i.ResourceName))
+	if i.Provider != "" {
+		sb.WriteString(fmt.Sprintf("  provider = %s\n", i.Provider))
+		if len(i.Arguments) > 0 {
+			sb.WriteString("\n")
+		}
+	}
+	width := 0
+	for _, arg := range i.Arguments {
+		width = max(width, len(arg.Name))
+	}
+	for _, arg := range i.Arguments {
+		sb.WriteString(fmt.Sprintf("  %-*s = %s\n", width, arg.Name, hclString(arg.Value)))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// RenderTerraformImports emits every import as a single terraform file
+func RenderTerraformImports(imports []TerraformImport) string {
+	blocks := make([]string, 0, len(imports))
+	for _, cur := range imports {
+		blocks = append(blocks, cur.Render())
+	}
+	return strings.Join(blocks, "\n")
+}
+
+// TerraformIdentifier converts an arbitrary name into a valid terraform identifier
+// Identifiers may only contain letters, digits, underscores, and dashes and must not start with a digit
+func TerraformIdentifier(name string) string {
+	result := invalidIdentifierChars.ReplaceAllString(strings.ToLower(name), "_")
+	result = strings.Trim(result, "_")
+	if result == "" {
+		return "this"
+	}
+	if result[0] >= '0' && result[0] <= '9' || result[0] == '-' {
+		result = "r_" + result
+	}
+	return result
+}
+
+// hclString quotes a value as an HCL string literal
+// HCL interprets `${` and `%{` as template sequences, so they must be escaped
+func hclString(value string) string {
+	quoted := fmt.Sprintf("%q", value)
+	quoted = strings.ReplaceAll(quoted, "${", "$${")
+	return strings.ReplaceAll(quoted, "%{", "%%{")
+}
@@ -0,0 +1,72 @@
+package infra_sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerraformImport_Render(t *testing.T) {
+	imp := TerraformImport{
+		ResourceType: "aws_db_instance",
+		ResourceName: "main",
+		Id:           "main-db",
+		Arguments: []TerraformImportArgument{
+			{Name: "identifier", Value: "main-db"},
+			{Name: "db_name", Value: "${app}"},
+		},
+		Comment: "RDS Instance",
+	}
+
+	want := `# RDS Instance
+import {
+  to = aws_db_instance.main
+  id = "main-db"
+}
+
+resource "aws_db_instance" "main" {
+  identifier = "main-db"
+  db_name    = "$${app}"
+}
+`
+	assert.Equal(t, want, imp.Render())
+}
+
+func TestTerraformImport_Render_Provider(t *testing.T) {
+	imp := TerraformImport{
+		ResourceType: "aws_s3_bucket",
+		ResourceName: "logs",
+		Id:           "logs",
+		Arguments:    []TerraformImportArgument{{Name: "bucket", Value: "logs"}},
+		Provider:     "aws.us-west-2",
+	}
+
+	want := `import {
+  to       = aws_s3_bucket.logs
+  id       = "logs"
+  provider = aws.us-west-2
+}
+
+resource "aws_s3_bucket" "logs" {
+  provider = aws.us-west-2
+
+  bucket = "logs"
+}
+`
+	assert.Equal(t, want, imp.Render())
+}
+
+func TestTerraformIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"my-bucket":         "my-bucket",
+		"My Bucket.example": "my_bucket_example",
+		"123-logs":          "r_123-logs",
+		"":                  "this",
+		"___":               "this",
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			assert.Equal(t, want, TerraformIdentifier(input))
+		})
+	}
+}
@@ -6,4 +6,28 @@ const (
 	UniversalTagBlock = "nullstone.io/block"
 
 	UniversalDimensionAccount = "nullstone.io/cloud-account"
+	// UniversalDimensionService is the cloud service that incurred the cost (e.g. "Amazon Elastic Compute Cloud - Compute")
+	UniversalDimensionService = "nullstone.io/service"
+	// UniversalDimensionRegion is the cloud region where the cost was incurred (e.g. "us-east-1")
+	UniversalDimensionRegion = "nullstone.io/region"
+	// UniversalDimensionUsageType is the provider-specific unit of usage (e.g. "USE1-BoxUsage:t3.micro")
+	UniversalDimensionUsageType = "nullstone.io/usage-type"
+	// UniversalDimensionOperation is the provider-specific operation that was performed (e.g. "RunInstances")
+	UniversalDimensionOperation = "nullstone.io/operation"
+	// UniversalDimensionChargeType is the kind of charge (e.g. usage, credit, refund, tax)
+	UniversalDimensionChargeType = "nullstone.io/charge-type"
+	// UniversalDimensionPurchaseOption is how the usage was purchased (e.g. on-demand, spot, reservation, savings plan)
+	UniversalDimensionPurchaseOption = "nullstone.io/purchase-option"
+	// UniversalDimensionResourceId groups costs by the cloud resource that incurred them
+	// Values match ScanResource.UniqueId or the provider's short identifier for the resource
+	UniversalDimensionResourceId = "nullstone.io/resource-id"
+
+	// Universal metrics are reported as CostSeries.MetricName
+	// The names match the AWS Cost Explorer metrics, other providers map them to their closest equivalent
+	UniversalMetricUnblendedCost    = "UnblendedCost"
+	UniversalMetricBlendedCost      = "BlendedCost"
+	UniversalMetricAmortizedCost    = "AmortizedCost"
+	UniversalMetricNetAmortizedCost = "NetAmortizedCost"
+	UniversalMetricNetUnblendedCost = "NetUnblendedCost"
+	UniversalMetricUsageQuantity    = "UsageQuantity"
 )